@@ -0,0 +1,673 @@
+// Package bru reads, edits and writes Bruno .bru files.
+//
+// Parse builds a lossless syntax tree: every block keeps its position, order,
+// the blank lines and comments before it, and every dictionary entry keeps
+// its disabled marker (~) and original text. Writing a File that was not
+// modified reproduces the input byte for byte; modified blocks and entries are
+// rendered in Bruno's canonical layout while the rest of the file is left
+// untouched.
+//
+// Block bodies come in three kinds: dictionaries (meta, get, headers,
+// params:query, vars, assert, ...), text (body:*, script:*, tests, docs) and
+// lists (vars:secret [ ... ]). Text blocks end at a "}" in column one, as in
+// Bruno's own grammar. Blocks nested in a dictionary are kept as entries (see
+// Entry.Block); Format lifts them to the top level.
+package bru
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"pkt.systems/gruno/internal/parser"
+)
+
+// Kind is the body layout of a block.
+type Kind int
+
+const (
+	// Dict blocks hold "key: value" entries.
+	Dict Kind = iota
+	// Text blocks hold free text (bodies, scripts, tests, docs).
+	Text
+	// List blocks hold one name per line between square brackets.
+	List
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Text:
+		return "text"
+	case List:
+		return "list"
+	}
+	return "dict"
+}
+
+// Pos is a 1-based line and column in the source.
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string { return fmt.Sprintf("%d:%d", p.Line, p.Col) }
+
+// File is a parsed .bru document.
+type File struct {
+	Blocks []*Block
+	// Trailing holds the raw lines after the last block.
+	Trailing []string
+	// noFinalNewline records that the source did not end with a newline.
+	noFinalNewline bool
+}
+
+// Block is one top-level block such as "meta { ... }" or "body:json { ... }".
+type Block struct {
+	Name string
+	Kind Kind
+	Pos  Pos
+	// Leading holds the raw lines (blank lines, comments) before the block.
+	Leading []string
+	// Entries are the lines of Dict and List blocks, including comment and
+	// blank lines.
+	Entries []*Entry
+	// Text is the raw content of a Text block between its braces, indentation
+	// included. See Content and SetContent for the unindented form.
+	Text string
+
+	raw    []string // source lines, header to footer
+	inline bool     // header, body and footer share one line
+	orig   *Block   // snapshot taken at parse time
+}
+
+// Entry is one line (or multi-line value) of a Dict or List block.
+type Entry struct {
+	Key   string
+	Value string
+	// Disabled entries are written with a leading "~" and ignored by runs.
+	Disabled bool
+	// Comment is the text of a comment line ("// ..." or "# ..."); Key is
+	// empty. An entry without Key and Comment is a blank line.
+	Comment string
+	// Block is a block nested in a dictionary, as importers used to write
+	// headers and bodies inside the request block; Key is empty.
+	Block *Block
+	Pos   Pos
+
+	raw  []string
+	orig *Entry
+}
+
+// IsComment reports whether e is a comment line.
+func (e *Entry) IsComment() bool { return e.Key == "" && e.Comment != "" }
+
+// IsBlank reports whether e is an empty line.
+func (e *Entry) IsBlank() bool { return e.Key == "" && e.Comment == "" && e.Block == nil }
+
+var headerRe = regexp.MustCompile(`^(\s*)([A-Za-z][\w.-]*(?::[\w.-]+)*)\s*([{\[])`)
+
+// textBlock reports whether a block named name holds free text.
+func textBlock(name string) bool {
+	switch {
+	case name == "body:form-urlencoded", name == "body:multipart-form":
+		return false
+	case name == "body", strings.HasPrefix(name, "body:"),
+		strings.HasPrefix(name, "script:"), name == "tests", name == "docs":
+		return true
+	}
+	return false
+}
+
+// ParseFile reads and parses the .bru file at path.
+func ParseFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse parses .bru source.
+func Parse(data []byte) (*File, error) {
+	src := string(data)
+	f := &File{}
+	if src == "" {
+		return f, nil
+	}
+	lines := strings.Split(src, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		f.noFinalNewline = true
+	}
+
+	var pending []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		m := headerRe.FindStringSubmatchIndex(line)
+		if m == nil {
+			pending = append(pending, line)
+			continue
+		}
+		b, end, err := parseBlock(lines, i, m)
+		if err != nil {
+			return nil, err
+		}
+		b.Leading = pending
+		pending = nil
+		b.snapshot()
+		f.Blocks = append(f.Blocks, b)
+		i = end
+	}
+	f.Trailing = pending
+	return f, nil
+}
+
+// parseBlock parses the block whose header, matched by headerRe as m, is on
+// lines[i] and returns it with the index of its last line.
+func parseBlock(lines []string, i int, m []int) (*Block, int, error) {
+	line := lines[i]
+	name := line[m[4]:m[5]]
+	b := &Block{Name: name, Pos: Pos{Line: i + 1, Col: m[4] + 1}}
+	open := line[m[6]:m[7]]
+	rest := line[m[7]:]
+	switch {
+	case open == "[":
+		b.Kind = List
+	case textBlock(name):
+		b.Kind = Text
+	default:
+		b.Kind = Dict
+	}
+
+	if inner, ok := inlineBody(open, rest); ok {
+		b.inline = true
+		b.raw = []string{line}
+		b.parseInline(inner, i+1, m[7]+1)
+		return b, i, nil
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, 0, fmt.Errorf("%d:%d: unexpected %q after %s %s", i+1, m[7]+1, strings.TrimSpace(rest), name, open)
+	}
+
+	end, err := b.parseBody(lines, i+1, closer(open), m[3]-m[2])
+	if err != nil {
+		return nil, 0, err
+	}
+	b.raw = append([]string(nil), lines[i:end+1]...)
+	return b, end, nil
+}
+
+// nestedHeader matches a block header inside a dictionary block, as in
+// "post { headers { ... } }". Lines that merely contain a bracket, such as
+// "ids[]: 1", are entries.
+func nestedHeader(line string) []int {
+	m := headerRe.FindStringSubmatchIndex(line)
+	if m == nil {
+		return nil
+	}
+	open, rest := line[m[6]:m[7]], line[m[7]:]
+	if _, ok := inlineBody(open, rest); ok || strings.TrimSpace(rest) == "" {
+		return m
+	}
+	return nil
+}
+
+func closer(open string) string {
+	if open == "[" {
+		return "]"
+	}
+	return "}"
+}
+
+// inlineBody returns the content of a block opened and closed on its header
+// line, e.g. "meta { name: x }".
+func inlineBody(open, rest string) (string, bool) {
+	close := closer(open)[0]
+	depth := 1
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case open[0]:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				if strings.TrimSpace(rest[i+1:]) != "" {
+					return "", false
+				}
+				return rest[:i], true
+			}
+		}
+	}
+	return "", false
+}
+
+func (b *Block) parseInline(inner string, line, col int) {
+	trimmed := strings.TrimSpace(inner)
+	switch b.Kind {
+	case Text:
+		b.Text = trimmed
+	default:
+		if trimmed == "" {
+			return
+		}
+		// Nested inline blocks (e.g. "post { url: x headers { ... } }")
+		// stay opaque; the line is kept as written.
+		if b.Kind == Dict && strings.Contains(trimmed, "{") {
+			b.Text = trimmed
+			return
+		}
+		off := col + strings.Index(inner, trimmed)
+		for _, part := range splitInline(trimmed, b.Kind) {
+			e := parseEntry(part, b.Kind, Pos{Line: line, Col: off + strings.Index(trimmed, part)})
+			e.raw = nil
+			b.Entries = append(b.Entries, e)
+		}
+	}
+}
+
+// splitInline splits the comma-separated entries of an inline block, as in
+// "meta { name: x, type: http }". In dictionaries a comma only separates
+// entries when a "key:" follows it, so values may contain commas.
+func splitInline(s string, kind Kind) []string {
+	parts := strings.Split(s, ",")
+	if kind == List {
+		var out []string
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	out := []string{strings.TrimSpace(parts[0])}
+	for _, p := range parts[1:] {
+		if inlineKeyRe.MatchString(p) {
+			out = append(out, strings.TrimSpace(p))
+			continue
+		}
+		out[len(out)-1] += "," + p
+	}
+	return out
+}
+
+var inlineKeyRe = regexp.MustCompile(`^\s*~?[\w.-]+\s*:`)
+
+// parseBody reads the lines after the header up to the closing line and
+// returns its index. Text blocks close at a line holding only the closer,
+// indented no deeper than the header (indent).
+func (b *Block) parseBody(lines []string, start int, close string, indent int) (int, error) {
+	if b.Kind == Text {
+		var body []string
+		for j := start; j < len(lines); j++ {
+			l := strings.TrimRight(lines[j], "\r")
+			if strings.TrimLeft(l, " \t") == close && len(l)-len(close) <= indent {
+				b.Text = strings.Join(body, "\n")
+				return j, nil
+			}
+			body = append(body, lines[j])
+		}
+		return 0, fmt.Errorf("%s: block %s is not closed", b.Pos, b.Name)
+	}
+	for j := start; j < len(lines); j++ {
+		line := lines[j]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == close {
+			return j, nil
+		}
+		if b.Kind == Dict {
+			if m := nestedHeader(line); m != nil {
+				nb, end, err := parseBlock(lines, j, m)
+				if err != nil {
+					return 0, err
+				}
+				nb.snapshot()
+				b.Entries = append(b.Entries, &Entry{Block: nb, Pos: nb.Pos, raw: lines[j : end+1]})
+				j = end
+				continue
+			}
+		}
+		e := parseEntry(line, b.Kind, Pos{Line: j + 1, Col: len(line) - len(strings.TrimLeft(line, " \t")) + 1})
+		e.raw = []string{line}
+		if b.Kind == Dict && !e.IsComment() && strings.TrimSpace(strings.TrimRight(e.Value, "\r")) == "'''" {
+			// Multi-line value: key: ''' ... '''
+			var val []string
+			closed := false
+			for k := j + 1; k < len(lines); k++ {
+				e.raw = append(e.raw, lines[k])
+				if strings.TrimSpace(lines[k]) == "'''" {
+					j = k
+					closed = true
+					break
+				}
+				val = append(val, lines[k])
+			}
+			if !closed {
+				return 0, fmt.Errorf("%s: multi-line value of %s is not closed", e.Pos, e.Key)
+			}
+			e.Value = outdent(strings.Join(val, "\n"), 4)
+		}
+		b.Entries = append(b.Entries, e)
+	}
+	return 0, fmt.Errorf("%s: block %s is not closed", b.Pos, b.Name)
+}
+
+func parseEntry(line string, kind Kind, pos Pos) *Entry {
+	e := &Entry{Pos: pos}
+	t := strings.TrimSpace(line)
+	switch {
+	case t == "":
+		return e
+	case strings.HasPrefix(t, "//") || strings.HasPrefix(t, "#"):
+		e.Comment = t
+		return e
+	}
+	if rest, ok := strings.CutPrefix(t, "~"); ok {
+		e.Disabled = true
+		t = strings.TrimSpace(rest)
+	}
+	if kind == List {
+		e.Key = strings.TrimSpace(strings.TrimSuffix(t, ","))
+		return e
+	}
+	k, v, ok := strings.Cut(t, ":")
+	e.Key = strings.TrimSpace(k)
+	if ok {
+		e.Value = strings.TrimSpace(v)
+	}
+	return e
+}
+
+// snapshot records the parsed state so unchanged parts are written verbatim.
+func (b *Block) snapshot() {
+	o := *b
+	o.Entries = append([]*Entry(nil), b.Entries...)
+	o.Leading = append([]string(nil), b.Leading...)
+	b.orig = &o
+	for _, e := range b.Entries {
+		ec := *e
+		e.orig = &ec
+	}
+}
+
+func (e *Entry) changed() bool {
+	return e.orig == nil || e.Key != e.orig.Key || e.Value != e.orig.Value ||
+		e.Disabled != e.orig.Disabled || e.Comment != e.orig.Comment ||
+		e.Block != e.orig.Block || (e.Block != nil && e.Block.changed())
+}
+
+func (b *Block) changed() bool {
+	o := b.orig
+	if o == nil || b.Name != o.Name || b.Kind != o.Kind || b.Text != o.Text || len(b.Entries) != len(o.Entries) {
+		return true
+	}
+	for i, e := range b.Entries {
+		if e != o.Entries[i] || e.changed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Bytes renders the file.
+func (f *File) Bytes() []byte {
+	var lines []string
+	for _, b := range f.Blocks {
+		lines = append(lines, b.Leading...)
+		lines = append(lines, b.lines("")...)
+	}
+	lines = append(lines, f.Trailing...)
+	if len(lines) == 0 {
+		return nil
+	}
+	out := strings.Join(lines, "\n")
+	if !f.noFinalNewline {
+		out += "\n"
+	}
+	return []byte(out)
+}
+
+// String renders the file.
+func (f *File) String() string { return string(f.Bytes()) }
+
+// WriteTo writes the rendered file to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(f.Bytes())
+	return int64(n), err
+}
+
+// WriteFile writes the rendered file to path.
+func (f *File) WriteFile(path string) error {
+	return os.WriteFile(path, f.Bytes(), 0o644)
+}
+
+// lines renders the block; indent prefixes the header, footer and rendered
+// entries of nested blocks.
+func (b *Block) lines(indent string) []string {
+	if !b.changed() {
+		return b.raw
+	}
+	open, close := "{", "}"
+	if b.Kind == List {
+		open, close = "[", "]"
+	}
+	out := []string{indent + b.Name + " " + open}
+	switch b.Kind {
+	case Text:
+		if b.Text != "" {
+			out = append(out, strings.Split(b.Text, "\n")...)
+		}
+	case List:
+		var names []*Entry
+		for _, e := range b.Entries {
+			if e.Key != "" {
+				names = append(names, e)
+			}
+		}
+		for i, e := range names {
+			l := indent + "  " + e.Key
+			if e.Disabled {
+				l = indent + "  ~" + e.Key
+			}
+			if i < len(names)-1 {
+				l += ","
+			}
+			out = append(out, l)
+		}
+	default:
+		if b.inline && len(b.Entries) == 0 && b.Text != "" {
+			out = append(out, indent+"  "+b.Text)
+		}
+		for _, e := range b.Entries {
+			if !e.changed() && e.raw != nil {
+				out = append(out, e.raw...)
+				continue
+			}
+			out = append(out, e.render(indent)...)
+		}
+	}
+	return append(out, indent+close)
+}
+
+func (e *Entry) render(indent string) []string {
+	prefix := indent + "  "
+	switch {
+	case e.Block != nil:
+		return e.Block.lines(prefix)
+	case e.IsComment():
+		return []string{prefix + e.Comment}
+	case e.IsBlank():
+		return []string{""}
+	}
+	if e.Disabled {
+		prefix += "~"
+	}
+	if strings.Contains(e.Value, "\n") {
+		out := []string{prefix + e.Key + ": '''"}
+		for _, l := range strings.Split(e.Value, "\n") {
+			if l == "" {
+				out = append(out, "")
+				continue
+			}
+			out = append(out, indent+"    "+l)
+		}
+		return append(out, indent+"  '''")
+	}
+	if e.Value == "" {
+		return []string{prefix + e.Key + ":"}
+	}
+	return []string{prefix + e.Key + ": " + e.Value}
+}
+
+// Block returns the first block named name, or nil.
+func (f *File) Block(name string) *Block {
+	for _, b := range f.Blocks {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// AddBlock appends a new empty block, separated from the previous one by a
+// blank line. The kind follows the name: text for bodies, scripts, tests and
+// docs, a list for vars:secret, a dictionary otherwise.
+func (f *File) AddBlock(name string) *Block {
+	b := &Block{Name: name, Kind: Dict}
+	switch {
+	case name == "vars:secret":
+		b.Kind = List
+	case textBlock(name):
+		b.Kind = Text
+	}
+	if len(f.Blocks) > 0 || len(f.Trailing) > 0 {
+		b.Leading = []string{""}
+	}
+	f.Blocks = append(f.Blocks, b)
+	return b
+}
+
+// RemoveBlock removes every block named name and reports whether one
+// existed. The blank lines and comments before a removed block go with it.
+func (f *File) RemoveBlock(name string) bool {
+	kept := f.Blocks[:0]
+	removed := false
+	for _, b := range f.Blocks {
+		if b.Name == name {
+			removed = true
+			continue
+		}
+		kept = append(kept, b)
+	}
+	f.Blocks = kept
+	return removed
+}
+
+// Entry returns the first entry with key (enabled or not), or nil.
+func (b *Block) Entry(key string) *Entry {
+	for _, e := range b.Entries {
+		if e.Key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the first enabled entry with key.
+func (b *Block) Get(key string) (string, bool) {
+	for _, e := range b.Entries {
+		if e.Key == key && !e.Disabled {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set updates the first entry with key (enabling it) or appends a new one.
+func (b *Block) Set(key, value string) {
+	if e := b.Entry(key); e != nil {
+		e.Value = value
+		e.Disabled = false
+		return
+	}
+	b.Entries = append(b.Entries, &Entry{Key: key, Value: value})
+}
+
+// Delete removes every entry with key and reports whether one existed.
+func (b *Block) Delete(key string) bool {
+	kept := b.Entries[:0:0]
+	removed := false
+	for _, e := range b.Entries {
+		if e.Key == key {
+			removed = true
+			continue
+		}
+		kept = append(kept, e)
+	}
+	b.Entries = kept
+	return removed
+}
+
+// Content returns a Text block's content without Bruno's two-space
+// indentation.
+func (b *Block) Content() string {
+	return outdent(b.Text, 2)
+}
+
+// SetContent replaces a Text block's content, indenting it by two spaces.
+func (b *Block) SetContent(s string) {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		b.Text = ""
+		return
+	}
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "  " + l
+		}
+	}
+	b.Text = strings.Join(lines, "\n")
+}
+
+// outdent removes up to n leading spaces from every line.
+func outdent(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		trim := 0
+		for trim < n && trim < len(l) && l[trim] == ' ' {
+			trim++
+		}
+		lines[i] = l[trim:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type (
+	// ParsedFile is the case model the runner executes.
+	ParsedFile = parser.ParsedFile
+	// MetaBlock holds the meta block of a case.
+	MetaBlock = parser.MetaBlock
+	// RequestBlock holds the method, URL, headers, params and body of a case.
+	RequestBlock = parser.RequestBlock
+	// BodyBlock holds the request body of a case.
+	BodyBlock = parser.BodyBlock
+	// ScriptBlock holds the pre-request and post-response scripts of a case.
+	ScriptBlock = parser.ScriptBlock
+	// AssertRule is one assert block entry.
+	AssertRule = parser.AssertRule
+)
+
+// ParsedFile derives the runner's view of the file, exactly as gru run would
+// read the rendered file; path is recorded as its FilePath.
+func (f *File) ParsedFile(ctx context.Context, path string) (ParsedFile, error) {
+	return parser.Parse(ctx, path, bytes.NewReader(f.Bytes()))
+}
@@ -0,0 +1,217 @@
+package bru
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/parser"
+)
+
+func TestRoundTripSampleData(t *testing.T) {
+	var paths []string
+	err := filepath.WalkDir("..", func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if strings.HasSuffix(path, ".bru") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no .bru files found")
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if got := f.Bytes(); string(got) != string(data) {
+			t.Fatalf("%s: round trip differs:\n%s\nwant:\n%s", path, got, data)
+		}
+
+		want, werr := parser.ParseFile(context.Background(), path)
+		got, gerr := f.ParsedFile(context.Background(), path)
+		if (werr == nil) != (gerr == nil) || !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: ParsedFile differs from parser:\n%+v (%v)\nwant:\n%+v (%v)", path, got, gerr, want, werr)
+		}
+	}
+}
+
+const sample = `meta {
+  name: Create user
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+// Headers sent with every attempt.
+headers {
+  Content-Type: application/json
+  ~X-Debug: 1
+  // trace header comes from the env
+  X-Trace: {{trace}}
+}
+
+body:json {
+  {
+    "name": "ada"
+  }
+}
+
+docs {
+  Creates a user.
+}
+`
+
+func TestParseStructure(t *testing.T) {
+	f, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, b := range f.Blocks {
+		names = append(names, b.Name+"/"+b.Kind.String())
+	}
+	if strings.Join(names, " ") != "meta/dict post/dict headers/dict body:json/text docs/text" {
+		t.Fatalf("blocks: %v", names)
+	}
+	h := f.Block("headers")
+	if h.Pos != (Pos{Line: 14, Col: 1}) || len(h.Leading) != 2 || h.Leading[1] != "// Headers sent with every attempt." {
+		t.Fatalf("headers position/leading: %v %q", h.Pos, h.Leading)
+	}
+	if e := h.Entry("X-Debug"); e == nil || !e.Disabled || e.Value != "1" || e.Pos != (Pos{Line: 16, Col: 3}) {
+		t.Fatalf("disabled entry: %+v", e)
+	}
+	if _, ok := h.Get("X-Debug"); ok {
+		t.Fatal("Get returned a disabled entry")
+	}
+	if !h.Entries[2].IsComment() || h.Entries[2].Comment != "// trace header comes from the env" {
+		t.Fatalf("comment entry: %+v", h.Entries[2])
+	}
+	if got := f.Block("body:json").Content(); got != "{\n  \"name\": \"ada\"\n}" {
+		t.Fatalf("content: %q", got)
+	}
+}
+
+func TestEditKeepsUntouchedText(t *testing.T) {
+	f, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Block("meta").Set("seq", "7")
+	h := f.Block("headers")
+	h.Set("X-Debug", "2")
+	h.Set("Accept", "application/json")
+	h.Delete("Content-Type")
+	f.Block("body:json").SetContent("{\"name\": \"grace\"}")
+	f.RemoveBlock("docs")
+	f.AddBlock("vars:post-response").Set("id", "res.body.id")
+	f.AddBlock("script:post-response").SetContent("bru.setVar(\"x\", 1);")
+	secrets := f.AddBlock("vars:secret")
+	secrets.Set("token", "")
+	secrets.Set("password", "")
+	f.Block("post").Set("notes", "line one\nline two")
+
+	want := `meta {
+  name: Create user
+  type: http
+  seq: 7
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+  notes: '''
+    line one
+    line two
+  '''
+}
+
+// Headers sent with every attempt.
+headers {
+  X-Debug: 2
+  // trace header comes from the env
+  X-Trace: {{trace}}
+  Accept: application/json
+}
+
+body:json {
+  {"name": "grace"}
+}
+
+vars:post-response {
+  id: res.body.id
+}
+
+script:post-response {
+  bru.setVar("x", 1);
+}
+
+vars:secret [
+  token,
+  password
+]
+`
+	if got := f.String(); got != want {
+		t.Fatalf("edited file:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The edited file parses back to the same tree and runner view.
+	again, err := Parse(f.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := again.Block("post").Get("notes"); v != "line one\nline two" {
+		t.Fatalf("multi-line value: %q", v)
+	}
+	pf, err := again.ParsedFile(context.Background(), "x.bru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pf.Meta.Seq != 7 || pf.Request.Headers["Accept"] != "application/json" || pf.VarsPost["id"] != "res.body.id" {
+		t.Fatalf("parsed view: %+v", pf)
+	}
+}
+
+func TestRoundTripOddLayouts(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"meta { name: Inline }\n\nget { url: https://example.com }",
+		"meta {\r\n  name: CRLF\r\n}\r\n",
+		"post { url: https://api.test/post headers { X-One: 1 } body:json { { \"k\": \"v\" } } }\n",
+		"vars:secret [\n  a,\n  ~b\n]\n\n\n// trailing comment\n",
+		"docs {\n  text with } brace\n   }\n}\n",
+	} {
+		f, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+		if got := f.String(); got != src {
+			t.Fatalf("round trip of %q gave %q", src, got)
+		}
+	}
+	if _, err := Parse([]byte("meta {\n  name: x\n")); err == nil || !strings.Contains(err.Error(), "1:1: block meta is not closed") {
+		t.Fatalf("unclosed block error: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package bru
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FormatOptions controls Format.
+type FormatOptions struct {
+	// SortKeys sorts the entries of dictionary blocks by key. The meta and
+	// request blocks always use Bruno's key order (name, type, seq, tags and
+	// url, body, auth first); other entries keep their order.
+	SortKeys bool
+}
+
+// blockOrder is the order in which Bruno writes blocks. Blocks not listed
+// keep their relative order and go before docs.
+var blockOrder = []string{
+	"meta",
+	"get", "post", "put", "delete", "patch", "options", "head", "connect", "trace",
+	"params:query", "params:path",
+	"headers",
+	"auth", "auth:awsv4", "auth:basic", "auth:bearer", "auth:digest", "auth:ntlm", "auth:oauth2", "auth:wsse", "auth:apikey",
+	"body", "body:json", "body:text", "body:xml", "body:sparql", "body:form-urlencoded", "body:multipart-form", "body:file", "body:graphql", "body:graphql:vars",
+	"vars", "vars:secret", "vars:pre-request", "vars:post-response",
+	"assert",
+	"script", "script:pre-request", "script:post-response",
+	"tests",
+	"settings",
+	"docs",
+}
+
+// KnownBlock reports whether Bruno reads blocks named name (the legacy
+// "query" block included).
+func KnownBlock(name string) bool {
+	return name == "query" || slices.Contains(blockOrder, name)
+}
+
+// keyOrder lists the keys that lead a block, in order.
+var keyOrder = map[string][]string{
+	"meta": {"name", "type", "seq", "tags"},
+}
+
+func init() {
+	for _, verb := range blockOrder[1:10] {
+		keyOrder[verb] = []string{"url", "body", "auth"}
+	}
+}
+
+// FormatBytes parses src and returns it in canonical layout.
+func FormatBytes(src []byte, opts FormatOptions) ([]byte, error) {
+	f, err := Parse(bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n")))
+	if err != nil {
+		return nil, err
+	}
+	return Format(f, opts).Bytes(), nil
+}
+
+// Format returns f in Bruno's canonical layout: blocks in Bruno's order and
+// separated by one blank line, two-space indentation, one entry per line and
+// JSON bodies pretty-printed. Blocks nested in the request block and
+// comma-separated inline entries, as older importers wrote them, are split
+// out. Comments are kept with the block or entry that follows them. Format
+// does not modify f.
+func Format(f *File, opts FormatOptions) *File {
+	var blocks []*Block
+	seen := map[string]*Block{}
+	add := func(b *Block) {
+		if b.Name == "query" {
+			b.Name = "params:query"
+		}
+		if prev := seen[b.Name]; prev != nil && b.Kind == Dict && prev.Kind == Dict && b.Text == "" && prev.Text == "" {
+			prev.Entries = append(append([]*Entry(nil), prev.Entries...), b.Entries...)
+			return
+		}
+		seen[b.Name] = b
+		blocks = append(blocks, b)
+	}
+	for _, b := range f.Blocks {
+		for _, nb := range flatten(b) {
+			add(nb)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blockRank(blocks[i].Name) < blockRank(blocks[j].Name) })
+
+	out := &File{}
+	for i, b := range blocks {
+		b = canonical(b, opts)
+		b.Leading = comments(b.Leading)
+		if i > 0 {
+			b.Leading = append([]string{""}, b.Leading...)
+		}
+		out.Blocks = append(out.Blocks, b)
+	}
+	if trailing := comments(f.Trailing); len(trailing) > 0 {
+		if len(out.Blocks) > 0 {
+			trailing = append([]string{""}, trailing...)
+		}
+		out.Trailing = trailing
+	}
+	return out
+}
+
+func blockRank(name string) int {
+	for i, n := range blockOrder {
+		if n == name {
+			if n == "docs" {
+				return i + 1
+			}
+			return i
+		}
+	}
+	return len(blockOrder) - 1
+}
+
+// comments keeps the comment lines of raw, trimmed.
+func comments(raw []string) []string {
+	var out []string
+	for _, l := range raw {
+		if t := strings.TrimSpace(l); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// flatten copies b and lifts the blocks nested in it to the top level. A body
+// block nested in a request block without a body entry sets that entry.
+func flatten(b *Block) []*Block {
+	c := *b
+	c.Entries = nil
+	var nested []*Block
+	for _, e := range b.Entries {
+		if e.Block == nil {
+			c.Entries = append(c.Entries, e)
+			continue
+		}
+		for _, nb := range flatten(e.Block) {
+			nb.Leading = nil
+			nested = append(nested, nb)
+		}
+	}
+	if len(nested) == 0 {
+		return []*Block{&c}
+	}
+	if _, verb := keyOrder[c.Name]; verb && c.Name != "meta" && c.Entry("body") == nil {
+		for _, nb := range nested {
+			if typ, ok := strings.CutPrefix(nb.Name, "body:"); ok && !strings.Contains(typ, ":") {
+				c.Entries = append(c.Entries, &Entry{Key: "body", Value: typ})
+				break
+			}
+		}
+	}
+	return append([]*Block{&c}, nested...)
+}
+
+// canonical returns a fresh copy of b laid out canonically. Dictionaries
+// whose inline content could not be split into entries are kept as written.
+func canonical(b *Block, opts FormatOptions) *Block {
+	if b.Kind == Dict && b.Text != "" {
+		c := *b
+		return &c
+	}
+	c := &Block{Name: b.Name, Kind: b.Kind, Leading: b.Leading}
+	switch b.Kind {
+	case Text:
+		content := dedent(b.Text)
+		switch b.Name {
+		case "body:json", "body:graphql:vars":
+			content = formatJSON(content)
+		}
+		c.SetContent(content)
+	default:
+		c.Entries = canonicalEntries(b, opts)
+	}
+	return c
+}
+
+// canonicalEntries copies the entries of b without blank lines, ordering
+// them by keyOrder or, with SortKeys, by key. Comments move with the entry
+// after them.
+func canonicalEntries(b *Block, opts FormatOptions) []*Entry {
+	type group struct {
+		entries []*Entry
+		key     string
+	}
+	var groups []group
+	var pending []*Entry
+	for _, e := range b.Entries {
+		switch {
+		case e.IsBlank():
+			continue
+		case e.IsComment():
+			pending = append(pending, &Entry{Comment: e.Comment})
+			continue
+		}
+		ne := &Entry{Key: e.Key, Value: e.Value, Disabled: e.Disabled}
+		if b.Name == "meta" {
+			ne.Value = strings.TrimSuffix(ne.Value, ",")
+		}
+		groups = append(groups, group{entries: append(pending, ne), key: e.Key})
+		pending = nil
+	}
+
+	rank := func(key string) int {
+		for i, k := range keyOrder[b.Name] {
+			if k == key {
+				return i
+			}
+		}
+		return len(keyOrder[b.Name])
+	}
+	sortKeys := opts.SortKeys && b.Kind == Dict && keyOrder[b.Name] == nil
+	sort.SliceStable(groups, func(i, j int) bool {
+		if sortKeys {
+			return strings.ToLower(groups[i].key) < strings.ToLower(groups[j].key)
+		}
+		return rank(groups[i].key) < rank(groups[j].key)
+	})
+
+	var out []*Entry
+	for _, g := range groups {
+		out = append(out, g.entries...)
+	}
+	return append(out, pending...)
+}
+
+// dedent removes the indentation common to all non-blank lines of s and the
+// blank lines around it.
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	common := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if common < 0 || n < common {
+			common = n
+		}
+	}
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			lines[i] = ""
+		} else if common > 0 {
+			lines[i] = l[common:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatJSON pretty-prints a JSON body with two-space indentation, keeping
+// key order. Unquoted {{var}} placeholders are allowed; bodies that are not
+// JSON otherwise are returned unchanged.
+func formatJSON(s string) string {
+	var (
+		masked       strings.Builder
+		placeholders []string
+		inString     bool
+	)
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case inString && ch == '\\' && i+1 < len(s):
+			masked.WriteString(s[i : i+2])
+			i++
+			continue
+		case ch == '"':
+			inString = !inString
+		case !inString && strings.HasPrefix(s[i:], "{{"):
+			if end := strings.Index(s[i:], "}}"); end > 0 {
+				fmt.Fprintf(&masked, "\"\\u0000gru%d\"", len(placeholders))
+				placeholders = append(placeholders, s[i:i+end+2])
+				i += end + 1
+				continue
+			}
+		}
+		masked.WriteByte(ch)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(masked.String()), "", "  "); err != nil {
+		return s
+	}
+	out := buf.String()
+	for i, p := range placeholders {
+		out = strings.Replace(out, fmt.Sprintf("\"\\u0000gru%d\"", i), p, 1)
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+package bru
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatCanonicalLayout(t *testing.T) {
+	src := `// Imported from service.wsdl
+tests {
+    test("ok", function() {
+      expect(res.status).to.equal(200);
+    });
+}
+body:json {
+      {"name":"ada","id":{{id}},"tags":["a","{{tag}}"]}
+}
+
+
+headers {
+
+    X-Trace: {{trace}}
+  // json only
+  Content-Type: application/json
+}
+meta { seq: 2, name: Create, type: http }
+post {
+  auth: none
+  body: json
+  url: {{baseUrl}}/users
+}
+docs {
+  Creates a user.
+}
+`
+	want := `meta {
+  name: Create
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+headers {
+  X-Trace: {{trace}}
+  // json only
+  Content-Type: application/json
+}
+
+body:json {
+  {
+    "name": "ada",
+    "id": {{id}},
+    "tags": [
+      "a",
+      "{{tag}}"
+    ]
+  }
+}
+
+// Imported from service.wsdl
+tests {
+  test("ok", function() {
+    expect(res.status).to.equal(200);
+  });
+}
+
+docs {
+  Creates a user.
+}
+`
+	got, err := FormatBytes([]byte(src), FormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("formatted:\n%s\nwant:\n%s", got, want)
+	}
+
+	sorted, err := FormatBytes([]byte(src), FormatOptions{SortKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sorted), "headers {\n  // json only\n  Content-Type: application/json\n  X-Trace: {{trace}}\n}") ||
+		!strings.Contains(string(sorted), "meta {\n  name: Create\n  type: http\n  seq: 2\n}") {
+		t.Fatalf("sorted:\n%s", sorted)
+	}
+}
+
+func TestFormatLiftsNestedBlocks(t *testing.T) {
+	// The layout ImportWSDL used to write.
+	src := `meta { name: GetUser, type: http }
+
+post {
+  url: {{baseUrl}}
+  headers {
+    Content-Type: text/xml
+    SOAPAction: "urn:GetUser"
+  }
+  body:xml {
+    <soap:Envelope>
+      <soap:Body/>
+    </soap:Envelope>
+  }
+}
+`
+	want := `meta {
+  name: GetUser
+  type: http
+}
+
+post {
+  url: {{baseUrl}}
+  body: xml
+}
+
+headers {
+  Content-Type: text/xml
+  SOAPAction: "urn:GetUser"
+}
+
+body:xml {
+  <soap:Envelope>
+    <soap:Body/>
+  </soap:Envelope>
+}
+`
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.String() != src {
+		t.Fatalf("nested blocks do not round trip:\n%s", f)
+	}
+	if got := Format(f, FormatOptions{}).String(); got != want {
+		t.Fatalf("formatted:\n%s\nwant:\n%s", got, want)
+	}
+	if f.String() != src {
+		t.Fatal("Format modified its input")
+	}
+
+	pf, err := Format(f, FormatOptions{}).ParsedFile(context.Background(), "x.bru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pf.Meta.Name != "GetUser" || pf.Request.Body.Type != "xml" || pf.Request.Headers["SOAPAction"] != `"urn:GetUser"` {
+		t.Fatalf("parsed view: %+v", pf)
+	}
+}
+
+func TestFormatSampleDataIsStable(t *testing.T) {
+	var paths []string
+	_ = filepath.WalkDir("..", func(path string, d os.DirEntry, err error) error {
+		if err == nil && strings.HasSuffix(path, ".bru") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		once, err := FormatBytes(data, FormatOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		twice, err := FormatBytes(once, FormatOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(once) != string(twice) {
+			t.Fatalf("%s: formatting is not idempotent:\n%s\nthen:\n%s", path, once, twice)
+		}
+
+		before, _ := Parse(data)
+		after, _ := Parse(once)
+		want, _ := before.ParsedFile(context.Background(), path)
+		got, _ := after.ParsedFile(context.Background(), path)
+		if !reflect.DeepEqual(got.Meta, want.Meta) || !reflect.DeepEqual(got.Assert, want.Assert) ||
+			got.Request.URL != want.Request.URL || !reflect.DeepEqual(got.Request.Headers, want.Request.Headers) ||
+			got.Request.Body.Type != want.Request.Body.Type {
+			t.Fatalf("%s: formatting changed the request:\n%+v\nwant:\n%+v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gruno
+
+import (
+	"pkt.systems/gruno/internal/cassette"
+	"pkt.systems/gruno/internal/runner"
+)
+
+// CassetteOptions tune cassette matching (query order, headers), network
+// pass-through on replay misses and which request headers are masked on disk.
+type CassetteOptions = cassette.Options
+
+// ErrNoCassetteInteraction is returned by replay for unmatched requests unless
+// pass-through is enabled.
+var ErrNoCassetteInteraction = cassette.ErrNoInteraction
+
+// RecordCassettes returns an Option that performs real requests and stores
+// each interaction in dir, keyed by method, URL and body hash. Sensitive
+// request headers (Authorization, Proxy-Authorization, Cookie and API keys)
+// are always masked.
+func RecordCassettes(dir string, opts CassetteOptions) (Option, error) {
+	opts.RedactHeaders = append(append([]string{}, sensitiveHeaders...), opts.RedactHeaders...)
+	rec, err := cassette.NewRecorder(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return runner.WithTransport(rec.Transport), nil
+}
+
+// ReplayCassettes returns an Option that serves responses recorded in dir
+// instead of using the network. The headers RecordCassettes always masks are
+// masked here too, so they match by their recorded placeholder.
+func ReplayCassettes(dir string, opts CassetteOptions) (Option, error) {
+	opts.RedactHeaders = append(append([]string{}, sensitiveHeaders...), opts.RedactHeaders...)
+	rep, err := cassette.NewReplayer(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return runner.WithTransport(rep.Transport), nil
+}
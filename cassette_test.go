@@ -0,0 +1,69 @@
+package gruno
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestRecordAndReplayCassettes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"partner"}`))
+	}))
+
+	tmp := t.TempDir()
+	bru := `meta { name: Partner }
+
+get {
+  url: ` + srv.URL + `/partner
+}
+
+tests {
+  test("recorded body", function() { expect(res.body.name).to.equal("partner"); });
+}
+`
+	bruPath := filepath.Join(tmp, "partner.bru")
+	if err := os.WriteFile(bruPath, []byte(bru), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cassettes := filepath.Join(tmp, "cassettes")
+	logger := pslog.New(os.Stderr)
+
+	record, err := RecordCassettes(cassettes, CassetteOptions{})
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	g, _ := New(context.Background(), record, WithLogger(logger))
+	if res, err := g.RunFile(context.Background(), bruPath, RunOptions{}); err != nil || !res.Passed {
+		t.Fatalf("record run: %v %+v", err, res)
+	}
+	srv.Close()
+
+	replay, err := ReplayCassettes(cassettes, CassetteOptions{})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	g, _ = New(context.Background(), replay, WithLogger(logger))
+	res, err := g.RunFile(context.Background(), bruPath, RunOptions{})
+	if err != nil || !res.Passed {
+		t.Fatalf("replay run: %v %+v", err, res)
+	}
+
+	if err := os.WriteFile(bruPath, []byte(strings.Replace(bru, "/partner", "/other", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err = g.RunFile(context.Background(), bruPath, RunOptions{})
+	if err != nil {
+		t.Fatalf("replay miss should be a case failure, got %v", err)
+	}
+	if res.Passed || !strings.Contains(res.ErrorText, "no recorded interaction") {
+		t.Fatalf("expected replay miss failure, got %+v", res)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isArchive reports whether target names a collection archive gru run can
+// read directly: .zip, .tar, .tar.gz or .tgz.
+func isArchive(target string) bool {
+	lower := strings.ToLower(target)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// openArchive opens a collection archive as an fs.FS. It returns the folder
+// holding the collection: the archive root when it has a bruno.json or more
+// than one entry, else its single top-level folder.
+func openArchive(target string) (fs.FS, string, func() error, error) {
+	var fsys fs.FS
+	closeFn := func() error { return nil }
+	if strings.HasSuffix(strings.ToLower(target), ".zip") {
+		zr, err := zip.OpenReader(target)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		fsys, closeFn = zr, zr.Close
+	} else {
+		zr, err := tarToZip(target)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		fsys = zr
+	}
+
+	root := "."
+	if _, err := fs.Stat(fsys, "bruno.json"); err != nil {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			closeFn()
+			return nil, "", nil, err
+		}
+		if len(entries) == 1 && entries[0].IsDir() {
+			root = entries[0].Name()
+		}
+	}
+	return fsys, root, closeFn, nil
+}
+
+// tarToZip reads a (gzipped) tar archive into an in-memory zip, whose reader
+// is an fs.FS.
+func tarToZip(target string) (*zip.Reader, error) {
+	f, err := os.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if lower := strings.ToLower(target); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: strings.TrimPrefix(hdr.Name, "./"), Method: zip.Store, Modified: hdr.ModTime})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, tr); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// archivePath maps a file flag of a run from an archive: a relative path is
+// taken from the collection root inside the archive when it exists there,
+// else from disk when it exists there. Absolute paths always come from disk.
+func archivePath(fsys fs.FS, root, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	inArchive := path.Join(root, filepath.ToSlash(p))
+	if _, err := fs.Stat(fsys, inArchive); err == nil {
+		return inArchive
+	}
+	if _, err := os.Stat(p); err == nil {
+		if abs, err := filepath.Abs(p); err == nil {
+			return abs
+		}
+	}
+	return inArchive
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oasdiff/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"pkt.systems/gruno"
+	"pkt.systems/gruno/internal/export"
+	"pkt.systems/gruno/internal/runner"
+)
+
+func newExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export .bru requests to other formats (curl, openapi, k6, snippet)",
+	}
+
+	curlCmd := &cobra.Command{
+		Use:   "curl <file|folder>",
+		Short: "Render fully interpolated curl commands",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reqs, err := buildExportRequests(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			return withExportOutput(cmd, func(w io.Writer) error {
+				for i, pr := range reqs {
+					out, err := export.Curl(pr.Request)
+					if err != nil {
+						return fmt.Errorf("%s: %w", pr.FilePath, err)
+					}
+					if i > 0 {
+						fmt.Fprintln(w)
+					}
+					fmt.Fprintf(w, "# %s (%s)\n%s\n", pr.Name, pr.FilePath, out)
+				}
+				return nil
+			})
+		},
+	}
+
+	openapiCmd := &cobra.Command{
+		Use:   "openapi <file|folder>",
+		Short: "Generate an OpenAPI 3 document from a collection",
+		Long: `Generate an OpenAPI 3 document from a collection. Paths, parameters and
+request bodies come from the .bru/.http files and docs blocks become
+descriptions. With --infer-responses the collection is executed and the JSON
+responses observed across all iterations become response schemas.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runExportOpenAPI,
+	}
+	openapiCmd.Flags().String("title", "", "API title (default: collection folder name)")
+	openapiCmd.Flags().String("api-version", "1.0.0", "API version (info.version)")
+	openapiCmd.Flags().String("format", "", "Output format: yaml or json (default: from --output extension, else yaml)")
+	openapiCmd.Flags().Bool("infer-responses", false, "Execute the collection and infer response schemas from JSON responses")
+	openapiCmd.Flags().Int("iteration-count", 0, "Iterations to run with --infer-responses (schemas are merged)")
+	openapiCmd.Flags().String("csv-file-path", "", "CSV dataset for --infer-responses iterations")
+	openapiCmd.Flags().String("json-file-path", "", "JSON dataset for --infer-responses iterations")
+	openapiCmd.Flags().Bool("insecure", false, "Skip TLS verification with --infer-responses")
+	openapiCmd.Flags().Int("timeout", 15, "Per-request timeout seconds with --infer-responses")
+
+	k6Cmd := &cobra.Command{
+		Use:   "k6 <file|folder>",
+		Short: "Generate a k6 load-test script from a collection",
+		Long: `Generate a k6 load-test script from a collection. Requests run in seq order,
+vars:post-response and bru.setVar chain values between requests, and assert
+rules and expect-based tests become check() calls where they translate.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runExportK6,
+	}
+	k6Cmd.Flags().Bool("env-mapping", false, "Read variables from k6 __ENV at run time (env values become defaults) instead of inlining them")
+	k6Cmd.Flags().Int("vus", 1, "k6 virtual users")
+	k6Cmd.Flags().Int("iterations", 1, "k6 total iterations (ignored with --duration)")
+	k6Cmd.Flags().String("duration", "", "k6 test duration, e.g. 30s")
+
+	snippetCmd := &cobra.Command{
+		Use:   "snippet <file|folder>",
+		Short: "Render client code snippets (Go, Python requests, JS fetch, HTTPie)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lang, _ := cmd.Flags().GetString("lang")
+			if lang == "" {
+				return fmt.Errorf("--lang is required (%s)", strings.Join(export.SnippetLanguages, ", "))
+			}
+			reqs, err := buildExportRequests(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			comment := export.SnippetComment(lang)
+			return withExportOutput(cmd, func(w io.Writer) error {
+				for i, pr := range reqs {
+					out, err := export.Snippet(pr.Request, lang)
+					if err != nil {
+						return fmt.Errorf("%s: %w", pr.FilePath, err)
+					}
+					if i > 0 {
+						fmt.Fprintln(w)
+					}
+					fmt.Fprintf(w, "%s %s (%s)\n%s", comment, pr.Name, pr.FilePath, out)
+					if !strings.HasSuffix(out, "\n") {
+						fmt.Fprintln(w)
+					}
+				}
+				return nil
+			})
+		},
+	}
+	snippetCmd.Flags().String("lang", "", "Snippet language: "+strings.Join(export.SnippetLanguages, ", "))
+
+	addLoggingFlags(exportCmd.Flags())
+	for _, c := range []*cobra.Command{curlCmd, openapiCmd, k6Cmd, snippetCmd} {
+		addLoggingFlags(c.Flags())
+		addExportFlags(c.Flags())
+	}
+
+	exportCmd.AddCommand(curlCmd, openapiCmd, k6Cmd, snippetCmd)
+	return exportCmd
+}
+
+func addExportFlags(flags *pflag.FlagSet) {
+	flags.String("env", "", "Environment name (environments/<name>.bru) or path to an environment .bru file")
+	flags.StringArray("var", nil, "Override variable (key=value)")
+	flags.StringSlice("tags", nil, "Only export cases with these tags")
+	flags.StringSlice("exclude-tags", nil, "Skip cases with these tags")
+	flags.BoolP("recursive", "r", true, "Recurse into subfolders")
+	flags.StringP("output", "o", "", "Write to this file instead of stdout")
+}
+
+// buildExportRequests builds the requests under target the way gru run would,
+// using the --env, --var, tag and --recursive flags.
+func buildExportRequests(cmd *cobra.Command, target string) ([]gruno.PreparedRequest, error) {
+	opts, err := exportRunOptions(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+	return gruno.BuildRequests(context.Background(), target, opts)
+}
+
+// exportRunOptions maps the export flags onto run options.
+func exportRunOptions(cmd *cobra.Command, target string) (gruno.RunOptions, error) {
+	envName, _ := cmd.Flags().GetString("env")
+	varsList, _ := cmd.Flags().GetStringArray("var")
+	tags, _ := cmd.Flags().GetStringSlice("tags")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude-tags")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+
+	envPath, err := resolveExportEnv(envName, target)
+	var httpEnv string
+	if envName != "" && !strings.ContainsRune(envName, os.PathSeparator) && hasHTTPClientEnv(target, envName) {
+		// .http files take the environment from http-client.env.json.
+		httpEnv = envName
+		if err != nil {
+			envPath, err = "", nil
+		}
+	}
+	if err != nil {
+		return gruno.RunOptions{}, err
+	}
+	vars := map[string]string{}
+	for _, kv := range varsList {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return gruno.RunOptions{}, fmt.Errorf("invalid --var %q", kv)
+		}
+		vars[k] = v
+	}
+	return gruno.RunOptions{
+		EnvPath:       envPath,
+		Vars:          vars,
+		Tags:          tags,
+		ExcludeTags:   exclude,
+		Recursive:     recursive,
+		RecursiveSet:  true,
+		HTTPClientEnv: httpEnv,
+		Logger:        stderrLoggerFromCmd(cmd),
+	}, nil
+}
+
+func runExportOpenAPI(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	title, _ := cmd.Flags().GetString("title")
+	version, _ := cmd.Flags().GetString("api-version")
+	format, _ := cmd.Flags().GetString("format")
+	infer, _ := cmd.Flags().GetBool("infer-responses")
+	iterCount, _ := cmd.Flags().GetInt("iteration-count")
+	csvPath, _ := cmd.Flags().GetString("csv-file-path")
+	jsonPath, _ := cmd.Flags().GetString("json-file-path")
+	insecure, _ := cmd.Flags().GetBool("insecure")
+	timeoutSec, _ := cmd.Flags().GetInt("timeout")
+	outPath, _ := cmd.Flags().GetString("output")
+
+	if format == "" {
+		format = "yaml"
+		if strings.EqualFold(filepath.Ext(outPath), ".json") {
+			format = "json"
+		}
+	}
+	if format != "yaml" && format != "json" {
+		return fmt.Errorf("unsupported --format %q (use yaml or json)", format)
+	}
+	if csvPath != "" && jsonPath != "" {
+		return fmt.Errorf("choose either --csv-file-path or --json-file-path")
+	}
+
+	opts, err := exportRunOptions(cmd, target)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	cases, vars, err := runner.LoadCases(ctx, target, opts)
+	if err != nil {
+		return err
+	}
+	root := target
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		root = filepath.Dir(target)
+	}
+	aopts := export.OpenAPIOptions{Title: title, Version: version, Root: root, Vars: vars}
+
+	if infer {
+		client, err := buildHTTPClient(insecure, "", false, "", false, false)
+		if err != nil {
+			return fmt.Errorf("http client: %w", err)
+		}
+		opts.IterationCount = iterCount
+		opts.CSVFilePath = csvPath
+		opts.JSONFilePath = jsonPath
+		if timeoutSec > 0 {
+			opts.Timeout = time.Duration(timeoutSec) * time.Second
+		}
+		obs, sum, err := export.ObserveResponses(ctx, target, opts, gruno.WithLogger(opts.Logger), gruno.WithHTTPClient(client))
+		if err != nil {
+			return fmt.Errorf("infer responses: %w", err)
+		}
+		if sum.Failed > 0 {
+			opts.Logger.Warn("export.openapi.failures", "failed", sum.Failed, "total", sum.Total)
+		}
+		aopts.Responses = obs
+	}
+
+	doc, err := export.OpenAPI(cases, aopts)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal openapi: %w", err)
+	}
+	if format == "yaml" {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return fmt.Errorf("marshal openapi: %w", err)
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	return withExportOutput(cmd, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func runExportK6(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	envMapping, _ := cmd.Flags().GetBool("env-mapping")
+	vus, _ := cmd.Flags().GetInt("vus")
+	iterations, _ := cmd.Flags().GetInt("iterations")
+	duration, _ := cmd.Flags().GetString("duration")
+
+	opts, err := exportRunOptions(cmd, target)
+	if err != nil {
+		return err
+	}
+	cases, vars, err := runner.LoadCases(context.Background(), target, opts)
+	if err != nil {
+		return err
+	}
+	script, err := export.K6(cases, export.K6Options{
+		Vars:       vars,
+		EnvMapping: envMapping,
+		VUs:        vus,
+		Iterations: iterations,
+		Duration:   duration,
+		Source:     filepath.ToSlash(target),
+	})
+	if err != nil {
+		return err
+	}
+	return withExportOutput(cmd, func(w io.Writer) error {
+		_, err := io.WriteString(w, script)
+		return err
+	})
+}
+
+// resolveExportEnv maps --env to a file. A bare name resolves to
+// environments/<name>.bru in the working directory or, failing that, in the
+// collection containing target (the nearest parent with bruno.json).
+func resolveExportEnv(env, target string) (string, error) {
+	if env == "" {
+		return "", nil
+	}
+	if strings.ContainsRune(env, os.PathSeparator) || strings.HasSuffix(env, ".bru") {
+		if _, err := os.Stat(env); err != nil {
+			return "", fmt.Errorf("env file not found: %w", err)
+		}
+		return env, nil
+	}
+	rel := filepath.Join("environments", env+".bru")
+	if _, err := os.Stat(rel); err == nil {
+		return rel, nil
+	}
+	dir, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		candidate := filepath.Join(dir, rel)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, "bruno.json")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf("env file not found: %s", rel)
+}
+
+// withExportOutput hands fn the --output file, or stdout when unset.
+func withExportOutput(cmd *cobra.Command, fn func(io.Writer) error) error {
+	outPath, _ := cmd.Flags().GetString("output")
+	if outPath == "" {
+		return fn(cmd.OutOrStdout())
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create output: %w", err)
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
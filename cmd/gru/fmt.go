@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"pkt.systems/gruno/bru"
+)
+
+func newFmtCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fmt [file|folder...]",
+		Short: "Rewrite .bru files in Bruno's canonical layout",
+		Long: `Rewrite .bru files in Bruno's canonical layout: blocks in Bruno's order,
+two-space indentation, one entry per line and pretty-printed JSON bodies.
+Folders are searched recursively (default: current directory). Without -w
+the formatted files are printed; with --check nothing is written, a diff is
+printed for every file that is not formatted and the command fails.`,
+		RunE: runFmt,
+	}
+	cmd.Flags().BoolP("write", "w", false, "Write the result back to the files")
+	cmd.Flags().Bool("check", false, "Print a diff for unformatted files and fail if there are any")
+	cmd.Flags().Bool("sort-keys", false, "Sort dictionary entries by key (meta and request blocks keep Bruno's order)")
+	addLoggingFlags(cmd.Flags())
+	return cmd
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	write, _ := cmd.Flags().GetBool("write")
+	check, _ := cmd.Flags().GetBool("check")
+	sortKeys, _ := cmd.Flags().GetBool("sort-keys")
+	if write && check {
+		return fmt.Errorf("-w and --check are mutually exclusive")
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	files, err := bruFiles(args)
+	if err != nil {
+		return err
+	}
+	opts := bru.FormatOptions{SortKeys: sortKeys}
+	out := cmd.OutOrStdout()
+	logger := stderrLoggerFromCmd(cmd)
+	unformatted := 0
+	for _, path := range files {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := bru.FormatBytes(src, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		switch {
+		case check:
+			if !bytes.Equal(src, formatted) {
+				unformatted++
+				writeUnifiedDiff(out, path, string(src), string(formatted))
+			}
+		case write:
+			if bytes.Equal(src, formatted) {
+				continue
+			}
+			if err := os.WriteFile(path, formatted, 0o644); err != nil {
+				return err
+			}
+			logger.Info("formatted", "file", path)
+		default:
+			if _, err := out.Write(formatted); err != nil {
+				return err
+			}
+		}
+	}
+	if unformatted > 0 {
+		return fmt.Errorf("%d of %d .bru file(s) not formatted; run gru fmt -w", unformatted, len(files))
+	}
+	return nil
+}
+
+// bruFiles expands args into .bru files, walking folders recursively.
+func bruFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != arg && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".bru") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// writeUnifiedDiff writes a unified diff (three lines of context) from a to b.
+func writeUnifiedDiff(w io.Writer, path, a, b string) {
+	al := splitLines(a)
+	bl := splitLines(b)
+
+	// Longest common subsequence table, filled from the end.
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	type op struct {
+		kind byte // ' ', '-' or '+'
+		text string
+		ai   int // lines of a before this op
+		bi   int
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			ops = append(ops, op{' ', al[i], i, j})
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', al[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{'+', bl[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	fmt.Fprintf(w, "--- %s.orig\n+++ %s\n", path, path)
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		start := max(k-context, 0)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+		var acount, bcount int
+		for _, o := range ops[start:end] {
+			if o.kind != '+' {
+				acount++
+			}
+			if o.kind != '-' {
+				bcount++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(ops[start].ai, acount), hunkRange(ops[start].bi, bcount))
+		for _, o := range ops[start:end] {
+			fmt.Fprintf(w, "%c%s\n", o.kind, o.text)
+		}
+		k = end
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
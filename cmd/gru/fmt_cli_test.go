@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmtCheckAndWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "case.bru")
+	if err := os.WriteFile(path, []byte("meta { name: Case, type: http }\n\nget {\n    url: https://example.com\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := newRootCmd()
+		cmd.SetArgs(append([]string{"fmt", "--log-level", "error"}, args...))
+		cmd.SetContext(context.Background())
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	out, err := run("--check", dir)
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 .bru file(s) not formatted") {
+		t.Fatalf("check error: %v", err)
+	}
+	for _, want := range []string{"--- " + path + ".orig", "+++ " + path, "-meta { name: Case, type: http }", "+  type: http", "-    url: https://example.com", "+  url: https://example.com"} {
+		if !strings.Contains(out, want+"\n") {
+			t.Fatalf("diff missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := run("-w", dir); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("--check", dir); err != nil || out != "" {
+		t.Fatalf("check after -w: %v\n%s", err, out)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "meta {\n  name: Case\n  type: http\n}\n\nget {\n  url: https://example.com\n}\n" {
+		t.Fatalf("written file:\n%s", data)
+	}
+}
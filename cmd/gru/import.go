@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"pkt.systems/gruno/internal/importer"
@@ -11,7 +13,7 @@ import (
 func newImportCmd() *cobra.Command {
 	importCmd := &cobra.Command{
 		Use:   "import",
-		Short: "Import a collection from other formats (openapi, wsdl)",
+		Short: "Import a collection from other formats (openapi, wsdl, har, postman, insomnia, curl, http)",
 	}
 
 	openapi := &cobra.Command{
@@ -86,12 +88,204 @@ func newImportCmd() *cobra.Command {
 			return importer.ImportWSDL(context.Background(), opts)
 		},
 	}
+	harCmd := &cobra.Command{
+		Use:   "har",
+		Short: "Import from a HAR capture (browser devtools)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := loggerFromCmd(cmd)
+			src, _ := cmd.Flags().GetString("source")
+			outDir, _ := cmd.Flags().GetString("output")
+			outFile, _ := cmd.Flags().GetString("output-file")
+			name, _ := cmd.Flags().GetString("collection-name")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			disableTests, _ := cmd.Flags().GetBool("disable-test-generation")
+			hosts, _ := cmd.Flags().GetStringSlice("include-host")
+			methods, _ := cmd.Flags().GetStringSlice("include-method")
+			contentTypes, _ := cmd.Flags().GetStringSlice("include-content-type")
+			if src == "" {
+				return fmt.Errorf("--source is required")
+			}
+			if outDir == "" && outFile == "" {
+				return fmt.Errorf("either --output or --output-file is required")
+			}
+			opts := importer.Options{
+				Source:              src,
+				OutputDir:           outDir,
+				OutputFile:          outFile,
+				CollectionName:      name,
+				Insecure:            insecure,
+				Type:                "har",
+				GenerateTests:       !disableTests,
+				GenerateTestsSet:    true,
+				IncludeHosts:        hosts,
+				IncludeMethods:      methods,
+				IncludeContentTypes: contentTypes,
+				Logger:              logger,
+			}
+			return importer.ImportHAR(context.Background(), opts)
+		},
+	}
+
+	postman := &cobra.Command{
+		Use:   "postman",
+		Short: "Import from a Postman collection (v2.0/v2.1)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := loggerFromCmd(cmd)
+			src, _ := cmd.Flags().GetString("source")
+			outDir, _ := cmd.Flags().GetString("output")
+			outFile, _ := cmd.Flags().GetString("output-file")
+			name, _ := cmd.Flags().GetString("collection-name")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			disableTests, _ := cmd.Flags().GetBool("disable-test-generation")
+			envFiles, _ := cmd.Flags().GetStringSlice("environment")
+			if src == "" {
+				return fmt.Errorf("--source is required")
+			}
+			if outDir == "" && outFile == "" {
+				return fmt.Errorf("either --output or --output-file is required")
+			}
+			opts := importer.Options{
+				Source:           src,
+				OutputDir:        outDir,
+				OutputFile:       outFile,
+				CollectionName:   name,
+				Insecure:         insecure,
+				Type:             "postman",
+				GenerateTests:    !disableTests,
+				GenerateTestsSet: true,
+				EnvironmentFiles: envFiles,
+				Logger:           logger,
+			}
+			return importer.ImportPostman(context.Background(), opts)
+		},
+	}
+
+	insomnia := &cobra.Command{
+		Use:   "insomnia",
+		Short: "Import from an Insomnia export (v4 JSON or v5 YAML)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := loggerFromCmd(cmd)
+			src, _ := cmd.Flags().GetString("source")
+			outDir, _ := cmd.Flags().GetString("output")
+			outFile, _ := cmd.Flags().GetString("output-file")
+			name, _ := cmd.Flags().GetString("collection-name")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			disableTests, _ := cmd.Flags().GetBool("disable-test-generation")
+			if src == "" {
+				return fmt.Errorf("--source is required")
+			}
+			if outDir == "" && outFile == "" {
+				return fmt.Errorf("either --output or --output-file is required")
+			}
+			opts := importer.Options{
+				Source:           src,
+				OutputDir:        outDir,
+				OutputFile:       outFile,
+				CollectionName:   name,
+				Insecure:         insecure,
+				Type:             "insomnia",
+				GenerateTests:    !disableTests,
+				GenerateTestsSet: true,
+				Logger:           logger,
+			}
+			return importer.ImportInsomnia(context.Background(), opts)
+		},
+	}
+
+	httpCmd := &cobra.Command{
+		Use:   "http",
+		Short: "Import a VS Code REST Client / JetBrains .http file (with http-client.env.json)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := loggerFromCmd(cmd)
+			src, _ := cmd.Flags().GetString("source")
+			outDir, _ := cmd.Flags().GetString("output")
+			outFile, _ := cmd.Flags().GetString("output-file")
+			name, _ := cmd.Flags().GetString("collection-name")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			disableTests, _ := cmd.Flags().GetBool("disable-test-generation")
+			if src == "" {
+				return fmt.Errorf("--source is required")
+			}
+			if outDir == "" && outFile == "" {
+				return fmt.Errorf("either --output or --output-file is required")
+			}
+			opts := importer.Options{
+				Source:           src,
+				OutputDir:        outDir,
+				OutputFile:       outFile,
+				CollectionName:   name,
+				Insecure:         insecure,
+				Type:             "http",
+				GenerateTests:    !disableTests,
+				GenerateTestsSet: true,
+				Logger:           logger,
+			}
+			return importer.ImportHTTP(context.Background(), opts)
+		},
+	}
+
+	curlCmd := &cobra.Command{
+		Use:   "curl [-- curl command...]",
+		Short: "Import curl command lines (from args, --source or stdin)",
+		Long: `Import one or more curl command lines. Pass the command after "--"
+(gru import curl -- curl -X POST https://...), as a single quoted argument,
+in a file via --source, or on stdin. Without --output the .bru text is
+printed to stdout.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, _ := cmd.Flags().GetString("source")
+			outDir, _ := cmd.Flags().GetString("output")
+			outFile, _ := cmd.Flags().GetString("output-file")
+			name, _ := cmd.Flags().GetString("collection-name")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			disableTests, _ := cmd.Flags().GetBool("disable-test-generation")
+			logger := loggerFromCmd(cmd)
+			if outDir == "" {
+				logger = stderrLoggerFromCmd(cmd)
+			}
+			opts := importer.Options{
+				Source:           src,
+				OutputDir:        outDir,
+				OutputFile:       outFile,
+				CollectionName:   name,
+				Insecure:         insecure,
+				Type:             "curl",
+				GenerateTests:    !disableTests,
+				GenerateTestsSet: true,
+				Logger:           logger,
+				Stdout:           cmd.OutOrStdout(),
+			}
+			switch {
+			case len(args) == 1:
+				opts.Curl = args[0]
+			case len(args) > 1:
+				if args[0] != "curl" {
+					args = append([]string{"curl"}, args...)
+				}
+				opts.Curl = shellJoin(args)
+			case src == "" || src == "-":
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("read stdin: %w", err)
+				}
+				opts.Curl = string(data)
+			}
+			if strings.TrimSpace(opts.Curl) == "" && (src == "" || src == "-") {
+				return fmt.Errorf("a curl command, --source or stdin is required")
+			}
+			return importer.ImportCurl(context.Background(), opts)
+		},
+	}
 
 	addLoggingFlags(importCmd.Flags())
 	addLoggingFlags(openapi.Flags())
 	addLoggingFlags(wsdl.Flags())
+	addLoggingFlags(harCmd.Flags())
+	addLoggingFlags(postman.Flags())
+	addLoggingFlags(insomnia.Flags())
+	addLoggingFlags(curlCmd.Flags())
+	addLoggingFlags(httpCmd.Flags())
 
-	for _, c := range []*cobra.Command{openapi, wsdl} {
+	for _, c := range []*cobra.Command{openapi, wsdl, harCmd, postman, insomnia, curlCmd, httpCmd} {
 		c.Flags().StringP("source", "s", "", "Path or URL to source file")
 		c.Flags().StringP("output", "o", "", "Output directory for collection")
 		c.Flags().StringP("output-file", "f", "", "Output JSON file instead of directory")
@@ -105,7 +299,31 @@ func newImportCmd() *cobra.Command {
 	openapi.Flags().String("strictness", "standard", "Schema assertion strictness: loose|standard|strict")
 	openapi.Flags().StringSliceP("include-path", "i", nil, "Only import operations whose path starts with one of these prefixes (repeatable)")
 	wsdl.Flags().Bool("disable-test-generation", false, "Skip generating response tests")
+	harCmd.Flags().Bool("disable-test-generation", false, "Skip generating status/content-type tests")
+	harCmd.Flags().StringSlice("include-host", nil, "Only import entries for these hosts (subdomains included)")
+	harCmd.Flags().StringSlice("include-method", nil, "Only import entries with these HTTP methods")
+	harCmd.Flags().StringSlice("include-content-type", nil, "Only import entries whose response content type contains one of these values (e.g. json)")
+	postman.Flags().Bool("disable-test-generation", false, "Skip the status test added to requests without Postman tests")
+	postman.Flags().StringSliceP("environment", "e", nil, "Postman environment export(s) to convert into environments/<name>.bru (repeatable)")
+	insomnia.Flags().Bool("disable-test-generation", false, "Skip the status test added to requests without an after-response script")
+
+	curlCmd.Flags().Bool("disable-test-generation", false, "Skip the status test added to each request")
+	httpCmd.Flags().Bool("disable-test-generation", false, "Skip the status test added to each request")
 
-	importCmd.AddCommand(openapi, wsdl)
+	importCmd.AddCommand(openapi, wsdl, harCmd, postman, insomnia, curlCmd, httpCmd)
 	return importCmd
 }
+
+// shellJoin re-quotes args split by the invoking shell so the curl importer
+// can tokenize them again.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && !strings.ContainsAny(a, " \t\n'\"\\$`;&|#*?<>(){}[]!~") {
+			quoted[i] = a
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"pkt.systems/gruno/internal/lint"
+)
+
+func newLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [file|folder]",
+		Short: "Check a collection for parse errors, unresolved vars, secrets and more",
+		Long: `Check a collection (default: current directory) for parse errors, unresolved
+{{vars}} (with --env/--var), duplicate seq numbers, requests without tests,
+hard-coded secrets, invalid JavaScript, unknown blocks and missing file
+bodies. Rules are configured with --enable/--disable/--severity or a
+.grulint.json file ({"disable": [...], "severity": {"missing-tests": "note"}})
+in the collection root. The command fails when a finding is at least as
+severe as --fail-on.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runLint,
+	}
+	cmd.Flags().String("env", "", "Environment name (environments/<name>.bru) or path; enables the unresolved-var rule")
+	cmd.Flags().StringArray("var", nil, "Define variable (key=value)")
+	cmd.Flags().StringP("format", "f", "text", "Output format: text, json or sarif")
+	cmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().String("config", "", "Rule configuration file (default: <collection>/.grulint.json when present)")
+	cmd.Flags().StringSlice("enable", nil, "Run only these rules")
+	cmd.Flags().StringSlice("disable", nil, "Skip these rules")
+	cmd.Flags().StringArray("severity", nil, "Override a rule severity (rule=error|warning|note|off)")
+	cmd.Flags().String("fail-on", "error", "Fail when a finding is at least this severe: error, warning, note or never")
+	cmd.Flags().Bool("list-rules", false, "List the rules and exit")
+	addLoggingFlags(cmd.Flags())
+	return cmd
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	target := "."
+	if len(args) == 1 {
+		target = args[0]
+	}
+	if list, _ := cmd.Flags().GetBool("list-rules"); list {
+		for _, r := range lint.DefaultRules() {
+			fmt.Fprintf(cmd.OutOrStdout(), "%-18s %-8s %s\n", r.ID, r.Severity, r.Description)
+		}
+		return nil
+	}
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	failOn, _ := cmd.Flags().GetString("fail-on")
+	switch format {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown --format %q (text, json, sarif)", format)
+	}
+	switch failOn {
+	case "error", "warning", "note", "never":
+	default:
+		return fmt.Errorf("unknown --fail-on %q (error, warning, note, never)", failOn)
+	}
+
+	opts, err := lintOptions(cmd, target)
+	if err != nil {
+		return err
+	}
+	rules, err := lint.ActiveRules(opts)
+	if err != nil {
+		return err
+	}
+	findings, err := lint.Run(cmd.Context(), target, opts)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	switch format {
+	case "json":
+		err = lint.WriteJSON(w, findings)
+	case "sarif":
+		err = lint.WriteSARIF(w, findings, rules)
+	default:
+		err = lint.WriteText(w, findings)
+	}
+	if err != nil {
+		return err
+	}
+
+	failing := 0
+	for _, f := range findings {
+		if f.Severity.AtLeast(lint.Severity(failOn)) {
+			failing++
+		}
+	}
+	if failing > 0 {
+		return fmt.Errorf("lint: %d finding(s) at or above %s", failing, failOn)
+	}
+	return nil
+}
+
+// lintOptions builds lint options from the config file and flags; flags add
+// to the file's enable/disable lists and override its severities.
+func lintOptions(cmd *cobra.Command, target string) (lint.Options, error) {
+	var opts lint.Options
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		root := target
+		if info, err := os.Stat(target); err == nil && !info.IsDir() {
+			root = filepath.Dir(target)
+		}
+		if _, err := os.Stat(filepath.Join(root, ".grulint.json")); err == nil {
+			configPath = filepath.Join(root, ".grulint.json")
+		}
+	}
+	if configPath != "" {
+		cfg, err := lint.LoadConfig(configPath)
+		if err != nil {
+			return opts, err
+		}
+		opts.Config = cfg
+	}
+	enable, _ := cmd.Flags().GetStringSlice("enable")
+	disable, _ := cmd.Flags().GetStringSlice("disable")
+	severities, _ := cmd.Flags().GetStringArray("severity")
+	opts.Enable = append(opts.Enable, enable...)
+	opts.Disable = append(opts.Disable, disable...)
+	for _, kv := range severities {
+		id, sev, ok := strings.Cut(kv, "=")
+		if !ok {
+			return opts, fmt.Errorf("invalid --severity %q (want rule=level)", kv)
+		}
+		if opts.Severity == nil {
+			opts.Severity = map[string]lint.Severity{}
+		}
+		opts.Severity[id] = lint.Severity(sev)
+	}
+
+	envName, _ := cmd.Flags().GetString("env")
+	envPath, err := resolveExportEnv(envName, target)
+	if err != nil {
+		return opts, err
+	}
+	opts.EnvPath = envPath
+	varsList, _ := cmd.Flags().GetStringArray("var")
+	for _, kv := range varsList {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return opts, errors.New("invalid --var " + kv)
+		}
+		if opts.Vars == nil {
+			opts.Vars = map[string]string{}
+		}
+		opts.Vars[k] = v
+	}
+	return opts, nil
+}
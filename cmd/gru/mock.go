@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"pkt.systems/gruno/internal/importer"
+)
+
+func newMockCmd() *cobra.Command {
+	mockCmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Serve mock services generated from API descriptions (wsdl)",
+	}
+
+	wsdl := &cobra.Command{
+		Use:   "wsdl",
+		Short: "Serve a SOAP mock generated from a WSDL",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := loggerFromCmd(cmd)
+			src, _ := cmd.Flags().GetString("source")
+			listen, _ := cmd.Flags().GetString("listen")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			mtom, _ := cmd.Flags().GetBool("mtom")
+			faultSpecs, _ := cmd.Flags().GetStringArray("fault")
+			if src == "" {
+				return fmt.Errorf("--source is required")
+			}
+			faults := map[string]importer.MockFault{}
+			for _, spec := range faultSpecs {
+				op, rest, ok := strings.Cut(spec, "=")
+				if !ok || strings.TrimSpace(op) == "" {
+					return fmt.Errorf("invalid --fault %q (want Operation=code|message|status)", spec)
+				}
+				f, err := importer.ParseMockFault(rest)
+				if err != nil {
+					return err
+				}
+				faults[strings.TrimSpace(op)] = f
+			}
+			mock, err := importer.NewWSDLMock(context.Background(), importer.MockOptions{
+				Source:   src,
+				Insecure: insecure,
+				Faults:   faults,
+				MTOM:     mtom,
+				Logger:   logger,
+			})
+			if err != nil {
+				return err
+			}
+
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("listen %s: %w", listen, err)
+			}
+			logger.Info("mock.wsdl.listen", "url", "http://"+ln.Addr().String(), "operations", strings.Join(mock.Operations(), ","))
+			srv := &http.Server{Handler: mock}
+			go func() {
+				<-cmd.Context().Done()
+				_ = srv.Close()
+			}()
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addLoggingFlags(mockCmd.Flags())
+	addLoggingFlags(wsdl.Flags())
+
+	wsdl.Flags().StringP("source", "s", "", "Path or URL to WSDL")
+	wsdl.Flags().StringP("listen", "l", "127.0.0.1:8088", "Address to listen on")
+	wsdl.Flags().Bool("insecure", false, "Skip TLS verification when fetching URL")
+	wsdl.Flags().Bool("mtom", false, "Serve base64Binary response elements as MTOM multipart/related attachments")
+	wsdl.Flags().StringArray("fault", nil, "Return a SOAP Fault for an operation: Operation=[code|]message[|status] (repeatable)")
+
+	mockCmd.AddCommand(wsdl)
+	return mockCmd
+}
@@ -43,6 +43,10 @@ func newRootCmd() *cobra.Command {
 	version.SetDefaultModule("pkt.systems/gruno")
 	root.AddCommand(newRunCmd())
 	root.AddCommand(newImportCmd())
+	root.AddCommand(newExportCmd())
+	root.AddCommand(newMockCmd())
+	root.AddCommand(newFmtCmd())
+	root.AddCommand(newLintCmd())
 	root.AddCommand(newVersionCmd())
 	return root
 }
@@ -74,6 +78,20 @@ func loggerFromCmd(cmd *cobra.Command) pslog.Logger {
 	return logger
 }
 
+// stderrLoggerFromCmd builds a logger from the command's logging flags that
+// writes to stderr, for commands whose stdout carries generated output.
+func stderrLoggerFromCmd(cmd *cobra.Command) pslog.Logger {
+	structured, _ := cmd.Flags().GetBool("structured")
+	levelStr, _ := cmd.Flags().GetString("log-level")
+	caller, _ := cmd.Flags().GetBool("log-caller")
+	levelFlagSet := cmd.Flags().Lookup("log-level") != nil && cmd.Flags().Lookup("log-level").Changed
+	logger, err := newLogger(structured, levelStr, levelFlagSet, caller, os.Stderr)
+	if err != nil {
+		return pslog.NewWithOptions(os.Stderr, pslog.Options{MinLevel: pslog.InfoLevel})
+	}
+	return logger
+}
+
 func addLoggingFlags(flags *pflag.FlagSet) {
 	if flags.Lookup("log-level") == nil {
 		flags.String("log-level", "info", "Log level (trace|debug|info|warn|error)")
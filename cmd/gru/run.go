@@ -7,22 +7,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/http/cookiejar"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"pkt.systems/gruno"
+	"pkt.systems/gruno/internal/parser"
 	"pkt.systems/pslog"
 )
 
 func newRunCmd() *cobra.Command {
 	runCmd := &cobra.Command{
-		Use:   "run [folder|file]",
-		Short: "Execute .bru files",
+		Use:   "run [folder|file|archive]",
+		Short: "Execute .bru and .http files, folders or collection archives",
 		Args:  cobra.MaximumNArgs(1),
 		RunE:  runE,
 	}
@@ -39,16 +43,24 @@ func newRunCmd() *cobra.Command {
 	runCmd.Flags().BoolP("recursive", "r", false, "Recurse into subfolders (Bru default: false)")
 	runCmd.Flags().Int("timeout", 15, "Per-request timeout seconds")
 	runCmd.Flags().StringP("output", "o", "", "Write summary to file (see --format)")
-	runCmd.Flags().StringP("format", "f", "json", "Output format: json|junit|html")
+	runCmd.Flags().StringP("format", "f", "json", "Output format: json|junit|html|bruno|ctrf|tap|allure|markdown")
 	runCmd.Flags().String("reporter-json", "", "Write JSON report to path")
 	runCmd.Flags().String("reporter-junit", "", "Write JUnit XML report to path")
 	runCmd.Flags().String("reporter-html", "", "Write HTML report to path")
+	runCmd.Flags().StringArray("reporter", nil, "Write a report as name=path (repeatable): json|junit|html|bruno|ctrf|tap|allure (a directory)|markdown (path defaults to $GITHUB_STEP_SUMMARY)")
 	runCmd.Flags().String("csv-file-path", "", "Path to CSV dataset for data-driven iterations")
 	runCmd.Flags().String("json-file-path", "", "Path to JSON dataset for data-driven iterations")
 	runCmd.Flags().Int("iteration-count", 0, "Execute collection this many times (default 1)")
 	runCmd.Flags().Bool("parallel", false, "Run requests in parallel")
+	runCmd.Flags().String("har", "", "Record every request/response to a HAR 1.2 file (headers redacted like reporters)")
+	runCmd.Flags().String("record", "", "Record every response into this cassette directory")
+	runCmd.Flags().String("replay", "", "Serve responses from this cassette directory instead of the network")
+	runCmd.Flags().Bool("replay-passthrough", false, "Send requests that have no recorded interaction to the network (default: fail them)")
+	runCmd.Flags().Bool("cassette-ignore-query-order", false, "Match cassette requests regardless of query parameter order")
+	runCmd.Flags().StringSlice("cassette-match-headers", nil, "Request headers that must also match when replaying cassettes")
 	runCmd.Flags().Bool("reporter-skip-all-headers", false, "Omit headers from reporter outputs")
 	runCmd.Flags().StringSlice("reporter-skip-headers", nil, "Skip specific headers (case-insensitive) from reporter outputs")
+	runCmd.Flags().Bool("reporter-bodies", false, "Include request/response bodies in reporter outputs (always on for html and bruno)")
 	runCmd.Flags().Bool("insecure", false, "Skip TLS verification")
 	runCmd.Flags().String("cacert", "", "Path to custom CA certificate (PEM)")
 	runCmd.Flags().Bool("ignore-truststore", false, "Use only the provided CA certificate")
@@ -57,6 +69,7 @@ func newRunCmd() *cobra.Command {
 	runCmd.Flags().Bool("disable-cookies", false, "Do not store/send cookies between requests")
 	runCmd.Flags().String("run-pre-request", "", "Executable (with args) to run before each request")
 	runCmd.Flags().String("run-post-request", "", "Executable (with args) to run after each request")
+	runCmd.Flags().Bool("postman-compat", false, "Expose a Postman-style pm object to scripts and tests")
 
 	return runCmd
 }
@@ -115,8 +128,17 @@ func runE(cmd *cobra.Command, args []string) error {
 	reportJSON, _ := cmd.Flags().GetString("reporter-json")
 	reportJUnit, _ := cmd.Flags().GetString("reporter-junit")
 	reportHTML, _ := cmd.Flags().GetString("reporter-html")
+	reporterSpecs, _ := cmd.Flags().GetStringArray("reporter")
+	harPath, _ := cmd.Flags().GetString("har")
+	recordDir, _ := cmd.Flags().GetString("record")
+	replayDir, _ := cmd.Flags().GetString("replay")
+	replayPassthrough, _ := cmd.Flags().GetBool("replay-passthrough")
+	postmanCompat, _ := cmd.Flags().GetBool("postman-compat")
+	ignoreQueryOrder, _ := cmd.Flags().GetBool("cassette-ignore-query-order")
+	matchHeaders, _ := cmd.Flags().GetStringSlice("cassette-match-headers")
 	reportSkipAll, _ := cmd.Flags().GetBool("reporter-skip-all-headers")
 	reportSkip, _ := cmd.Flags().GetStringSlice("reporter-skip-headers")
+	reportBodies, _ := cmd.Flags().GetBool("reporter-bodies")
 	timeoutSec, _ := cmd.Flags().GetInt("timeout")
 	insecure, _ := cmd.Flags().GetBool("insecure")
 	cacert, _ := cmd.Flags().GetString("cacert")
@@ -137,13 +159,59 @@ func runE(cmd *cobra.Command, args []string) error {
 		logger.Fatal("iteration-count must be >= 0", "value", iterCount)
 		return nil
 	}
+	if recordDir != "" && replayDir != "" {
+		logger.Fatal("choose either --record or --replay")
+		return nil
+	}
+	var reporters []string
+	for _, spec := range reporterSpecs {
+		name, path, err := parseReporterSpec(spec)
+		if err != nil {
+			logger.Fatal("reporter", "value", spec, "err", err)
+			return nil
+		}
+		reporters = append(reporters, name+"="+path)
+	}
+
+	// A .zip/.tar.gz target runs the collection inside the archive; --env
+	// names and relative file flags resolve there first, then on disk.
+	var archive fs.FS
+	var archiveRoot string
+	if isArchive(target) {
+		fsys, root, closeArchive, err := openArchive(target)
+		if err != nil {
+			logger.Fatal("open archive", "path", target, "err", err)
+			return nil
+		}
+		defer closeArchive()
+		archive, archiveRoot = fsys, root
+		if envPath != "" && !strings.Contains(envPath, string(os.PathSeparator)) && !strings.HasSuffix(envPath, ".bru") {
+			envPath = path.Join("environments", envPath+".bru")
+		}
+		envPath = archivePath(archive, archiveRoot, envPath)
+		if _, err := fs.Stat(archive, envPath); envPath != "" && !filepath.IsAbs(envPath) && err != nil {
+			logger.Fatal("env file not found", "archive", target, "path", envPath, "err", err)
+			return nil
+		}
+		csvPath = archivePath(archive, archiveRoot, csvPath)
+		jsonPath = archivePath(archive, archiveRoot, jsonPath)
+	}
 
-	// Bru-style env resolution: --env local resolves to environments/local.bru
-	if envPath != "" {
+	// Bru-style env resolution: --env local resolves to environments/local.bru;
+	// .http files also pick "local" from the nearest http-client.env.json.
+	var httpEnv string
+	if envPath != "" && archive == nil {
 		if !strings.Contains(envPath, string(os.PathSeparator)) && !strings.HasSuffix(envPath, ".bru") {
-			envPath = filepath.Join("environments", envPath+".bru")
+			name := envPath
+			envPath = filepath.Join("environments", name+".bru")
+			if hasHTTPClientEnv(target, name) {
+				httpEnv = name
+				if _, err := os.Stat(envPath); err != nil {
+					envPath = ""
+				}
+			}
 		}
-		if _, err := os.Stat(envPath); err != nil {
+		if _, err := os.Stat(envPath); envPath != "" && err != nil {
 			logger.Fatal("env file not found", "path", envPath, "err", err)
 			return nil
 		}
@@ -156,7 +224,36 @@ func runE(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	g, err := gruno.New(context.Background(), gruno.WithLogger(logger), gruno.WithHTTPClient(httpClient), gruno.WithTimeout(time.Duration(timeoutSec)*time.Second))
+	console := &consoleObserver{logger: logger}
+	gopts := []gruno.Option{gruno.WithLogger(logger), gruno.WithHTTPClient(httpClient), gruno.WithTimeout(time.Duration(timeoutSec) * time.Second), gruno.WithObserver(console)}
+	cassetteOpts := gruno.CassetteOptions{
+		IgnoreQueryOrder: ignoreQueryOrder,
+		MatchHeaders:     matchHeaders,
+		Passthrough:      replayPassthrough,
+		Logger:           logger,
+	}
+	if recordDir != "" {
+		opt, err := gruno.RecordCassettes(recordDir, cassetteOpts)
+		if err != nil {
+			logger.Fatal("record", "err", err)
+			return nil
+		}
+		gopts = append(gopts, opt)
+	}
+	if replayDir != "" {
+		opt, err := gruno.ReplayCassettes(replayDir, cassetteOpts)
+		if err != nil {
+			logger.Fatal("replay", "err", err)
+			return nil
+		}
+		gopts = append(gopts, opt)
+	}
+	var harRec *gruno.HARRecorder
+	if harPath != "" {
+		harRec = gruno.NewHARRecorder()
+		gopts = append(gopts, gruno.WithHARRecorder(harRec))
+	}
+	g, err := gruno.New(context.Background(), gopts...)
 	if err != nil {
 		logger.Fatal("init", "err", err)
 		return nil
@@ -189,13 +286,18 @@ func runE(cmd *cobra.Command, args []string) error {
 		ReporterJSON:           reportJSON,
 		ReporterJUnit:          reportJUnit,
 		ReporterHTML:           reportHTML,
+		Reporters:              reporters,
 		ReporterSkipAllHeaders: reportSkipAll,
 		ReporterSkipHeaders:    reportSkip,
+		ReportBodies:           reportBodies,
 		Recursive:              recursive,
 		RecursiveSet:           true,
 		PreHookCmd:             splitCmd(preHookCmd),
 		PostHookCmd:            splitCmd(postHookCmd),
+		PostmanCompat:          postmanCompat,
+		HTTPClientEnv:          httpEnv,
 	}
+	opts.ReportBodies = opts.ReportBodies || reportsBodies(opts)
 	if timeoutSec > 0 {
 		opts.Timeout = time.Duration(timeoutSec) * time.Second
 	}
@@ -206,8 +308,15 @@ func runE(cmd *cobra.Command, args []string) error {
 		logger.Fatal("stat", "path", target, "err", err)
 		return nil
 	}
-	if info.IsDir() || csvPath != "" || jsonPath != "" || iterCount > 1 || parallel {
-		summary, err := g.RunFolder(cmd.Context(), target, opts)
+	if archive != nil || info.IsDir() || parser.IsHTTPFile(target) || csvPath != "" || jsonPath != "" || iterCount > 1 || parallel {
+		var summary gruno.RunSummary
+		console.summary = true
+		if archive != nil {
+			summary, err = g.RunFS(cmd.Context(), archive, archiveRoot, opts)
+		} else {
+			summary, err = g.RunFolder(cmd.Context(), target, opts)
+		}
+		writeHAR(harPath, harRec, opts, logger)
 		if err != nil {
 			logger.Fatal("run", "err", err)
 			return nil
@@ -216,18 +325,17 @@ func runE(cmd *cobra.Command, args []string) error {
 			logger.Fatal("report", "err", err)
 			return nil
 		}
-		printSummary(summary, logger)
 		if summary.Failed > 0 {
 			logger.Fatal("cases failed", "count", summary.Failed)
 		}
 		return nil
 	}
 	res, err := g.RunFile(cmd.Context(), target, opts)
+	writeHAR(harPath, harRec, opts, logger)
 	if err != nil {
 		logger.Fatal("run", "err", err)
 		return nil
 	}
-	printSingle(res, logger)
 	summary := gruno.RunSummary{
 		Cases:        []gruno.CaseResult{res},
 		Total:        1,
@@ -356,11 +464,25 @@ func splitCmd(s string) []string {
 	return strings.Fields(s)
 }
 
-func printSummary(sum gruno.RunSummary, logger pslog.Base) {
-	for _, c := range sum.Cases {
-		printSingle(c, logger)
+// consoleObserver logs each case as it finishes and, for folder runs, the
+// summary once the run ends.
+type consoleObserver struct {
+	logger  pslog.Base
+	summary bool
+}
+
+func (o *consoleObserver) Observe(_ context.Context, ev gruno.Event) {
+	switch ev.Type {
+	case gruno.EventCaseEnd:
+		if ev.Err == nil {
+			printSingle(*ev.Result, o.logger)
+		}
+	case gruno.EventRunEnd:
+		if o.summary && ev.Err == nil {
+			sum := ev.Summary
+			o.logger.Info("summary", "total", sum.Total, "passed", sum.Passed, "failed", sum.Failed, "elapsed", sum.TotalElapsed.String())
+		}
 	}
-	logger.Info("summary", "total", sum.Total, "passed", sum.Passed, "failed", sum.Failed, "elapsed", sum.TotalElapsed.String())
 }
 
 func printSingle(res gruno.CaseResult, logger pslog.Base) {
@@ -403,12 +525,78 @@ func writeOutputs(opts gruno.RunOptions, sum gruno.RunSummary, logger pslog.Base
 			return err
 		}
 	}
+	for _, spec := range opts.Reporters {
+		name, path, _ := strings.Cut(spec, "=")
+		if err := gruno.WriteReport(name, path, sum); err != nil {
+			return fmt.Errorf("%s reporter: %w", name, err)
+		}
+	}
 	return nil
 }
 
+// bodyReporters are the report formats that show request/response bodies.
+var bodyReporters = []string{"html", "bruno"}
+
+// reportsBodies reports whether any configured output needs the request and
+// response bodies kept in each CaseResult.
+func reportsBodies(opts gruno.RunOptions) bool {
+	if opts.ReporterHTML != "" {
+		return true
+	}
+	if opts.OutputPath != "" && slices.Contains(bodyReporters, strings.ToLower(opts.OutputFormat)) {
+		return true
+	}
+	for _, spec := range opts.Reporters {
+		name, _, _ := strings.Cut(spec, "=")
+		if slices.Contains(bodyReporters, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReporterSpec splits a --reporter name=path value and checks the
+// reporter exists. markdown without a path writes to $GITHUB_STEP_SUMMARY.
+func parseReporterSpec(spec string) (name, path string, err error) {
+	name, path, _ = strings.Cut(spec, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := gruno.LookupReporter(name); !ok {
+		return "", "", fmt.Errorf("unknown reporter %q (available: %s)", name, strings.Join(gruno.ReporterNames(), ", "))
+	}
+	if path == "" && name == "markdown" {
+		path = os.Getenv("GITHUB_STEP_SUMMARY")
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("reporter %s needs a path (name=path)", name)
+	}
+	return name, path, nil
+}
+
+// writeHAR persists the recorded exchanges; it runs before any fatal exit so
+// failed runs still leave a HAR behind for post-mortems.
+func writeHAR(path string, rec *gruno.HARRecorder, opts gruno.RunOptions, logger pslog.Base) {
+	if path == "" || rec == nil {
+		return
+	}
+	if err := gruno.WriteHAR(path, rec, opts); err != nil {
+		logger.Error("har", "path", path, "err", err)
+	}
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
 	}
 	return 0
 }
+
+// hasHTTPClientEnv reports whether an http-client.env.json at or above target
+// defines the environment name.
+func hasHTTPClientEnv(target, name string) bool {
+	dir := target
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		dir = filepath.Dir(target)
+	}
+	_, err := parser.FindHTTPClientEnv(dir, name)
+	return err == nil
+}
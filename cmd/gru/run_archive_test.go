@@ -0,0 +1,118 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunCLIFromArchives(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			hits.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	files := map[string]string{
+		"environments/local.bru": "vars {\n  baseUrl: " + srv.URL + "\n}\n",
+		"ping.bru": `meta { name: Archived }
+
+get {
+  url: {{baseUrl}}/ping
+}
+
+tests {
+  test("ok", function(){ expect(res.status).to.equal(200); });
+}
+`,
+	}
+	tmp := t.TempDir()
+
+	// A tar.gz with the collection in a single top-level folder.
+	tgz := filepath.Join(tmp, "collection.tar.gz")
+	f, err := os.Create(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "collection/" + name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	f.Close()
+
+	// A zip with bruno.json at its root.
+	zipPath := filepath.Join(tmp, "collection.zip")
+	f, err = os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	files["bruno.json"] = `{"version":"1","name":"c","type":"collection"}`
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for i, archive := range []string{tgz, zipPath} {
+		cmd := newRunCmd()
+		cmd.SetArgs([]string{archive, "--env", "local"})
+		cmd.SetContext(context.Background())
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("run %s: %v", archive, err)
+		}
+		if got := hits.Load(); got != int32(i+1) {
+			t.Fatalf("%s: expected %d requests, got %d", archive, i+1, got)
+		}
+	}
+
+	// Another checkout's environments/local.bru in the working directory must
+	// not shadow the archive's own environment.
+	other := filepath.Join(tmp, "other")
+	if err := os.MkdirAll(filepath.Join(other, "environments"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(other, "environments", "local.bru"), []byte("vars {\n  baseUrl: http://127.0.0.1:1\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(other)
+	cmd := newRunCmd()
+	cmd.SetArgs([]string{zipPath, "--env", "local"})
+	cmd.SetContext(context.Background())
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run %s from another checkout: %v", zipPath, err)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Fatalf("expected the archive environment to be used, got %d requests", got)
+	}
+}
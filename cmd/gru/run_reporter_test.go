@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno"
+)
+
+func TestRunCLIWritesMultipleReporters(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	bru := "meta { name: Ping }\n\nget {\n  url: " + srv.URL + "/ping\n}\n\nassert {\n  res.status: eq 200\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "ping.bru"), []byte(bru), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	summary := filepath.Join(out, "step-summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	cmd := newRunCmd()
+	cmd.SetArgs([]string{dir,
+		"--reporter", "ctrf=" + filepath.Join(out, "ctrf.json"),
+		"--reporter", "tap=" + filepath.Join(out, "report.tap"),
+		"--reporter", "allure=" + filepath.Join(out, "allure-results"),
+		"--reporter", "markdown",
+	})
+	cmd.SetContext(context.Background())
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	tap, err := os.ReadFile(filepath.Join(out, "report.tap"))
+	if err != nil || !strings.Contains(string(tap), "ok 1 - Ping\n") {
+		t.Fatalf("tap: %q %v", tap, err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "ctrf.json")); err != nil {
+		t.Fatalf("ctrf: %v", err)
+	}
+	if results, _ := filepath.Glob(filepath.Join(out, "allure-results", "*-result.json")); len(results) != 1 {
+		t.Fatalf("allure results: %v", results)
+	}
+	md, err := os.ReadFile(summary)
+	if err != nil || !strings.Contains(string(md), "1 passed") {
+		t.Fatalf("markdown: %q %v", md, err)
+	}
+}
+
+func TestParseReporterSpec(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	if name, path, err := parseReporterSpec("TAP=out.tap"); err != nil || name != "tap" || path != "out.tap" {
+		t.Fatalf("tap: %s %s %v", name, path, err)
+	}
+	for _, spec := range []string{"nope=x", "json", "markdown"} {
+		if _, _, err := parseReporterSpec(spec); err == nil {
+			t.Fatalf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestReportsBodies(t *testing.T) {
+	cases := []struct {
+		opts gruno.RunOptions
+		want bool
+	}{
+		{gruno.RunOptions{}, false},
+		{gruno.RunOptions{OutputPath: "out.json", OutputFormat: "json", ReporterJSON: "r.json", Reporters: []string{"tap=r.tap"}}, false},
+		{gruno.RunOptions{OutputFormat: "html"}, false},
+		{gruno.RunOptions{OutputPath: "out.html", OutputFormat: "HTML"}, true},
+		{gruno.RunOptions{ReporterHTML: "r.html"}, true},
+		{gruno.RunOptions{Reporters: []string{"tap=r.tap", "bruno=r.json"}}, true},
+	}
+	for i, c := range cases {
+		if got := reportsBodies(c.opts); got != c.want {
+			t.Fatalf("case %d: got %v want %v", i, got, c.want)
+		}
+	}
+}
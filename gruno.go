@@ -2,7 +2,9 @@ package gruno
 
 import (
 	"context"
+	"net/http"
 
+	"pkt.systems/gruno/internal/export"
 	"pkt.systems/gruno/internal/runner"
 	"pkt.systems/version"
 )
@@ -20,8 +22,52 @@ type (
 	RunSummary = runner.RunSummary
 	// AssertionFailure mirrors a failed JS assertion.
 	AssertionFailure = runner.AssertionFailure
+	// TestResult is the outcome of one assert rule or JS test() of a case.
+	TestResult = runner.TestResult
 	// HookInfo carries request metadata provided to hooks.
 	HookInfo = runner.HookInfo
+	// PreparedRequest is a request built as the runner would send it.
+	PreparedRequest = runner.PreparedRequest
+	// Request is a case composed in Go; run it with Gruno.RunRequest or
+	// write it out with Request.WriteBru.
+	Request = runner.Request
+	// RequestBody is the body of a Request.
+	RequestBody = runner.RequestBody
+	// FormField is one form-urlencoded or multipart-form field.
+	FormField = runner.FormField
+	// Assert is one assert rule of a Request.
+	Assert = runner.Assert
+	// RunObserver receives run events as they happen (see WithObserver).
+	RunObserver = runner.RunObserver
+	// HTTPObserver is a RunObserver that also wants HTTP exchange events.
+	HTTPObserver = runner.HTTPObserver
+	// ObserverFunc adapts a function to a RunObserver.
+	ObserverFunc = runner.ObserverFunc
+	// Event is one step of a run delivered to a RunObserver.
+	Event = runner.Event
+	// EventType names the kind of an Event.
+	EventType = runner.EventType
+	// Exchange is one HTTP request/response pair of an EventHTTP.
+	Exchange = runner.Exchange
+)
+
+// TestResult kinds.
+const (
+	TestKindAssert = runner.TestKindAssert
+	TestKindTest   = runner.TestKindTest
+)
+
+// Event types, in the order a run emits them.
+const (
+	EventRunStart       = runner.EventRunStart
+	EventIterationStart = runner.EventIterationStart
+	EventCaseStart      = runner.EventCaseStart
+	EventHTTP           = runner.EventHTTP
+	EventConsole        = runner.EventConsole
+	EventTest           = runner.EventTest
+	EventCaseEnd        = runner.EventCaseEnd
+	EventIterationEnd   = runner.EventIterationEnd
+	EventRunEnd         = runner.EventRunEnd
 )
 
 // Option tweaks runner construction.
@@ -38,6 +84,14 @@ var (
 	WithPreRequestHook = runner.WithPreRequestHook
 	// WithPostRequestHook registers a Go hook invoked after each .bru request (logger provided).
 	WithPostRequestHook = runner.WithPostRequestHook
+	// WithHARRecorder records every request/response made by the runner (see NewHARRecorder).
+	WithHARRecorder = runner.WithHARRecorder
+	// WithTransport wraps the runner's HTTP transport (see RecordCassettes/ReplayCassettes).
+	WithTransport = runner.WithTransport
+	// WithHandler serves every request from an http.Handler in memory, without sockets.
+	WithHandler = runner.WithHandler
+	// WithObserver streams run, iteration, case, test, console and HTTP events to an observer.
+	WithObserver = runner.WithObserver
 )
 
 // New constructs a Gruno instance.
@@ -45,6 +99,24 @@ func New(ctx context.Context, opts ...Option) (Gruno, error) {
 	return runner.New(ctx, opts...)
 }
 
+// BuildRequests builds the requests of a .bru file or folder without sending
+// them (env, vars and vars:pre-request interpolated; scripts are not run).
+func BuildRequests(ctx context.Context, path string, opts RunOptions) ([]PreparedRequest, error) {
+	return runner.BuildRequests(ctx, path, opts)
+}
+
+// Snippet renders a request (typically from BuildRequests) as a runnable
+// client snippet in lang: go, python-requests, js-fetch, httpie or curl.
+// The request body is restored, so req stays usable.
+func Snippet(req *http.Request, lang string) (string, error) {
+	return export.Snippet(req, lang)
+}
+
+// SnippetLanguages lists the languages Snippet supports.
+func SnippetLanguages() []string {
+	return append([]string(nil), export.SnippetLanguages...)
+}
+
 // Version returns the current module version (best effort).
 func Version() string {
 	return moduleVersion(modulePath)
@@ -0,0 +1,121 @@
+// Package grunotest runs Bruno collections as Go subtests.
+//
+// Run maps every case of a folder to a t.Run subtest and every assert rule
+// and JS test() of the case to a subtest of its own, so go test reports
+// which check failed, with the file and line it is written on:
+//
+//	func TestAPI(t *testing.T) {
+//		grunotest.Run(t, "testdata/api", gruno.RunOptions{
+//			Vars: map[string]string{"baseUrl": "http://api.test"},
+//		}, gruno.WithHandler(newMux()))
+//	}
+//
+// Cases are named after their meta name (the file name when it has none),
+// so go test -run 'TestAPI/Login' runs only matching cases. Skipped cases
+// call t.Skip, and runs with more than one iteration (data files or
+// IterationCount) nest the cases under "iteration-N" subtests. With
+// RunOptions.Parallel the cases of an iteration are independent and run as
+// parallel subtests.
+package grunotest
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno"
+	"pkt.systems/gruno/internal/runner"
+	"pkt.systems/pslog"
+)
+
+// Run runs the .bru collection at folder with opts, one subtest per case.
+// Runner options (WithHandler, WithHTTPClient, ...) configure the runner;
+// its log is discarded unless one of them is WithLogger. Setup errors, such
+// as a file that does not parse, fail t immediately.
+func Run(t *testing.T, folder string, opts gruno.RunOptions, options ...gruno.Option) {
+	t.Helper()
+	ctx := t.Context()
+	options = append([]gruno.Option{gruno.WithLogger(pslog.New(io.Discard))}, options...)
+	g, err := gruno.New(ctx, options...)
+	if err != nil {
+		t.Fatalf("gruno: %v", err)
+	}
+	s, err := runner.NewSession(ctx, g, folder, opts)
+	if err != nil {
+		t.Fatalf("gruno: %v", err)
+	}
+
+	failed := false
+	runIteration := func(t *testing.T, iter int) {
+		for i, c := range s.Cases() {
+			t.Run(caseName(c), func(t *testing.T) {
+				if opts.Parallel {
+					t.Parallel()
+				} else if failed && opts.Bail {
+					t.Skip("skipped after a failure (Bail)")
+				}
+				res, err := s.RunCase(t.Context(), iter, i)
+				if err != nil {
+					t.Fatalf("%s: %v", c.FilePath, err)
+				}
+				if !report(t, c, res) && !opts.Parallel {
+					failed = true
+				}
+			})
+		}
+	}
+	if s.Iterations() == 1 {
+		runIteration(t, 0)
+		return
+	}
+	for iter := range s.Iterations() {
+		t.Run(fmt.Sprintf("iteration-%d", iter+1), func(t *testing.T) {
+			runIteration(t, iter)
+		})
+	}
+}
+
+// report maps a case result onto t and reports whether the case passed.
+func report(t *testing.T, c gruno.HookInfo, res gruno.CaseResult) bool {
+	t.Helper()
+	for _, line := range res.Console {
+		t.Log(line)
+	}
+	if res.Skipped {
+		t.Skip("skipped")
+	}
+	if res.ErrorText != "" {
+		t.Errorf("%s: %s", c.FilePath, res.ErrorText)
+	}
+	failedTests := 0
+	for _, tr := range res.Tests {
+		t.Run(tr.Name, func(t *testing.T) {
+			if !tr.Passed {
+				t.Errorf("%s: %s", position(c.FilePath, tr.Line), tr.Message)
+			}
+		})
+		if !tr.Passed {
+			failedTests++
+		}
+	}
+	if !res.Passed && res.ErrorText == "" && failedTests == 0 {
+		t.Errorf("%s: case failed (status %d)", c.FilePath, res.Status)
+	}
+	return res.Passed
+}
+
+func caseName(c gruno.HookInfo) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strings.TrimSuffix(filepath.Base(c.FilePath), filepath.Ext(c.FilePath))
+}
+
+func position(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return file
+}
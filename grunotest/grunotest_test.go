@@ -0,0 +1,145 @@
+package grunotest
+
+import (
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno"
+)
+
+func writeCollection(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"login.bru": `meta {
+  name: Login
+  seq: 1
+}
+
+post {
+  url: http://api.test/login
+}
+
+vars:post-response {
+  token: res.body.token
+}
+
+assert {
+  res.status: eq 200
+}
+
+tests {
+  test("returns a token", function() {
+    expect(res.body.token).to.equal("t1");
+  });
+}
+`,
+		"me.bru": `meta {
+  name: Me
+  seq: 2
+}
+
+get {
+  url: http://api.test/me?token={{token}}
+}
+
+tests {
+  test("knows the user", function() {
+    expect(res.body.user).to.equal(bru.getVar("user"));
+  });
+}
+`,
+		"skip.bru": `meta {
+  name: Disabled
+  seq: 3
+  enabled: false
+}
+
+get {
+  url: http://api.test/nope
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func mux() http.Handler {
+	m := http.NewServeMux()
+	m.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "t1"}`))
+	})
+	m.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "t1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user": "ada"}`))
+	})
+	return m
+}
+
+// TestHelperCollection is run by TestRunMapsCasesToSubtests in a child
+// process, so its failures do not fail this package.
+func TestHelperCollection(t *testing.T) {
+	dir := os.Getenv("GRUNOTEST_DIR")
+	if dir == "" {
+		t.Skip("helper")
+	}
+	Run(t, dir, gruno.RunOptions{
+		Vars:           map[string]string{"user": os.Getenv("GRUNOTEST_USER")},
+		IterationCount: 2,
+	}, gruno.WithHandler(mux()))
+}
+
+func TestRunMapsCasesToSubtests(t *testing.T) {
+	dir := writeCollection(t)
+	run := func(user, filter string) (string, error) {
+		cmd := exec.Command(os.Args[0], "-test.run", filter, "-test.v")
+		cmd.Env = append(os.Environ(), "GRUNOTEST_DIR="+dir, "GRUNOTEST_USER="+user)
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	out, err := run("ada", "TestHelperCollection")
+	if err != nil {
+		t.Fatalf("expected the collection to pass: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"--- PASS: TestHelperCollection/iteration-1/Login/res.status:_eq_200",
+		"--- PASS: TestHelperCollection/iteration-2/Login/returns_a_token",
+		"--- PASS: TestHelperCollection/iteration-2/Me/knows_the_user",
+		"--- SKIP: TestHelperCollection/iteration-1/Disabled",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	out, err = run("grace", "TestHelperCollection/iteration-1")
+	if err == nil {
+		t.Fatalf("expected a failure:\n%s", out)
+	}
+	if !strings.Contains(out, "--- FAIL: TestHelperCollection/iteration-1/Me/knows_the_user") ||
+		!strings.Contains(out, "me.bru:11: GoError: expected ada to equal grace") {
+		t.Fatalf("failure not mapped to the test and line:\n%s", out)
+	}
+	if strings.Contains(out, "iteration-2") {
+		t.Fatalf("-run did not filter iterations:\n%s", out)
+	}
+
+	out, err = run("grace", "TestHelperCollection/iteration-1/Login")
+	if err != nil || strings.Contains(out, "Me") {
+		t.Fatalf("-run did not filter cases: %v\n%s", err, out)
+	}
+}
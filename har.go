@@ -0,0 +1,40 @@
+package gruno
+
+import (
+	"slices"
+	"strings"
+
+	"pkt.systems/gruno/internal/har"
+)
+
+// HARRecorder captures HTTP exchanges for export as a HAR 1.2 archive.
+type HARRecorder = har.Recorder
+
+// NewHARRecorder returns an empty recorder for use with WithHARRecorder.
+func NewHARRecorder() *HARRecorder {
+	return har.NewRecorder("gru", Version())
+}
+
+// WriteHAR writes the exchanges captured by rec to path. Headers are redacted
+// with the same rules as the reporters: ReporterSkipAllHeaders and
+// ReporterSkipHeaders drop headers, and credentials are always masked.
+func WriteHAR(path string, rec *HARRecorder, opts RunOptions) error {
+	skip := map[string]struct{}{}
+	for _, h := range opts.ReporterSkipHeaders {
+		skip[strings.ToLower(strings.TrimSpace(h))] = struct{}{}
+	}
+	log := rec.Log().FilterHeaders(func(name, value string) (string, bool) {
+		lname := strings.ToLower(name)
+		if opts.ReporterSkipAllHeaders {
+			return "", false
+		}
+		if _, ok := skip[lname]; ok {
+			return "", false
+		}
+		if slices.Contains(sensitiveHeaders, lname) {
+			return maskedHeaderValue, true
+		}
+		return value, true
+	})
+	return har.WriteFile(path, log)
+}
@@ -0,0 +1,97 @@
+package gruno
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestWriteHARRecordsRunAndMasksHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Trace", "t1")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	bru := `meta { name: Secret }
+
+get {
+  url: ` + srv.URL + `/secret
+}
+
+headers {
+  Authorization: Bearer s3cr3t
+  X-Api-Key: k
+}
+`
+	bruPath := filepath.Join(tmp, "secret.bru")
+	if err := os.WriteFile(bruPath, []byte(bru), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := NewHARRecorder()
+	g, err := New(context.Background(), WithHARRecorder(rec), WithLogger(pslog.New(os.Stderr)))
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	opts := RunOptions{ReporterSkipHeaders: []string{"x-api-key"}}
+	if _, err := g.RunFile(context.Background(), bruPath, opts); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	out := filepath.Join(tmp, "run.har")
+	if err := WriteHAR(out, rec, opts); err != nil {
+		t.Fatalf("write har: %v", err)
+	}
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "s3cr3t") || strings.Contains(string(raw), "X-Api-Key") {
+		t.Fatalf("secrets leaked into HAR: %s", raw)
+	}
+	var doc struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				Comment string `json:"comment"`
+				Request struct {
+					Headers []struct{ Name, Value string } `json:"headers"`
+				} `json:"request"`
+				Response struct {
+					Status  int `json:"status"`
+					Content struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if doc.Log.Version != "1.2" || len(doc.Log.Entries) != 1 {
+		t.Fatalf("unexpected har %+v", doc)
+	}
+	e := doc.Log.Entries[0]
+	if e.Comment != bruPath || e.Response.Status != 200 || e.Response.Content.Text != `{"ok":true}` {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	masked := false
+	for _, h := range e.Request.Headers {
+		if strings.EqualFold(h.Name, "authorization") && h.Value == "********" {
+			masked = true
+		}
+	}
+	if !masked {
+		t.Fatalf("authorization not masked: %+v", e.Request.Headers)
+	}
+}
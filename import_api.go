@@ -2,6 +2,7 @@ package gruno
 
 import (
 	"context"
+	"io"
 
 	"pkt.systems/gruno/internal/importer"
 	"pkt.systems/pslog"
@@ -19,6 +20,17 @@ type ImportOptions struct {
 	AllowFileRefs   bool
 	DisableTests    bool
 	IncludePaths    []string
+	// IncludeHosts, IncludeMethods and IncludeContentTypes filter HAR imports.
+	IncludeHosts        []string
+	IncludeMethods      []string
+	IncludeContentTypes []string
+	// EnvironmentFiles are Postman environment exports converted alongside a
+	// Postman collection.
+	EnvironmentFiles []string
+	// Curl holds curl command lines for ImportCurl; Source is read when empty.
+	Curl string
+	// Stdout receives the rendered .bru text from ImportCurl when OutputDir is empty.
+	Stdout io.Writer
 	Logger          pslog.Logger
 }
 
@@ -54,3 +66,89 @@ func ImportWSDL(ctx context.Context, opts ImportOptions) error {
 		GenerateTestsSet: true,
 	})
 }
+
+// ImportHAR generates a Bruno collection from a HAR capture, one request per
+// unique entry, with hosts and credentials extracted into environments/local.bru.
+func ImportHAR(ctx context.Context, opts ImportOptions) error {
+	return importer.ImportHAR(ctx, importer.Options{
+		Source:              opts.Source,
+		OutputDir:           opts.OutputDir,
+		OutputFile:          opts.OutputFile,
+		CollectionName:      opts.CollectionName,
+		Insecure:            opts.Insecure,
+		Type:                "har",
+		GenerateTests:       !opts.DisableTests,
+		GenerateTestsSet:    true,
+		IncludeHosts:        opts.IncludeHosts,
+		IncludeMethods:      opts.IncludeMethods,
+		IncludeContentTypes: opts.IncludeContentTypes,
+		Logger:              opts.Logger,
+	})
+}
+
+// ImportPostman generates a Bruno collection from a Postman v2.0/v2.1 export,
+// translating common pm.* test idioms and converting environment files.
+func ImportPostman(ctx context.Context, opts ImportOptions) error {
+	return importer.ImportPostman(ctx, importer.Options{
+		Source:           opts.Source,
+		OutputDir:        opts.OutputDir,
+		OutputFile:       opts.OutputFile,
+		CollectionName:   opts.CollectionName,
+		Insecure:         opts.Insecure,
+		Type:             "postman",
+		GenerateTests:    !opts.DisableTests,
+		GenerateTestsSet: true,
+		EnvironmentFiles: opts.EnvironmentFiles,
+		Logger:           opts.Logger,
+	})
+}
+
+// ImportInsomnia generates a Bruno collection from an Insomnia v4 (JSON) or v5
+// (YAML) export, including base and sub-environments.
+func ImportInsomnia(ctx context.Context, opts ImportOptions) error {
+	return importer.ImportInsomnia(ctx, importer.Options{
+		Source:           opts.Source,
+		OutputDir:        opts.OutputDir,
+		OutputFile:       opts.OutputFile,
+		CollectionName:   opts.CollectionName,
+		Insecure:         opts.Insecure,
+		Type:             "insomnia",
+		GenerateTests:    !opts.DisableTests,
+		GenerateTestsSet: true,
+		Logger:           opts.Logger,
+	})
+}
+
+// ImportCurl converts curl command lines into .bru requests, added to the
+// collection in OutputDir or written to Stdout.
+func ImportCurl(ctx context.Context, opts ImportOptions) error {
+	return importer.ImportCurl(ctx, importer.Options{
+		Source:           opts.Source,
+		OutputDir:        opts.OutputDir,
+		OutputFile:       opts.OutputFile,
+		CollectionName:   opts.CollectionName,
+		Insecure:         opts.Insecure,
+		Type:             "curl",
+		GenerateTests:    !opts.DisableTests,
+		GenerateTestsSet: true,
+		Logger:           opts.Logger,
+		Curl:             opts.Curl,
+		Stdout:           opts.Stdout,
+	})
+}
+
+// ImportHTTP generates a Bruno collection from a VS Code REST Client /
+// JetBrains HTTP Client .http file and its http-client.env.json environments.
+func ImportHTTP(ctx context.Context, opts ImportOptions) error {
+	return importer.ImportHTTP(ctx, importer.Options{
+		Source:           opts.Source,
+		OutputDir:        opts.OutputDir,
+		OutputFile:       opts.OutputFile,
+		CollectionName:   opts.CollectionName,
+		Insecure:         opts.Insecure,
+		Type:             "http",
+		GenerateTests:    !opts.DisableTests,
+		GenerateTestsSet: true,
+		Logger:           opts.Logger,
+	})
+}
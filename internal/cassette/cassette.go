@@ -0,0 +1,180 @@
+// Package cassette records HTTP interactions to disk and replays them through
+// an http.RoundTripper so collections can run deterministically offline.
+//
+// A cassette directory holds one JSON file per request key (method, URL and
+// body hash, optionally selected headers). Multipart boundaries are normalized
+// before hashing. Repeated requests with the same key
+// are stored in order and replayed in order; the last interaction repeats once
+// the sequence is exhausted.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pkt.systems/pslog"
+)
+
+// ErrNoInteraction is returned during replay when no recorded interaction
+// matches a request and Passthrough is off.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+// Options tune how requests are matched and what is persisted.
+type Options struct {
+	// IgnoreQueryOrder sorts query parameters before matching.
+	IgnoreQueryOrder bool
+	// MatchHeaders lists request headers (case-insensitive) whose values must
+	// also match. Headers are ignored by default.
+	MatchHeaders []string
+	// Passthrough sends requests without a recorded interaction to the
+	// network during replay. By default they fail with ErrNoInteraction.
+	Passthrough bool
+	// RedactHeaders lists request headers whose values are masked when
+	// recording. Matching compares the masked values on both sides.
+	RedactHeaders []string
+	Logger        pslog.Base
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// Request is the recorded request.
+type Request struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// Response is the recorded response.
+type Response struct {
+	Status       int         `json:"status"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// key identifies a request for matching purposes.
+func (o Options) key(method, rawURL string, header http.Header, body []byte) string {
+	u := rawURL
+	if o.IgnoreQueryOrder {
+		if parsed, err := url.Parse(rawURL); err == nil && parsed.RawQuery != "" {
+			parts := strings.Split(parsed.RawQuery, "&")
+			sort.Strings(parts)
+			parsed.RawQuery = strings.Join(parts, "&")
+			u = parsed.String()
+		}
+	}
+	boundary := multipartBoundary(header)
+	if boundary != "" {
+		body = bytes.ReplaceAll(body, []byte(boundary), []byte(stableBoundary))
+	}
+	sum := sha256.Sum256(body)
+	header = redact(header, o.RedactHeaders)
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(method))
+	b.WriteString(" ")
+	b.WriteString(u)
+	b.WriteString(" ")
+	b.WriteString(hex.EncodeToString(sum[:]))
+	names := make([]string, 0, len(o.MatchHeaders))
+	for _, h := range o.MatchHeaders {
+		names = append(names, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+	}
+	sort.Strings(names)
+	for _, h := range names {
+		b.WriteString("\n")
+		b.WriteString(h)
+		b.WriteString(": ")
+		value := strings.Join(header.Values(h), ",")
+		if boundary != "" {
+			value = strings.ReplaceAll(value, boundary, stableBoundary)
+		}
+		b.WriteString(value)
+	}
+	return b.String()
+}
+
+// stableBoundary replaces per-request multipart boundaries so bodies built
+// with a random boundary hash identically across record and replay.
+const stableBoundary = "gruno-cassette-boundary"
+
+func multipartBoundary(header http.Header) string {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return ""
+	}
+	return params["boundary"]
+}
+
+// fileName derives a readable, stable cassette file name for a key.
+func fileName(method, rawURL, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	slug := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		slug = u.Host + u.Path
+	}
+	var b strings.Builder
+	for _, r := range slug {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	s := strings.Trim(b.String(), "_")
+	if len(s) > 60 {
+		s = s[:60]
+	}
+	return strings.ToLower(method) + "-" + s + "-" + hex.EncodeToString(sum[:6]) + ".json"
+}
+
+// readBody drains and restores req.Body so it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	return b, nil
+}
+
+func encodeBody(b []byte) (string, string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+func decodeBody(s, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return []byte(s), nil
+}
@@ -0,0 +1,229 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordThenReplayOffline(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Hit", string(rune('0'+n)))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(r.URL.RawQuery + "|" + string(body)))
+	}))
+	dir := t.TempDir()
+
+	rec, err := NewRecorder(dir, Options{RedactHeaders: []string{"Authorization"}})
+	if err != nil {
+		t.Fatalf("recorder: %v", err)
+	}
+	client := &http.Client{Transport: rec.Transport(nil)}
+	do := func(c *http.Client, method, url, body string) (*http.Response, string, error) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp, string(b), nil
+	}
+	for _, body := range []string{"one", "one", "two"} {
+		if _, _, err := do(client, http.MethodPost, srv.URL+"/items?b=2&a=1", body); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	srv.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("expected one cassette per key, got %v", files)
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), "secret") {
+			t.Fatalf("authorization leaked into %s", f)
+		}
+	}
+
+	rep, err := NewReplayer(dir, Options{IgnoreQueryOrder: true})
+	if err != nil {
+		t.Fatalf("replayer: %v", err)
+	}
+	client = &http.Client{Transport: rep.Transport(nil)}
+	wantHits := []string{"1", "2", "2"}
+	for i, want := range wantHits {
+		resp, body, err := do(client, http.MethodPost, srv.URL+"/items?a=1&b=2", "one")
+		if err != nil {
+			t.Fatalf("replay %d: %v", i, err)
+		}
+		if resp.StatusCode != http.StatusAccepted || body != "b=2&a=1|one" || resp.Header.Get("X-Hit") != want {
+			t.Fatalf("replay %d: status=%d body=%q hit=%q", i, resp.StatusCode, body, resp.Header.Get("X-Hit"))
+		}
+	}
+	if _, _, err := do(client, http.MethodPost, srv.URL+"/items?a=1&b=2", "three"); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func TestReplayMatchHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Accept")))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	opts := Options{MatchHeaders: []string{"accept"}}
+
+	rec, err := NewRecorder(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, accept := range []string{"application/json", "application/xml"} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Accept", accept)
+		resp, err := (&http.Client{Transport: rec.Transport(nil)}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	rep, err := NewReplayer(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept", "application/xml")
+	resp, err := (&http.Client{Transport: rep.Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	defer resp.Body.Close()
+	if b, _ := io.ReadAll(resp.Body); string(b) != "application/xml" {
+		t.Fatalf("header-matched replay returned %q", b)
+	}
+}
+
+func TestReplayMissingDir(t *testing.T) {
+	if _, err := NewReplayer(filepath.Join(t.TempDir(), "nope"), Options{}); err == nil {
+		t.Fatalf("expected error for missing cassette dir")
+	}
+}
+
+func TestReplayMultipartIgnoresBoundary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseMultipartForm(1 << 20)
+		_, _ = w.Write([]byte(r.FormValue("name")))
+	}))
+	dir := t.TempDir()
+	post := func(rt http.RoundTripper, name string) (string, error) {
+		var buf strings.Builder
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("name", name)
+		_ = mw.Close()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/upload", strings.NewReader(buf.String()))
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		resp, err := (&http.Client{Transport: rt}).Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), nil
+	}
+
+	opts := Options{MatchHeaders: []string{"Content-Type"}}
+	rec, err := NewRecorder(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := post(rec.Transport(nil), "gopher"); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	srv.Close()
+
+	rep, err := NewReplayer(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := post(rep.Transport(nil), "gopher")
+	if err != nil {
+		t.Fatalf("replay with a fresh boundary: %v", err)
+	}
+	if got != "gopher" {
+		t.Fatalf("multipart replay returned %q", got)
+	}
+	if _, err := post(rep.Transport(nil), "other"); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction for different parts, got %v", err)
+	}
+}
+
+func TestReplayMatchesRedactedHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	dir := t.TempDir()
+	opts := Options{MatchHeaders: []string{"Authorization"}, RedactHeaders: []string{"Authorization"}}
+	get := func(rt http.RoundTripper, auth string) error {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := (&http.Client{Transport: rt}).Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	rec, err := NewRecorder(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(rec.Transport(nil), "Bearer secret"); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	srv.Close()
+
+	rep, err := NewReplayer(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(rep.Transport(nil), "Bearer secret"); err != nil {
+		t.Fatalf("replay with redacted match header: %v", err)
+	}
+	if err := get(rep.Transport(nil), ""); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction without the header, got %v", err)
+	}
+}
+
+func TestReplayPassthrough(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("live"))
+	}))
+	defer srv.Close()
+	rep, err := NewReplayer(t.TempDir(), Options{Passthrough: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: rep.Transport(nil)}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("passthrough: %v", err)
+	}
+	defer resp.Body.Close()
+	if b, _ := io.ReadAll(resp.Body); string(b) != "live" {
+		t.Fatalf("passthrough returned %q", b)
+	}
+}
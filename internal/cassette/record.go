@@ -0,0 +1,109 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder writes every interaction passing through its transport to dir.
+// Cassettes touched during a session are rewritten from scratch, so
+// re-recording replaces stale responses.
+type Recorder struct {
+	dir  string
+	opts Options
+	mu   sync.Mutex
+	seen map[string]*cassetteFile
+}
+
+// NewRecorder prepares dir for recording.
+func NewRecorder(dir string, opts Options) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cassette dir: %w", err)
+	}
+	return &Recorder{dir: dir, opts: opts, seen: map[string]*cassetteFile{}}, nil
+}
+
+// Transport wraps next (http.DefaultTransport when nil).
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := readBody(req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		if err := r.save(req, body, resp, respBody); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+}
+
+func (r *Recorder) save(req *http.Request, body []byte, resp *http.Response, respBody []byte) error {
+	key := r.opts.key(req.Method, req.URL.String(), req.Header, body)
+	name := fileName(req.Method, req.URL.String(), key)
+
+	in := Interaction{RecordedAt: time.Now().UTC()}
+	in.Request = Request{Method: req.Method, URL: req.URL.String(), Headers: redact(req.Header, r.opts.RedactHeaders)}
+	in.Request.Body, in.Request.BodyEncoding = encodeBody(body)
+	in.Response = Response{Status: resp.StatusCode, Headers: resp.Header.Clone()}
+	in.Response.Body, in.Response.BodyEncoding = encodeBody(respBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cf, ok := r.seen[name]
+	if !ok {
+		cf = &cassetteFile{}
+		r.seen[name] = cf
+	}
+	cf.Interactions = append(cf.Interactions, in)
+	data, err := json.MarshalIndent(cf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	if r.opts.Logger != nil {
+		r.opts.Logger.Debug("cassette.record", "file", name, "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode)
+	}
+	return nil
+}
+
+func redact(h http.Header, names []string) http.Header {
+	out := h.Clone()
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if vals := out.Values(n); len(vals) > 0 {
+			masked := make([]string, len(vals))
+			for i := range masked {
+				masked[i] = "********"
+			}
+			out[http.CanonicalHeaderKey(n)] = masked
+		}
+	}
+	return out
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,112 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Replayer serves recorded interactions without touching the network.
+type Replayer struct {
+	opts   Options
+	mu     sync.Mutex
+	queues map[string][]Interaction
+	served map[string]int
+}
+
+// NewReplayer loads every cassette in dir and indexes it with opts.
+func NewReplayer(dir string, opts Options) (*Replayer, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("open cassette dir: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	p := &Replayer{opts: opts, queues: map[string][]Interaction{}, served: map[string]int{}}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read cassette: %w", err)
+		}
+		var cf cassetteFile
+		if err := json.Unmarshal(data, &cf); err != nil {
+			return nil, fmt.Errorf("parse cassette %s: %w", filepath.Base(f), err)
+		}
+		for _, in := range cf.Interactions {
+			body, err := decodeBody(in.Request.Body, in.Request.BodyEncoding)
+			if err != nil {
+				return nil, fmt.Errorf("parse cassette %s: %w", filepath.Base(f), err)
+			}
+			key := opts.key(in.Request.Method, in.Request.URL, in.Request.Headers, body)
+			p.queues[key] = append(p.queues[key], in)
+		}
+	}
+	return p, nil
+}
+
+// Transport returns a RoundTripper serving recorded responses. Unmatched
+// requests fail with ErrNoInteraction unless Passthrough is set, in which case
+// they are sent to next (http.DefaultTransport when nil).
+func (p *Replayer) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := readBody(req)
+		if err != nil {
+			return nil, err
+		}
+		in, ok := p.next(p.opts.key(req.Method, req.URL.String(), req.Header, body))
+		if !ok {
+			if !p.opts.Passthrough {
+				return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.Method, req.URL.String())
+			}
+			if p.opts.Logger != nil {
+				p.opts.Logger.Warn("cassette.replay.miss", "method", req.Method, "url", req.URL.String())
+			}
+			return next.RoundTrip(req)
+		}
+		respBody, err := decodeBody(in.Response.Body, in.Response.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		header := in.Response.Headers.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	})
+}
+
+func (p *Replayer) next(key string) (Interaction, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q := p.queues[key]
+	if len(q) == 0 {
+		return Interaction{}, false
+	}
+	i := p.served[key]
+	if i >= len(q) {
+		i = len(q) - 1
+	}
+	p.served[key] = i + 1
+	return q[i], true
+}
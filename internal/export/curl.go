@@ -0,0 +1,192 @@
+// Package export renders requests built by the runner in other tools' formats.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Curl renders req as a curl command line: method and URL first, then one
+// option per continuation line.
+// The request body is consumed and restored, so req stays usable.
+func Curl(req *http.Request) (string, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"curl"}
+	switch {
+	case req.Method == http.MethodHead:
+		args = append(args, "--head")
+	case (req.Method == http.MethodGet || req.Method == "") && len(body) == 0:
+	case req.Method == http.MethodPost && len(body) > 0:
+		// implied by --data/--form
+	default:
+		args = append(args, "-X "+req.Method)
+	}
+	args = []string{strings.Join(append(args, shellQuote(req.URL.String())), " ")}
+
+	ct := req.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(ct)
+	multipartBody := strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" && len(body) > 0
+
+	names := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if strings.EqualFold(k, "Content-Length") {
+			continue
+		}
+		for _, v := range req.Header[k] {
+			if multipartBody && strings.EqualFold(k, "Content-Type") {
+				// curl generates its own boundary for -F.
+				if mediaType == "multipart/form-data" {
+					continue
+				}
+				delete(params, "boundary")
+				v = mime.FormatMediaType(mediaType, params)
+			}
+			args = append(args, "-H "+shellQuote(k+": "+v))
+		}
+	}
+
+	if multipartBody {
+		forms, err := multipartArgs(body, params["boundary"])
+		if err != nil {
+			return "", err
+		}
+		args = append(args, forms...)
+	} else if len(body) > 0 {
+		args = append(args, "--data-raw "+shellQuote(string(body)))
+	}
+	return strings.Join(args, " \\\n  "), nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// multipartArgs turns a multipart body back into -F/--form-string options.
+// File parts reference the part's file name.
+func multipartArgs(body []byte, boundary string) ([]string, error) {
+	parts, err := readParts(body, boundary)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, p := range parts {
+		if p.FileName != "" {
+			v := p.Name + "=@" + p.FileName
+			if p.ContentType != "" {
+				v += ";type=" + p.ContentType
+			}
+			args = append(args, "-F "+shellQuote(v))
+			continue
+		}
+		if p.ContentType != "" {
+			args = append(args, "-F "+shellQuote(p.Name+"="+string(p.Value)+";type="+p.ContentType))
+			continue
+		}
+		args = append(args, "--form-string "+shellQuote(p.Name+"="+string(p.Value)))
+	}
+	return args, nil
+}
+
+// formPart is one decoded part of a multipart request body.
+type formPart struct {
+	Name        string
+	FileName    string
+	ContentType string
+	Value       []byte
+}
+
+func readParts(body []byte, boundary string) ([]formPart, error) {
+	r := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []formPart
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read multipart body: %w", err)
+		}
+		val, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("read multipart body: %w", err)
+		}
+		parts = append(parts, formPart{
+			Name:        part.FormName(),
+			FileName:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Value:       val,
+		})
+	}
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s for POSIX shells, falling back to bash $'...' quoting
+// for control characters and invalid UTF-8.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	printable := utf8.ValidString(s)
+	for _, r := range s {
+		if !printable {
+			break
+		}
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			printable = false
+		}
+	}
+	if printable {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
@@ -0,0 +1,148 @@
+package export
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCurlFromBuiltRequests(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version":"1","name":"c","type":"collection"}`)
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), "vars {\n  baseUrl: https://api.test\n  token: s3cr3t\n}\n")
+	writeFile(t, filepath.Join(dir, "create.bru"), `meta {
+  name: Create
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+params:query {
+  dry: {{dry}}
+}
+
+headers {
+  Authorization: Bearer {{token}}
+  Content-Type: application/json
+}
+
+body:json {
+  {"name": "it's {{who}}"}
+}
+`)
+	writeFile(t, filepath.Join(dir, "upload.bru"), `meta {
+  name: Upload
+  type: http
+  seq: 2
+}
+
+put {
+  url: {{baseUrl}}/files
+  body: multipart-form
+  auth: none
+}
+
+body:multipart-form {
+  note: line
+  file: @`+filepath.Join(dir, "data.txt")+`;type=text/plain
+}
+`)
+	writeFile(t, filepath.Join(dir, "data.txt"), "hello")
+	writeFile(t, filepath.Join(dir, "list.bru"), "meta {\n  name: List\n  type: http\n  seq: 3\n}\n\nget {\n  url: {{baseUrl}}/users\n  auth: none\n}\n")
+
+	reqs, err := runner.BuildRequests(context.Background(), dir, runner.RunOptions{
+		EnvPath: filepath.Join(dir, "environments", "local.bru"),
+		Vars:    map[string]string{"who": "ada", "dry": "1"},
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(reqs) != 3 || reqs[0].Name != "Create" || reqs[2].Name != "List" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+
+	create, err := Curl(reqs[0].Request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `curl 'https://api.test/users?dry=1' \
+  -H 'Authorization: Bearer s3cr3t' \
+  -H 'Content-Type: application/json' \
+  --data-raw '{"name":"it'\''s ada"}'`
+	if create != want {
+		t.Fatalf("create:\n%s\nwant:\n%s", create, want)
+	}
+	// The body is restored for reuse.
+	if body, _ := io.ReadAll(reqs[0].Request.Body); !strings.Contains(string(body), "ada") {
+		t.Fatalf("body not restored: %q", body)
+	}
+
+	upload, err := Curl(reqs[1].Request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"curl -X PUT https://api.test/files", "--form-string note=line", "-F 'file=@data.txt;type=text/plain'"} {
+		if !strings.Contains(upload, want) {
+			t.Fatalf("upload missing %q:\n%s", want, upload)
+		}
+	}
+	if strings.Contains(upload, "boundary") || strings.Contains(upload, "Content-Type") {
+		t.Fatalf("multipart content type should be left to curl:\n%s", upload)
+	}
+
+	list, err := Curl(reqs[2].Request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list != "curl https://api.test/users" {
+		t.Fatalf("list: %q", list)
+	}
+
+	// --data-raw alone would turn a GET with a body into a POST.
+	search, _ := http.NewRequest(http.MethodGet, "https://api.test/search", strings.NewReader(`{"q":"go"}`))
+	got, err := Curl(search)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "curl -X GET https://api.test/search \\\n  --data-raw '{\"q\":\"go\"}'"; got != want {
+		t.Fatalf("get with body:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		"":            "''",
+		"plain/a=b":   "plain/a=b",
+		"a b":         "'a b'",
+		"it's":        `'it'\''s'`,
+		"tab\there":   "'tab\there'",
+		"bell\x07":    `$'bell\x07'`,
+		"cr\r\n'x'\\": `$'cr\r\n\'x\'\\'`,
+	}
+	for in, want := range cases {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
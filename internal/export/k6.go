@@ -0,0 +1,762 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pkt.systems/gruno/internal/parser"
+)
+
+// K6Options tune k6 script generation.
+type K6Options struct {
+	// Vars are the variables a run would start with (environment plus
+	// overrides). They are inlined into the script unless EnvMapping is set.
+	Vars map[string]string
+	// EnvMapping reads every variable from k6's __ENV at run time, with the
+	// value from Vars as the default, instead of inlining it.
+	EnvMapping bool
+	// VUs, Iterations and Duration become the exported k6 options; Duration
+	// wins over Iterations. Zero values default to 1 VU and 1 iteration.
+	VUs        int
+	Iterations int
+	Duration   string
+	// Source is mentioned in the header comment.
+	Source string
+}
+
+var (
+	setVarLine = regexp.MustCompile(`^\s*bru\.set(?:Env)?Var\(\s*(["'])([^"']+)["']\s*,\s*(.+)\)\s*;?\s*$`)
+	testHeader = regexp.MustCompile(`\btest\(\s*(["'` + "`" + `])((?:\\.|[^\\])*?)["'` + "`" + `]\s*,\s*(?:async\s*)?(?:function\s*\w*\s*\(\s*\)|\(\s*\)\s*=>)\s*\{`)
+	resExpr    = regexp.MustCompile(`^\s*res\s*[.\[(]`)
+	identRe    = regexp.MustCompile(`(^|[^.\w$])([A-Za-z_$][\w$]*)`)
+	stringLit  = regexp.MustCompile(`"(?:\\.|[^"\\])*"|'(?:\\.|[^'\\])*'|` + "`(?:\\\\.|[^`\\\\])*`")
+	resMember  = regexp.MustCompile(`\bres\.(\w+)`)
+	simpleExpr = regexp.MustCompile(`^[\w$]+(?:\.[\w$]+|\(\)|\[(?:"[^"]*"|\d+)\])*$`)
+)
+
+// k6Globals are the identifiers a translated expression may use besides the
+// response (res) and the variable bag (vars).
+var k6Globals = map[string]bool{
+	"res": true, "vars": true, "JSON": true, "Math": true, "Date": true, "Number": true,
+	"String": true, "Boolean": true, "Array": true, "Object": true, "parseInt": true,
+	"parseFloat": true, "encodeURIComponent": true, "true": true, "false": true,
+	"null": true, "undefined": true, "typeof": true, "new": true, "crypto": true,
+}
+
+// K6 renders cases (in run order) as a k6 load-test module. Requests run in
+// one default function, each in its own group; vars:pre-request,
+// vars:post-response and bru.setVar calls become assignments on a shared
+// vars object so values chain between requests, and assert rules and test()
+// blocks become check() calls where they translate. Anything that does not
+// translate is kept as a comment.
+func K6(cases []parser.ParsedFile, opts K6Options) (string, error) {
+	g := &k6Gen{opts: opts, runtime: map[string]bool{}, envDefaults: map[string]string{}, seen: map[string]bool{}}
+	for _, c := range cases {
+		for k := range c.VarsPre {
+			g.runtime[k] = true
+		}
+		for k := range c.VarsPost {
+			g.runtime[k] = true
+		}
+		for _, src := range []string{c.Scripts.PreRequest, c.Scripts.PostResponse, c.TestsRaw} {
+			for _, line := range strings.Split(src, "\n") {
+				if m := setVarLine.FindStringSubmatch(line); m != nil {
+					g.runtime[m[2]] = true
+				}
+			}
+		}
+	}
+
+	var body bytes.Buffer
+	for _, c := range cases {
+		if c.Meta.Skip {
+			continue
+		}
+		if err := g.writeCase(&body, c); err != nil {
+			return "", fmt.Errorf("%s: %w", c.FilePath, err)
+		}
+	}
+
+	var out bytes.Buffer
+	src := opts.Source
+	if src == "" {
+		src = "a gru collection"
+	}
+	fmt.Fprintf(&out, "// Generated by gru export k6 from %s.\n// Run with: k6 run <this file> (override variables with -e name=value).\n", src)
+	out.WriteString("import http from \"k6/http\";\nimport { check, group } from \"k6\";\n\n")
+	out.WriteString("export const options = {\n")
+	vus := opts.VUs
+	if vus <= 0 {
+		vus = 1
+	}
+	fmt.Fprintf(&out, "  vus: %d,\n", vus)
+	if opts.Duration != "" {
+		fmt.Fprintf(&out, "  duration: %s,\n", jsString(opts.Duration))
+	} else {
+		iters := opts.Iterations
+		if iters <= 0 {
+			iters = 1
+		}
+		fmt.Fprintf(&out, "  iterations: %d,\n", iters)
+	}
+	out.WriteString("};\n\n")
+
+	if len(g.files) > 0 {
+		out.WriteString("// Multipart files are read once in the init context.\nconst files = [\n")
+		for _, f := range g.files {
+			fmt.Fprintf(&out, "  open(%s, \"b\"),\n", jsString(f))
+		}
+		out.WriteString("];\n\n")
+	}
+
+	if len(g.envOrder) == 0 {
+		out.WriteString("const env = {};\n\n")
+	} else {
+		out.WriteString("const env = {\n")
+		for _, name := range g.envOrder {
+			if def, ok := g.envDefaults[name]; ok {
+				fmt.Fprintf(&out, "  %s: __ENV[%s] || %s,\n", jsString(name), jsString(name), jsString(def))
+			} else {
+				fmt.Fprintf(&out, "  %s: __ENV[%s],\n", jsString(name), jsString(name))
+			}
+		}
+		out.WriteString("};\n\n")
+	}
+
+	out.WriteString("export default function () {\n  const vars = Object.assign({}, env);\n")
+	out.Write(body.Bytes())
+	out.WriteString("}\n")
+	return out.String(), nil
+}
+
+type k6Gen struct {
+	opts        K6Options
+	runtime     map[string]bool   // variables assigned while running
+	envDefaults map[string]string // defaults of variables read from __ENV
+	envOrder    []string
+	seen        map[string]bool
+	files       []string
+}
+
+func (g *k6Gen) writeCase(w *bytes.Buffer, c parser.ParsedFile) error {
+	name := c.Meta.Name
+	if name == "" {
+		name = c.FilePath
+	}
+	fmt.Fprintf(w, "\n  group(%s, function () {\n", jsString(name))
+	const ind = "    "
+
+	for _, k := range sortedKeys(c.VarsPre) {
+		fmt.Fprintf(w, "%svars[%s] = %s;\n", ind, jsString(k), g.tmpl(c.VarsPre[k]))
+	}
+	g.writeScript(w, ind, "script:pre-request", c.Scripts.PreRequest)
+
+	method := strings.ToUpper(c.Request.Verb)
+	if method == "" {
+		method = http.MethodGet
+	}
+	url := c.Request.URL
+	for _, k := range sortedKeys(c.Request.PathParams) {
+		url = strings.ReplaceAll(url, ":"+k, c.Request.PathParams[k])
+	}
+	if len(c.Request.Query) > 0 {
+		base, rawQuery, _ := strings.Cut(url, "?")
+		present := map[string]bool{}
+		var parts []string
+		for _, kv := range strings.Split(rawQuery, "&") {
+			if kv == "" {
+				continue
+			}
+			k, _, _ := strings.Cut(kv, "=")
+			present[k] = true
+			parts = append(parts, kv)
+		}
+		for _, k := range sortedKeys(c.Request.Query) {
+			if !present[k] {
+				parts = append(parts, k+"="+c.Request.Query[k])
+			}
+		}
+		url = base + "?" + strings.Join(parts, "&")
+	}
+
+	headers := map[string]string{}
+	for k, v := range c.Request.Headers {
+		headers[k] = v
+	}
+	bodyExpr, err := g.body(c.Request, headers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%sconst res = http.request(%s, %s, %s, {\n", ind, jsString(method), g.tmpl(url), bodyExpr)
+	if len(headers) > 0 {
+		fmt.Fprintf(w, "%s  headers: {\n", ind)
+		for _, k := range sortedKeys(headers) {
+			fmt.Fprintf(w, "%s    %s: %s,\n", ind, jsString(k), g.tmpl(headers[k]))
+		}
+		fmt.Fprintf(w, "%s  },\n", ind)
+	}
+	fmt.Fprintf(w, "%s  tags: { name: %s },\n", ind, jsString(name))
+	fmt.Fprintf(w, "%s});\n", ind)
+
+	for _, k := range sortedKeys(c.VarsPost) {
+		v := c.VarsPost[k]
+		if resExpr.MatchString(v) {
+			if expr, ok := k6Expr(v); ok {
+				fmt.Fprintf(w, "%svars[%s] = %s;\n", ind, jsString(k), expr)
+				continue
+			}
+			fmt.Fprintf(w, "%s// untranslated vars:post-response %s: %s\n", ind, k, v)
+			continue
+		}
+		fmt.Fprintf(w, "%svars[%s] = %s;\n", ind, jsString(k), g.tmpl(v))
+	}
+	g.writeScript(w, ind, "script:post-response", c.Scripts.PostResponse)
+
+	var checks, notes []string
+	for _, a := range c.Assert {
+		label := strings.TrimSpace(a.Left + ": " + strings.TrimSpace(a.Op+" "+a.Right))
+		if expr, ok := g.assertExpr(a); ok {
+			checks = append(checks, fmt.Sprintf("%s: (res) => %s", jsString(label), expr))
+		} else {
+			notes = append(notes, "untranslated assert "+label)
+		}
+	}
+	rest := c.TestsRaw
+	for {
+		loc := testHeader.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		end := matchClose(rest, loc[1]-1)
+		if end < 0 {
+			break
+		}
+		testName := rest[loc[4]:loc[5]]
+		if expr, ok := g.testExpr(rest[loc[1]:end]); ok {
+			checks = append(checks, fmt.Sprintf("%s: (res) => %s", jsString(testName), expr))
+		} else {
+			notes = append(notes, "untranslated test "+strconv.Quote(testName))
+		}
+		tail := strings.TrimLeft(rest[end+1:], " \t")
+		tail = strings.TrimPrefix(tail, ")")
+		tail = strings.TrimPrefix(strings.TrimLeft(tail, " \t"), ";")
+		rest = rest[:loc[0]] + tail
+	}
+	g.writeScript(w, ind, "tests", rest)
+	if len(checks) > 0 {
+		fmt.Fprintf(w, "%scheck(res, {\n", ind)
+		for _, ch := range checks {
+			fmt.Fprintf(w, "%s  %s,\n", ind, ch)
+		}
+		fmt.Fprintf(w, "%s});\n", ind)
+	}
+	for _, n := range notes {
+		fmt.Fprintf(w, "%s// %s\n", ind, n)
+	}
+	w.WriteString("  });\n")
+	return nil
+}
+
+// writeScript keeps the bru.setVar/setEnvVar calls of a script; other
+// statements are not translated and only counted in a comment.
+func (g *k6Gen) writeScript(w *bytes.Buffer, ind, label, src string) {
+	skipped := 0
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		if t == "" || strings.HasPrefix(t, "//") || t == "});" || t == "}" {
+			continue
+		}
+		if m := setVarLine.FindStringSubmatch(line); m != nil {
+			if expr, ok := k6Expr(m[3]); ok {
+				fmt.Fprintf(w, "%svars[%s] = %s;\n", ind, jsString(m[2]), expr)
+				continue
+			}
+		}
+		skipped++
+	}
+	if skipped > 0 {
+		fmt.Fprintf(w, "%s// %s: %d line(s) not translated\n", ind, label, skipped)
+	}
+}
+
+// body returns the k6 body argument, setting the default Content-Type the
+// runner would send.
+func (g *k6Gen) body(r parser.RequestBlock, headers map[string]string) (string, error) {
+	if !r.Body.Present {
+		return "null", nil
+	}
+	setCT := func(v string) {
+		for k := range headers {
+			if strings.EqualFold(k, "Content-Type") {
+				return
+			}
+		}
+		headers["Content-Type"] = v
+	}
+	switch r.Body.Type {
+	case "", "json":
+		setCT("application/json")
+		return g.tmpl(dedent(r.Body.Raw)), nil
+	case "graphql":
+		setCT("application/json")
+		var b strings.Builder
+		fmt.Fprintf(&b, "JSON.stringify({ query: %s", g.tmpl(dedent(r.Body.Raw)))
+		if len(r.GraphqlVars) > 0 {
+			b.WriteString(", variables: {")
+			for i, k := range sortedKeys(r.GraphqlVars) {
+				if i > 0 {
+					b.WriteString(",")
+				}
+				fmt.Fprintf(&b, " %s: %s", jsString(k), g.tmpl(r.GraphqlVars[k]))
+			}
+			b.WriteString(" }")
+		}
+		b.WriteString(" })")
+		return b.String(), nil
+	case "form-urlencoded", "multipart-form":
+		for k := range headers {
+			if strings.EqualFold(k, "Content-Type") && r.Body.Type == "multipart-form" {
+				// k6 sets the multipart boundary itself.
+				delete(headers, k)
+			}
+		}
+		var b strings.Builder
+		b.WriteString("{")
+		for i, k := range sortedKeys(r.Body.Fields) {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			v := r.Body.Fields[k]
+			if r.Body.Type == "multipart-form" && strings.HasPrefix(v, "@") {
+				path, ctype, _ := strings.Cut(strings.TrimPrefix(v, "@"), ";type=")
+				path = g.staticExpand(path)
+				g.files = append(g.files, path)
+				fileName := path[strings.LastIndexAny(path, `/\`)+1:]
+				fmt.Fprintf(&b, " %s: http.file(files[%d], %s", jsString(k), len(g.files)-1, jsString(fileName))
+				if ctype != "" {
+					fmt.Fprintf(&b, ", %s", jsString(ctype))
+				}
+				b.WriteString(")")
+				continue
+			}
+			fmt.Fprintf(&b, " %s: %s", jsString(k), g.tmpl(v))
+		}
+		b.WriteString(" }")
+		return b.String(), nil
+	case "xml":
+		setCT("application/xml")
+	case "text":
+		setCT("text/plain")
+	}
+	return g.tmpl(dedent(r.Body.Raw)), nil
+}
+
+// dedent trims surrounding blank lines and the indentation common to all
+// lines of a .bru body block.
+func dedent(s string) string {
+	lines := strings.Split(strings.Trim(s, "\r\n"), "\n")
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " \t\r\n")
+}
+
+// tmpl renders s as a JS template literal, resolving {{placeholders}}.
+func (g *k6Gen) tmpl(s string) string {
+	return "`" + g.tmplInner(s, 0) + "`"
+}
+
+func (g *k6Gen) tmplInner(s string, depth int) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range parser.VarPattern.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(escapeTemplate(s[last:m[0]]))
+		b.WriteString(g.ref(strings.TrimSpace(s[m[2]:m[3]]), s[m[0]:m[1]], depth))
+		last = m[1]
+	}
+	b.WriteString(escapeTemplate(s[last:]))
+	return b.String()
+}
+
+// ref resolves one placeholder: inlined when static, otherwise read from vars
+// (and, unless the collection sets it, from __ENV).
+func (g *k6Gen) ref(name, raw string, depth int) string {
+	if strings.HasPrefix(name, "$") {
+		fields := strings.Fields(name)
+		switch fields[0] {
+		case "$guid", "$uuid", "$randomUUID", "$random.uuid":
+			return "${crypto.randomUUID()}"
+		case "$timestamp":
+			return "${Math.floor(Date.now() / 1000)}"
+		case "$isoTimestamp":
+			return "${new Date().toISOString()}"
+		case "$randomInt", "$random.integer":
+			return "${Math.floor(Math.random() * 1000)}"
+		case "$processEnv":
+			if len(fields) == 2 {
+				return "${__ENV[" + jsString(fields[1]) + "]}"
+			}
+		}
+		return escapeTemplate(raw)
+	}
+	val, known := g.opts.Vars[name]
+	if known && !g.runtime[name] && !g.opts.EnvMapping && depth < maxExpandDepth {
+		return g.tmplInner(val, depth+1)
+	}
+	if !g.seen[name] {
+		g.seen[name] = true
+		if !g.runtime[name] || known {
+			g.envOrder = append(g.envOrder, name)
+			if known {
+				g.envDefaults[name] = g.staticExpand(val)
+			}
+		}
+	}
+	return "${vars[" + jsString(name) + "]}"
+}
+
+const maxExpandDepth = 8
+
+// staticExpand resolves placeholders from opts.Vars only (used where a value
+// is needed before the run, like file paths and env defaults).
+func (g *k6Gen) staticExpand(s string) string {
+	for i := 0; i < maxExpandDepth && parser.VarPattern.MatchString(s); i++ {
+		next := parser.VarPattern.ReplaceAllStringFunc(s, func(m string) string {
+			if v, ok := g.opts.Vars[strings.TrimSpace(m[2:len(m)-2])]; ok {
+				return v
+			}
+			return m
+		})
+		if next == s {
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// assertExpr translates an assert rule into a boolean JS expression over res.
+func (g *k6Gen) assertExpr(a parser.AssertRule) (string, bool) {
+	left, ok := k6Expr(a.Left)
+	if !ok {
+		return "", false
+	}
+	right := g.assertValue(strings.TrimSpace(a.Right))
+	switch a.Op {
+	case "eq":
+		return left + " === " + right, true
+	case "neq":
+		return left + " !== " + right, true
+	case "gt":
+		return left + " > " + right, true
+	case "gte":
+		return left + " >= " + right, true
+	case "lt":
+		return left + " < " + right, true
+	case "lte":
+		return left + " <= " + right, true
+	case "contains":
+		return "String(" + left + ").includes(" + right + ")", true
+	case "notContains":
+		return "!String(" + left + ").includes(" + right + ")", true
+	case "startsWith", "endsWith":
+		return "String(" + left + ")." + a.Op + "(" + right + ")", true
+	case "matches":
+		return "new RegExp(" + right + ").test(" + left + ")", true
+	case "in":
+		return "[" + right + "].includes(" + left + ")", true
+	case "isDefined":
+		return left + " !== undefined", true
+	case "isUndefined":
+		return left + " === undefined", true
+	case "isNull":
+		return left + " === null", true
+	case "isTruthy":
+		return "!!" + left, true
+	case "isFalsy":
+		return "!" + left, true
+	case "isEmpty":
+		return "Object.keys(" + left + ").length === 0", true
+	case "isArray":
+		return "Array.isArray(" + left + ")", true
+	case "isString", "isNumber", "isBoolean":
+		return "typeof " + left + " === " + jsString(strings.ToLower(strings.TrimPrefix(a.Op, "is"))), true
+	case "isJson":
+		return "typeof " + left + " === \"object\"", true
+	}
+	return "", false
+}
+
+// assertValue mirrors the runner's literal handling: booleans, null and
+// numbers stay literal, quoted strings keep their quotes, anything else is a
+// string.
+func (g *k6Gen) assertValue(v string) string {
+	switch {
+	case v == "true" || v == "false" || v == "null":
+		return v
+	case len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0]:
+		return g.tmpl(v[1 : len(v)-1])
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	return g.tmpl(v)
+}
+
+// testExpr translates the body of a test() block made only of expect()
+// statements into one boolean expression.
+func (g *k6Gen) testExpr(body string) (string, bool) {
+	var parts []string
+	for _, stmt := range splitTop(body, ";\n") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || strings.HasPrefix(stmt, "//") {
+			continue
+		}
+		expr, ok := expectExpr(stmt)
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, expr)
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, " && "), true
+}
+
+// expectExpr translates expect(x).to.<assertion> chains.
+func expectExpr(stmt string) (string, bool) {
+	if !strings.HasPrefix(stmt, "expect(") {
+		return "", false
+	}
+	end := matchClose(stmt, len("expect"))
+	if end < 0 {
+		return "", false
+	}
+	subject, ok := k6Expr(stmt[len("expect("):end])
+	if !ok {
+		return "", false
+	}
+	if !simpleExpr.MatchString(subject) {
+		subject = "(" + subject + ")"
+	}
+	chain := splitTop(strings.TrimPrefix(stmt[end+1:], "."), ".")
+	neg, deep := false, false
+	for i, link := range chain {
+		word, args, hasArgs := strings.Cut(link, "(")
+		if hasArgs {
+			args = strings.TrimSuffix(args, ")")
+		}
+		switch word {
+		case "to", "be", "been", "is", "that", "which", "and", "has", "have", "with", "at", "of", "same", "does", "still":
+			continue
+		case "not":
+			neg = !neg
+			continue
+		case "deep":
+			deep = true
+			continue
+		}
+		if i != len(chain)-1 {
+			return "", false
+		}
+		var arg string
+		if hasArgs && strings.TrimSpace(args) != "" {
+			if strings.HasPrefix(strings.TrimSpace(args), "/") && word == "match" {
+				arg = strings.TrimSpace(args)
+			} else if arg, ok = k6Expr(args); !ok {
+				return "", false
+			}
+		}
+		var expr string
+		switch word {
+		case "equal", "equals", "eq":
+			if deep {
+				expr = "JSON.stringify(" + subject + ") === JSON.stringify(" + arg + ")"
+			} else {
+				expr = subject + " === " + arg
+			}
+		case "eql", "eqls":
+			expr = "JSON.stringify(" + subject + ") === JSON.stringify(" + arg + ")"
+		case "above", "gt", "greaterThan":
+			expr = subject + " > " + arg
+		case "least", "gte":
+			expr = subject + " >= " + arg
+		case "below", "lt", "lessThan":
+			expr = subject + " < " + arg
+		case "most", "lte":
+			expr = subject + " <= " + arg
+		case "a", "an":
+			switch strings.Trim(arg, `"'`) {
+			case "array":
+				expr = "Array.isArray(" + subject + ")"
+			case "null":
+				expr = subject + " === null"
+			default:
+				expr = "typeof " + subject + " === " + arg
+			}
+		case "property":
+			key, val, hasVal := strings.Cut(arg, ",")
+			expr = subject + " != null && " + subject + "[" + key + "] !== undefined"
+			if hasVal {
+				expr += " && " + subject + "[" + key + "] === " + strings.TrimSpace(val)
+			}
+		case "include", "includes", "contain", "contains":
+			expr = subject + ".includes(" + arg + ")"
+		case "lengthOf", "length":
+			expr = subject + ".length === " + arg
+		case "match", "matches":
+			expr = arg + ".test(" + subject + ")"
+		case "oneOf":
+			expr = arg + ".includes(" + subject + ")"
+		case "true", "false", "null", "undefined":
+			expr = subject + " === " + word
+		case "ok":
+			expr = "!!" + subject
+		case "exist":
+			expr = subject + " != null"
+		case "empty":
+			expr = "Object.keys(" + subject + ").length === 0"
+		default:
+			return "", false
+		}
+		if neg {
+			expr = "!(" + expr + ")"
+		}
+		return expr, true
+	}
+	return "", false
+}
+
+// k6Expr rewrites a Bruno expression for k6, where the response is res and
+// variables live in vars. It fails when the expression references anything
+// the generated script does not define.
+func k6Expr(expr string) (string, bool) {
+	e := strings.TrimSpace(expr)
+	for _, r := range []struct {
+		re  *regexp.Regexp
+		rep func([]string) string
+	}{
+		{regexp.MustCompile(`\bres\.getStatus\(\)`), func([]string) string { return "res.status" }},
+		{regexp.MustCompile(`\bres\.(?:getBody\(\)|body\b)`), func([]string) string { return "res.json()" }},
+		{regexp.MustCompile(`\bres\.getHeaders\(\)`), func([]string) string { return "res.headers" }},
+		{regexp.MustCompile(`\bres\.(?:getResponseTime\(\)|responseTime)`), func([]string) string { return "res.timings.duration" }},
+		{regexp.MustCompile(`\bres\.getHeader\(\s*["']([^"']+)["']\s*\)`), func(m []string) string { return "res.headers[" + jsString(http.CanonicalHeaderKey(m[1])) + "]" }},
+		{regexp.MustCompile(`\bres\.headers\[\s*["']([^"']+)["']\s*\]`), func(m []string) string { return "res.headers[" + jsString(http.CanonicalHeaderKey(m[1])) + "]" }},
+		{regexp.MustCompile(`\bres\.headers\.([\w-]+)`), func(m []string) string { return "res.headers[" + jsString(http.CanonicalHeaderKey(m[1])) + "]" }},
+		{regexp.MustCompile(`\bbru\.(?:getVar|getEnvVar|getRequestVar)\(\s*["']([^"']+)["']\s*\)`), func(m []string) string { return "vars[" + jsString(m[1]) + "]" }},
+		{regexp.MustCompile(`\bbru\.interpolate\(\s*["']\{\{([^}]+)\}\}["']\s*\)`), func(m []string) string { return "vars[" + jsString(strings.TrimSpace(m[1])) + "]" }},
+	} {
+		e = r.re.ReplaceAllStringFunc(e, func(s string) string { return r.rep(r.re.FindStringSubmatch(s)) })
+	}
+	code := stringLit.ReplaceAllString(e, `""`)
+	for _, m := range identRe.FindAllStringSubmatch(code, -1) {
+		if !k6Globals[m[2]] {
+			return "", false
+		}
+	}
+	for _, m := range resMember.FindAllStringSubmatch(code, -1) {
+		switch m[1] {
+		case "status", "json", "headers", "timings":
+		default:
+			return "", false
+		}
+	}
+	if strings.Contains(code, "res(") || strings.Contains(code, "res [") {
+		return "", false
+	}
+	return e, true
+}
+
+// matchClose returns the index of the bracket closing the one at open,
+// skipping string literals, or -1.
+func matchClose(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTop splits s at any of seps outside brackets and string literals.
+func splitTop(s, seps string) []string {
+	var out []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && strings.IndexByte(seps, c) >= 0:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
+
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "`", "\\`")
+	return strings.ReplaceAll(s, "${", "\\${")
+}
+
+// jsString quotes s as a JS string literal.
+func jsString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"pkt.systems/gruno/internal/runner"
+)
+
+func writeK6Collection(t *testing.T, dir string) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version":"1","name":"c","type":"collection"}`)
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), "vars {\n  baseUrl: https://api.test\n  user: ada\n}\n")
+	writeFile(t, filepath.Join(dir, "login.bru"), `meta {
+  name: Login
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/login
+  body: json
+  auth: none
+}
+
+body:json {
+  {"user": "{{user}}"}
+}
+
+vars:post-response {
+  token: res.body.token
+}
+
+script:post-response {
+  bru.setVar("session", res.getHeader("x-session"));
+  console.log("logged in");
+}
+
+assert {
+  res.status: eq 200
+}
+
+tests {
+  test("has token", function() {
+    expect(res.body.token).to.be.a("string");
+    expect(res.body).to.have.property("token");
+  });
+  test("custom", function() {
+    const t = res.body.token;
+    expect(t).to.exist;
+  });
+}
+`)
+	writeFile(t, filepath.Join(dir, "me.bru"), `meta {
+  name: Me
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/users/:id?verbose=1
+  auth: none
+}
+
+params:path {
+  id: {{user}}
+}
+
+headers {
+  Authorization: Bearer {{token}}
+  X-Session: {{session}}
+}
+
+tests {
+  test("ok", () => {
+    expect(res.status).to.equal(200);
+    expect(res.getBody().name).to.not.equal("bob");
+  });
+}
+`)
+}
+
+func TestK6ScriptChainsAndChecks(t *testing.T) {
+	dir := t.TempDir()
+	writeK6Collection(t, dir)
+	cases, vars, err := runner.LoadCases(context.Background(), dir, runner.RunOptions{EnvPath: filepath.Join(dir, "environments", "local.bru")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := K6(cases, K6Options{Vars: vars, VUs: 5, Duration: "30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`vus: 5,`,
+		`duration: "30s",`,
+		"http.request(\"POST\", `https://api.test/login`, `{\"user\": \"ada\"}`",
+		`vars["token"] = res.json().token;`,
+		`vars["session"] = res.headers["X-Session"];`,
+		`// script:post-response: 1 line(s) not translated`,
+		`"res.status: eq 200": (res) => res.status === 200,`,
+		`"has token": (res) => typeof res.json().token === "string" && res.json() != null && res.json()["token"] !== undefined,`,
+		`// untranslated test "custom"`,
+		"`https://api.test/users/ada?verbose=1`",
+		"\"Authorization\": `Bearer ${vars[\"token\"]}`",
+		`"ok": (res) => res.status === 200 && !(res.json().name === "bob"),`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("script missing %s:\n%s", want, script)
+		}
+	}
+
+	calls := runK6Script(t, script, map[string]string{})
+	if len(calls) != 2 {
+		t.Fatalf("requests: %v", calls)
+	}
+	if calls[1]["Authorization"] != "Bearer tok-1" || calls[1]["X-Session"] != "sess-1" {
+		t.Fatalf("chained values not sent: %v", calls[1])
+	}
+}
+
+func TestK6EnvMapping(t *testing.T) {
+	dir := t.TempDir()
+	writeK6Collection(t, dir)
+	cases, vars, err := runner.LoadCases(context.Background(), dir, runner.RunOptions{EnvPath: filepath.Join(dir, "environments", "local.bru")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := K6(cases, K6Options{Vars: vars, EnvMapping: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script, `"baseUrl": __ENV["baseUrl"] || "https://api.test",`) || strings.Contains(script, "https://api.test/login") {
+		t.Fatalf("variables not mapped to __ENV:\n%s", script)
+	}
+	calls := runK6Script(t, script, map[string]string{"baseUrl": "http://staging"})
+	if calls[0]["url"] != "http://staging/login" {
+		t.Fatalf("__ENV override ignored: %v", calls[0])
+	}
+}
+
+// runK6Script evaluates a generated script in goja with stubbed k6 modules
+// and returns the url and headers of every request made.
+func runK6Script(t *testing.T, script string, env map[string]string) []map[string]string {
+	t.Helper()
+	src := regexp.MustCompile(`(?m)^import .*$`).ReplaceAllString(script, "")
+	src = strings.Replace(src, "export const options", "const options", 1)
+	src = strings.Replace(src, "export default function ()", "function main()", 1)
+
+	vm := goja.New()
+	var calls []map[string]string
+	envObj := vm.NewObject()
+	for k, v := range env {
+		_ = envObj.Set(k, v)
+	}
+	_ = vm.Set("__ENV", envObj)
+	_ = vm.Set("__request", func(method, url string, headers map[string]any) {
+		call := map[string]string{"method": method, "url": url}
+		for k, v := range headers {
+			call[k] = v.(string)
+		}
+		calls = append(calls, call)
+	})
+	var failed []string
+	_ = vm.Set("__fail", func(name string) { failed = append(failed, name) })
+	stubs := `
+let __n = 0;
+const http = { request(method, url, body, params) {
+  __request(method, url, (params && params.headers) || {});
+  const n = ++__n;
+  return { status: 200, headers: { "X-Session": "sess-" + n }, json() { return { token: "tok-" + n, name: "ada" }; } };
+} };
+function check(res, checks) { for (const k in checks) { if (!checks[k](res)) __fail(k); } }
+function group(name, fn) { fn(); }
+`
+	if _, err := vm.RunString(stubs + src + "\nmain();"); err != nil {
+		t.Fatalf("run script: %v\n%s", err, src)
+	}
+	if len(failed) > 0 {
+		t.Fatalf("checks failed: %v", failed)
+	}
+	return calls
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"pkt.systems/gruno/internal/runner"
+	"pkt.systems/pslog"
+)
+
+// maxObservedBody caps how much of a response body is kept for inference.
+const maxObservedBody = 4 << 20
+
+// ObserveResponses runs the collection at path and records the response of
+// every case, keyed by CaseKey. Iterations (opts.IterationCount or a data
+// file) add more observations per case; OpenAPI merges their schemas. Cases
+// run sequentially so responses can be attributed to them.
+func ObserveResponses(ctx context.Context, path string, opts runner.RunOptions, gopts ...runner.Option) (Observations, runner.RunSummary, error) {
+	obs := &observer{out: Observations{}}
+	opts.Parallel = false
+	gopts = append(gopts,
+		runner.WithPreRequestHook(obs.before),
+		runner.WithTransport(obs.wrap),
+	)
+	g, err := runner.New(ctx, gopts...)
+	if err != nil {
+		return nil, runner.RunSummary{}, err
+	}
+	sum, err := g.RunFolder(ctx, path, opts)
+	if err != nil {
+		return nil, sum, err
+	}
+	return obs.out, sum, nil
+}
+
+type observer struct {
+	mu      sync.Mutex
+	out     Observations
+	key     string
+	method  string
+	urlPath string
+}
+
+// before marks the case whose request is about to be sent. Requests made by
+// scripts (bru.sendRequest) do not match and are not recorded.
+func (o *observer) before(_ context.Context, info runner.HookInfo, req *http.Request, _ pslog.Base) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.key = CaseKey(info.FilePath, info.Name)
+	o.method = req.Method
+	o.urlPath = req.URL.Path
+	return nil
+}
+
+func (o *observer) wrap(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		o.mu.Lock()
+		key := o.key
+		match := key != "" && req.Method == o.method && req.URL.Path == o.urlPath
+		if match {
+			o.key = ""
+		}
+		o.mu.Unlock()
+		if !match {
+			return resp, nil
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxObservedBody+1))
+		if err != nil {
+			return resp, err
+		}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		if len(body) > maxObservedBody {
+			body = nil
+		}
+		o.mu.Lock()
+		o.out[key] = append(o.out[key], Observation{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        body,
+		})
+		o.mu.Unlock()
+		return resp, nil
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,650 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"pkt.systems/gruno/internal/parser"
+)
+
+// OpenAPIOptions tune OpenAPI generation.
+type OpenAPIOptions struct {
+	Title   string
+	Version string
+	// Root is the collection folder; sub-folders become tags for cases
+	// without meta tags.
+	Root string
+	// Vars resolve server variables (e.g. {{baseUrl}}) to defaults.
+	Vars map[string]string
+	// Responses are observed responses (see ObserveResponses); without them
+	// operations get asserted status codes or a default response.
+	Responses Observations
+}
+
+// Observations maps a case (see CaseKey) to the responses seen for it.
+type Observations map[string][]Observation
+
+// Observation is one response received for a case.
+type Observation struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// CaseKey identifies a case across parsing and running: .http files hold
+// several cases per path, so the name is part of the key.
+func CaseKey(filePath, name string) string {
+	return filePath + "#" + name
+}
+
+var (
+	placeholder     = regexp.MustCompile(`\{\{\s*([^}]+?)\s*\}\}`)
+	jsonPlaceholder = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|\{\{[^}]+\}\}`)
+	statusAssert    = regexp.MustCompile(`^eq\s+(\d{3})$`)
+)
+
+// OpenAPI builds an OpenAPI 3 document from parsed cases. Paths come from the
+// request URLs (":id" and "{{id}}" segments become path parameters), query,
+// header and body parameters and examples from the request blocks, and docs
+// blocks become descriptions. Cases sharing a method and path are merged.
+func OpenAPI(cases []parser.ParsedFile, opts OpenAPIOptions) (*openapi3.T, error) {
+	title := opts.Title
+	if title == "" {
+		title = "API"
+		if opts.Root != "" {
+			if abs, err := filepath.Abs(opts.Root); err == nil {
+				title = filepath.Base(abs)
+			}
+		}
+	}
+	version := opts.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: title, Version: version},
+		Paths:   openapi3.NewPaths(),
+	}
+	b := &openapiBuilder{doc: doc, opts: opts, opIDs: map[string]int{}, servers: map[string]bool{}}
+	for _, c := range cases {
+		if err := b.add(c); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.FilePath, err)
+		}
+	}
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			if op.Responses == nil || op.Responses.Len() == 0 {
+				op.Responses = openapi3.NewResponses(openapi3.WithName("default", &openapi3.Response{Description: ptr("Response")}))
+			}
+		}
+	}
+	return doc, nil
+}
+
+type openapiBuilder struct {
+	doc     *openapi3.T
+	opts    OpenAPIOptions
+	opIDs   map[string]int
+	servers map[string]bool
+}
+
+func (b *openapiBuilder) add(c parser.ParsedFile) error {
+	method := strings.ToUpper(c.Request.Verb)
+	if method == "" {
+		method = http.MethodGet
+	}
+	server, path, rawQuery := splitRequestURL(c.Request.URL)
+	if server != "" {
+		b.addServer(server)
+	}
+	path, pathParams := templatePath(path)
+
+	item := b.doc.Paths.Value(path)
+	if item == nil {
+		item = &openapi3.PathItem{}
+		b.doc.Paths.Set(path, item)
+	}
+	op := item.GetOperation(method)
+	if op == nil {
+		op = &openapi3.Operation{
+			Summary:     c.Meta.Name,
+			Description: strings.TrimSpace(c.Docs),
+			OperationID: b.operationID(c.Meta.Name, method, path),
+			Tags:        b.tags(c),
+		}
+		item.SetOperation(method, op)
+	} else if op.Description == "" {
+		op.Description = strings.TrimSpace(c.Docs)
+	}
+
+	for _, name := range pathParams {
+		p := openapi3.NewPathParameter(name).WithSchema(openapi3.NewStringSchema())
+		if v, ok := c.Request.PathParams[name]; ok && !placeholder.MatchString(v) {
+			p.Example = v
+		}
+		addParam(op, p)
+	}
+	query := map[string]string{}
+	for _, kv := range strings.Split(rawQuery, "&") {
+		if kv == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(kv, "=")
+		if uk, err := url.QueryUnescape(k); err == nil {
+			k = uk
+		}
+		if uv, err := url.QueryUnescape(v); err == nil {
+			v = uv
+		}
+		query[k] = v
+	}
+	for k, v := range c.Request.Query {
+		query[k] = v
+	}
+	for _, k := range sortedKeys(query) {
+		schema, example := scalarSchema(query[k])
+		p := openapi3.NewQueryParameter(k).WithSchema(schema)
+		p.Example = example
+		addParam(op, p)
+	}
+	contentType := ""
+	for _, k := range sortedKeys(c.Request.Headers) {
+		v := c.Request.Headers[k]
+		switch strings.ToLower(k) {
+		case "content-type":
+			contentType = v
+		case "authorization":
+			b.addSecurity(op, v)
+		case "accept", "content-length", "host", "user-agent":
+		default:
+			p := openapi3.NewHeaderParameter(k).WithSchema(openapi3.NewStringSchema())
+			if !placeholder.MatchString(v) {
+				p.Example = v
+			}
+			addParam(op, p)
+		}
+	}
+	if op.RequestBody == nil && c.Request.Body.Present {
+		op.RequestBody = requestBody(c.Request, contentType)
+	}
+	b.addResponses(op, c)
+	return nil
+}
+
+func (b *openapiBuilder) addServer(server string) {
+	if b.servers[server] {
+		return
+	}
+	b.servers[server] = true
+	s := &openapi3.Server{URL: server}
+	for _, m := range regexp.MustCompile(`\{([^}]+)\}`).FindAllStringSubmatch(server, -1) {
+		def := b.opts.Vars[m[1]]
+		if def == "" {
+			def = "http://localhost"
+		}
+		if s.Variables == nil {
+			s.Variables = map[string]*openapi3.ServerVariable{}
+		}
+		s.Variables[m[1]] = &openapi3.ServerVariable{Default: def}
+	}
+	b.doc.Servers = append(b.doc.Servers, s)
+}
+
+func (b *openapiBuilder) addSecurity(op *openapi3.Operation, value string) {
+	scheme, name := "", ""
+	switch {
+	case strings.HasPrefix(strings.ToLower(value), "bearer "):
+		scheme, name = "bearer", "bearerAuth"
+	case strings.HasPrefix(strings.ToLower(value), "basic "):
+		scheme, name = "basic", "basicAuth"
+	default:
+		return
+	}
+	if b.doc.Components == nil {
+		b.doc.Components = &openapi3.Components{}
+	}
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+	}
+	if _, ok := b.doc.Components.SecuritySchemes[name]; !ok {
+		b.doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "http", Scheme: scheme}}
+	}
+	if op.Security == nil {
+		op.Security = openapi3.NewSecurityRequirements()
+	}
+	for _, req := range *op.Security {
+		if _, ok := req[name]; ok {
+			return
+		}
+	}
+	op.Security.With(openapi3.NewSecurityRequirement().Authenticate(name))
+}
+
+// addResponses documents observed responses, or statuses asserted with
+// "res.status: eq N" when nothing was observed.
+func (b *openapiBuilder) addResponses(op *openapi3.Operation, c parser.ParsedFile) {
+	obs := b.opts.Responses[CaseKey(c.FilePath, c.Meta.Name)]
+	if len(obs) == 0 {
+		for _, a := range c.Assert {
+			if strings.TrimSpace(a.Left) != "res.status" {
+				continue
+			}
+			if m := statusAssert.FindStringSubmatch(strings.TrimSpace(a.Op + " " + a.Right)); m != nil {
+				obs = append(obs, Observation{Status: atoi(m[1])})
+			}
+		}
+	}
+	for _, o := range obs {
+		if op.Responses == nil {
+			op.Responses = openapi3.NewResponses()
+			op.Responses.Delete("default")
+		}
+		code := strconv.Itoa(o.Status)
+		ref := op.Responses.Value(code)
+		if ref == nil {
+			desc := http.StatusText(o.Status)
+			if desc == "" {
+				desc = "Response"
+			}
+			ref = &openapi3.ResponseRef{Value: &openapi3.Response{Description: &desc}}
+			op.Responses.Set(code, ref)
+		}
+		mt, _, _ := mime.ParseMediaType(o.ContentType)
+		if mt == "" || len(o.Body) == 0 {
+			continue
+		}
+		if ref.Value.Content == nil {
+			ref.Value.Content = openapi3.Content{}
+		}
+		media := ref.Value.Content.Get(mt)
+		if media == nil {
+			media = &openapi3.MediaType{}
+			ref.Value.Content[mt] = media
+		}
+		if !isJSONMedia(mt) {
+			if media.Schema == nil {
+				media.Schema = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+			}
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(o.Body, &v); err != nil {
+			continue
+		}
+		var prev *openapi3.Schema
+		if media.Schema != nil {
+			prev = media.Schema.Value
+		} else {
+			media.Example = v
+		}
+		media.Schema = openapi3.NewSchemaRef("", MergeSchemas(prev, InferSchema(v)))
+	}
+}
+
+func (b *openapiBuilder) operationID(name, method, path string) string {
+	base := camelIdent(name)
+	if base == "" {
+		base = camelIdent(strings.ToLower(method) + " " + path)
+	}
+	b.opIDs[base]++
+	if n := b.opIDs[base]; n > 1 {
+		return fmt.Sprintf("%s%d", base, n)
+	}
+	return base
+}
+
+func (b *openapiBuilder) tags(c parser.ParsedFile) []string {
+	if len(c.Meta.Tags) > 0 {
+		return append([]string(nil), c.Meta.Tags...)
+	}
+	if b.opts.Root == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(b.opts.Root, filepath.Dir(c.FilePath))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	return []string{filepath.ToSlash(rel)}
+}
+
+// splitRequestURL separates the server ("{baseUrl}" or scheme://host), the
+// path and the raw query of a .bru URL.
+func splitRequestURL(raw string) (server, path, query string) {
+	raw, _, _ = strings.Cut(raw, "#")
+	raw, query, _ = strings.Cut(raw, "?")
+	switch {
+	case strings.HasPrefix(raw, "{{"):
+		if name, rest, ok := strings.Cut(raw[2:], "}}"); ok {
+			server, path = "{"+strings.TrimSpace(name)+"}", rest
+		}
+	case strings.Contains(raw, "://"):
+		scheme, rest, _ := strings.Cut(raw, "://")
+		host, p, _ := strings.Cut(rest, "/")
+		server, path = scheme+"://"+host, "/"+p
+		server = placeholder.ReplaceAllString(server, "{$1}")
+	default:
+		path = raw
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return server, path, query
+}
+
+// templatePath turns ":id" and "{{id}}" segments into "{id}" and returns the
+// parameter names in order.
+func templatePath(path string) (string, []string) {
+	var names []string
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		switch {
+		case strings.HasPrefix(seg, ":") && len(seg) > 1:
+			names = append(names, seg[1:])
+			segs[i] = "{" + seg[1:] + "}"
+		case placeholder.MatchString(seg):
+			segs[i] = placeholder.ReplaceAllStringFunc(seg, func(m string) string {
+				name := strings.TrimSpace(m[2 : len(m)-2])
+				names = append(names, name)
+				return "{" + name + "}"
+			})
+		}
+	}
+	if len(segs) > 1 && segs[len(segs)-1] == "" {
+		segs = segs[:len(segs)-1]
+	}
+	out := strings.Join(segs, "/")
+	if out == "" {
+		out = "/"
+	}
+	return out, names
+}
+
+func addParam(op *openapi3.Operation, p *openapi3.Parameter) {
+	if op.Parameters.GetByInAndName(p.In, p.Name) != nil {
+		return
+	}
+	op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: p})
+}
+
+func requestBody(r parser.RequestBlock, contentType string) *openapi3.RequestBodyRef {
+	raw := strings.TrimSpace(r.Body.Raw)
+	media := &openapi3.MediaType{}
+	mt := ""
+	switch r.Body.Type {
+	case "", "json":
+		mt = "application/json"
+		if v, ok := jsonExample(raw); ok {
+			media.Example = v
+			media.Schema = openapi3.NewSchemaRef("", InferSchema(v))
+		} else {
+			media.Schema = openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+		}
+	case "graphql":
+		mt = "application/json"
+		s := openapi3.NewObjectSchema().
+			WithProperty("query", openapi3.NewStringSchema()).
+			WithProperty("variables", openapi3.NewObjectSchema())
+		s.Required = []string{"query"}
+		media.Schema = openapi3.NewSchemaRef("", s)
+		media.Example = map[string]any{"query": raw}
+	case "form-urlencoded", "multipart-form":
+		mt = "application/x-www-form-urlencoded"
+		if r.Body.Type == "multipart-form" {
+			mt = "multipart/form-data"
+		}
+		s := openapi3.NewObjectSchema()
+		example := map[string]any{}
+		for _, k := range sortedKeys(r.Body.Fields) {
+			v := r.Body.Fields[k]
+			if strings.HasPrefix(v, "@") {
+				s.WithProperty(k, openapi3.NewStringSchema().WithFormat("binary"))
+				continue
+			}
+			s.WithProperty(k, openapi3.NewStringSchema())
+			if !placeholder.MatchString(v) {
+				example[k] = v
+			}
+		}
+		media.Schema = openapi3.NewSchemaRef("", s)
+		if len(example) > 0 {
+			media.Example = example
+		}
+	case "xml":
+		mt = "application/xml"
+		media.Schema = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		media.Example = raw
+	default:
+		mt = "text/plain"
+		media.Schema = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		media.Example = raw
+	}
+	if ct, _, err := mime.ParseMediaType(contentType); err == nil && ct != "" && r.Body.Type != "form-urlencoded" && r.Body.Type != "multipart-form" {
+		mt = ct
+	}
+	return &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithContent(openapi3.Content{mt: media})}
+}
+
+// jsonExample parses a .bru JSON body; unquoted {{placeholders}} become null
+// so templated numbers and objects still parse.
+func jsonExample(raw string) (any, bool) {
+	replaced := jsonPlaceholder.ReplaceAllStringFunc(raw, func(m string) string {
+		if strings.HasPrefix(m, `"`) {
+			return m
+		}
+		return "null"
+	})
+	var v any
+	if err := json.Unmarshal([]byte(replaced), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// InferSchema derives a JSON schema from a decoded JSON value. Objects list
+// every observed property as required.
+func InferSchema(v any) *openapi3.Schema {
+	switch t := v.(type) {
+	case nil:
+		return &openapi3.Schema{Nullable: true}
+	case bool:
+		return openapi3.NewBoolSchema()
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return openapi3.NewIntegerSchema()
+		}
+		return openapi3.NewFloat64Schema()
+	case string:
+		return openapi3.NewStringSchema()
+	case []any:
+		var items *openapi3.Schema
+		for _, e := range t {
+			items = MergeSchemas(items, InferSchema(e))
+		}
+		s := openapi3.NewArraySchema()
+		if items == nil {
+			items = &openapi3.Schema{}
+		}
+		s.Items = openapi3.NewSchemaRef("", items)
+		return s
+	case map[string]any:
+		s := openapi3.NewObjectSchema()
+		for _, k := range sortedKeys(t) {
+			s.WithProperty(k, InferSchema(t[k]))
+			s.Required = append(s.Required, k)
+		}
+		return s
+	}
+	return &openapi3.Schema{}
+}
+
+// MergeSchemas widens a and b into one schema: object properties are united
+// (required only when present in both), array items merged, integer and
+// number become number, null makes the other side nullable and any other
+// type conflict yields an unconstrained schema.
+func MergeSchemas(a, b *openapi3.Schema) *openapi3.Schema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	ta, tb := schemaType(a), schemaType(b)
+	switch {
+	case ta == "" && a.Nullable:
+		out := *b
+		out.Nullable = true
+		return &out
+	case tb == "" && b.Nullable:
+		out := *a
+		out.Nullable = true
+		return &out
+	case ta == "" || tb == "":
+		return &openapi3.Schema{}
+	}
+	nullable := a.Nullable || b.Nullable
+	if ta != tb {
+		if (ta == "integer" || ta == "number") && (tb == "integer" || tb == "number") {
+			s := openapi3.NewFloat64Schema()
+			s.Nullable = nullable
+			return s
+		}
+		return &openapi3.Schema{Nullable: nullable}
+	}
+	switch ta {
+	case "object":
+		s := openapi3.NewObjectSchema()
+		s.Nullable = nullable
+		keys := map[string]struct{}{}
+		for k := range a.Properties {
+			keys[k] = struct{}{}
+		}
+		for k := range b.Properties {
+			keys[k] = struct{}{}
+		}
+		for _, k := range sortedKeys(keys) {
+			s.WithProperty(k, MergeSchemas(propValue(a, k), propValue(b, k)))
+		}
+		inB := map[string]bool{}
+		for _, k := range b.Required {
+			inB[k] = true
+		}
+		for _, k := range a.Required {
+			if inB[k] {
+				s.Required = append(s.Required, k)
+			}
+		}
+		return s
+	case "array":
+		s := openapi3.NewArraySchema()
+		s.Nullable = nullable
+		var ia, ib *openapi3.Schema
+		if a.Items != nil {
+			ia = a.Items.Value
+		}
+		if b.Items != nil {
+			ib = b.Items.Value
+		}
+		// An empty array says nothing about its items.
+		if ia != nil && schemaType(ia) == "" && !ia.Nullable {
+			ia = nil
+		}
+		if ib != nil && schemaType(ib) == "" && !ib.Nullable {
+			ib = nil
+		}
+		items := MergeSchemas(ia, ib)
+		if items == nil {
+			items = &openapi3.Schema{}
+		}
+		s.Items = openapi3.NewSchemaRef("", items)
+		return s
+	}
+	out := *a
+	out.Nullable = nullable
+	return &out
+}
+
+func schemaType(s *openapi3.Schema) string {
+	if s == nil || s.Type == nil || len(*s.Type) != 1 {
+		return ""
+	}
+	return (*s.Type)[0]
+}
+
+func propValue(s *openapi3.Schema, k string) *openapi3.Schema {
+	if ref := s.Properties[k]; ref != nil {
+		return ref.Value
+	}
+	return nil
+}
+
+// scalarSchema guesses a query parameter schema and typed example from its
+// value; templated values get no example.
+func scalarSchema(v string) (*openapi3.Schema, any) {
+	if placeholder.MatchString(v) {
+		return openapi3.NewStringSchema(), nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return openapi3.NewIntegerSchema(), n
+	}
+	if b, err := strconv.ParseBool(v); err == nil && (v == "true" || v == "false") {
+		return openapi3.NewBoolSchema(), b
+	}
+	return openapi3.NewStringSchema(), v
+}
+
+func isJSONMedia(mt string) bool {
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// camelIdent turns "List users (v2)" into "listUsersV2".
+func camelIdent(s string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = b.Len() > 0
+			continue
+		}
+		switch {
+		case b.Len() == 0:
+			if unicode.IsDigit(r) {
+				b.WriteString("op")
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteRune(r)
+		}
+		upper = false
+	}
+	return b.String()
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
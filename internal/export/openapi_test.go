@@ -0,0 +1,177 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+	"pkt.systems/pslog"
+)
+
+func writeOpenAPICollection(t *testing.T, dir string) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version":"1","name":"c","type":"collection"}`)
+	writeFile(t, filepath.Join(dir, "users", "get.bru"), `meta {
+  name: Get user
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/:id?verbose=true
+  body: none
+  auth: none
+}
+
+params:path {
+  id: 42
+}
+
+headers {
+  Authorization: Bearer {{token}}
+  X-Trace: abc
+}
+
+assert {
+  res.status: eq 200
+}
+
+docs {
+  Fetches one user.
+}
+`)
+	writeFile(t, filepath.Join(dir, "users", "create.bru"), `meta {
+  name: Create user
+  type: http
+  seq: 2
+  tags: [write]
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+body:json {
+  {"name": "ada", "age": {{age}}, "admin": false}
+}
+`)
+}
+
+func TestOpenAPIFromCases(t *testing.T) {
+	dir := t.TempDir()
+	writeOpenAPICollection(t, dir)
+	cases, vars, err := runner.LoadCases(context.Background(), dir, runner.RunOptions{Vars: map[string]string{"baseUrl": "https://api.test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := OpenAPI(cases, OpenAPIOptions{Title: "Users", Root: dir, Vars: vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "{baseUrl}" || doc.Servers[0].Variables["baseUrl"].Default != "https://api.test" {
+		t.Fatalf("servers: %+v", doc.Servers)
+	}
+
+	get := doc.Paths.Value("/users/{id}").Get
+	if get == nil || get.Summary != "Get user" || get.Description != "Fetches one user." || get.OperationID != "getUser" {
+		t.Fatalf("get operation: %+v", get)
+	}
+	if len(get.Tags) != 1 || get.Tags[0] != "users" {
+		t.Fatalf("folder tag: %v", get.Tags)
+	}
+	if p := get.Parameters.GetByInAndName("path", "id"); p == nil || !p.Required || p.Example != "42" {
+		t.Fatalf("path param: %+v", p)
+	}
+	if p := get.Parameters.GetByInAndName("query", "verbose"); p == nil || !p.Schema.Value.Type.Is("boolean") {
+		t.Fatalf("query param: %+v", p)
+	}
+	if get.Parameters.GetByInAndName("header", "X-Trace") == nil || get.Parameters.GetByInAndName("header", "Authorization") != nil {
+		t.Fatalf("header params: %+v", get.Parameters)
+	}
+	if get.Security == nil || len(*get.Security) != 1 || doc.Components.SecuritySchemes["bearerAuth"] == nil {
+		t.Fatalf("security: %+v", get.Security)
+	}
+	if get.Responses.Value("200") == nil {
+		t.Fatalf("asserted status missing: %v", get.Responses.Map())
+	}
+
+	post := doc.Paths.Value("/users").Post
+	if post == nil || len(post.Tags) != 1 || post.Tags[0] != "write" {
+		t.Fatalf("post operation: %+v", post)
+	}
+	media := post.RequestBody.Value.Content.Get("application/json")
+	if media == nil || media.Schema.Value.Properties["age"] == nil || !media.Schema.Value.Properties["admin"].Value.Type.Is("boolean") {
+		t.Fatalf("request body: %+v", media)
+	}
+	if post.Responses.Value("default") == nil {
+		t.Fatalf("default response missing")
+	}
+}
+
+func TestObserveResponsesMergesIterations(t *testing.T) {
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		n++
+		body := map[string]any{"id": 42, "name": "ada", "score": 1}
+		if r.Method == http.MethodGet && n > 2 {
+			// Second iteration: nickname appears, name disappears, score widens.
+			body = map[string]any{"id": 42, "nickname": "a", "score": 1.5}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeOpenAPICollection(t, dir)
+	opts := runner.RunOptions{
+		Vars:           map[string]string{"baseUrl": srv.URL, "token": "t", "age": "3"},
+		IterationCount: 2,
+		Logger:         pslog.NewWithOptions(&strings.Builder{}, pslog.Options{MinLevel: pslog.Disabled}),
+	}
+	obs, sum, err := ObserveResponses(context.Background(), dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Failed != 0 {
+		t.Fatalf("run failed: %+v", sum)
+	}
+	getKey := CaseKey(filepath.Join(dir, "users", "get.bru"), "Get user")
+	if len(obs[getKey]) != 2 {
+		t.Fatalf("observations: %v", obs)
+	}
+
+	cases, vars, err := runner.LoadCases(context.Background(), dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := OpenAPI(cases, OpenAPIOptions{Root: dir, Vars: vars, Responses: obs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	schema := doc.Paths.Value("/users/{id}").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	for _, prop := range []string{"id", "name", "nickname", "score"} {
+		if schema.Properties[prop] == nil {
+			t.Fatalf("missing property %s: %v", prop, schema.Properties)
+		}
+	}
+	if !schema.Properties["score"].Value.Type.Is("number") || !schema.Properties["id"].Value.Type.Is("integer") {
+		t.Fatalf("merged types: score=%v id=%v", schema.Properties["score"].Value.Type, schema.Properties["id"].Value.Type)
+	}
+	if strings.Join(schema.Required, ",") != "id,score" {
+		t.Fatalf("required: %v", schema.Required)
+	}
+}
@@ -0,0 +1,329 @@
+package export
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SnippetLanguages lists the languages Snippet renders.
+var SnippetLanguages = []string{"go", "python-requests", "js-fetch", "httpie", "curl"}
+
+// Snippet renders req as a runnable client snippet in lang (see
+// SnippetLanguages). multipart/form-data bodies are rebuilt with the
+// language's own form API, file parts reading the part's file name from the
+// working directory; any other body is sent verbatim.
+// The request body is consumed and restored, so req stays usable.
+func Snippet(req *http.Request, lang string) (string, error) {
+	if lang == "curl" {
+		return Curl(req)
+	}
+	sr, err := newSnippetRequest(req)
+	if err != nil {
+		return "", err
+	}
+	switch lang {
+	case "go":
+		return sr.golang(), nil
+	case "python-requests":
+		return sr.python(), nil
+	case "js-fetch":
+		return sr.jsFetch(), nil
+	case "httpie":
+		return sr.httpie(), nil
+	}
+	return "", fmt.Errorf("unsupported snippet language %q (supported: %s)", lang, strings.Join(SnippetLanguages, ", "))
+}
+
+// SnippetComment returns the line comment prefix of lang.
+func SnippetComment(lang string) string {
+	switch lang {
+	case "go", "js-fetch":
+		return "//"
+	}
+	return "#"
+}
+
+type snippetRequest struct {
+	Method  string
+	URL     string
+	Headers [][2]string
+	Body    []byte
+	// Parts holds multipart/form-data bodies; Content-Type is then left to
+	// the client library.
+	Parts []formPart
+}
+
+func newSnippetRequest(req *http.Request) (snippetRequest, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return snippetRequest{}, err
+	}
+	sr := snippetRequest{Method: req.Method, URL: req.URL.String(), Body: body}
+	if sr.Method == "" {
+		sr.Method = http.MethodGet
+	}
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" && params["boundary"] != "" && len(body) > 0 {
+		if sr.Parts, err = readParts(body, params["boundary"]); err != nil {
+			return snippetRequest{}, err
+		}
+		sr.Body = nil
+	}
+	names := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if strings.EqualFold(k, "Content-Length") || (sr.Parts != nil && strings.EqualFold(k, "Content-Type")) {
+			continue
+		}
+		for _, v := range req.Header[k] {
+			sr.Headers = append(sr.Headers, [2]string{k, v})
+		}
+	}
+	return sr, nil
+}
+
+func (sr snippetRequest) golang() string {
+	imports := map[string]bool{"fmt": true, "io": true, "net/http": true}
+	var b strings.Builder
+	bodyArg := "nil"
+	switch {
+	case sr.Parts != nil:
+		imports["bytes"], imports["mime/multipart"] = true, true
+		b.WriteString("\tvar body bytes.Buffer\n\tmw := multipart.NewWriter(&body)\n")
+		for _, p := range sr.Parts {
+			if p.FileName == "" && p.ContentType == "" {
+				fmt.Fprintf(&b, "\tif err := mw.WriteField(%s, %s); err != nil {\n\t\tpanic(err)\n\t}\n", goString(p.Name), goString(string(p.Value)))
+				continue
+			}
+			imports["net/textproto"] = true
+			disposition := fmt.Sprintf("form-data; name=%q", p.Name)
+			if p.FileName != "" {
+				disposition += fmt.Sprintf("; filename=%q", p.FileName)
+			}
+			b.WriteString("\t{\n\t\th := make(textproto.MIMEHeader)\n")
+			fmt.Fprintf(&b, "\t\th.Set(\"Content-Disposition\", %s)\n", goString(disposition))
+			if p.ContentType != "" {
+				fmt.Fprintf(&b, "\t\th.Set(\"Content-Type\", %s)\n", goString(p.ContentType))
+			}
+			b.WriteString("\t\tpart, err := mw.CreatePart(h)\n\t\tif err != nil {\n\t\t\tpanic(err)\n\t\t}\n")
+			if p.FileName != "" {
+				imports["os"] = true
+				fmt.Fprintf(&b, "\t\tf, err := os.Open(%s)\n\t\tif err != nil {\n\t\t\tpanic(err)\n\t\t}\n", goString(p.FileName))
+				b.WriteString("\t\tif _, err := io.Copy(part, f); err != nil {\n\t\t\tpanic(err)\n\t\t}\n\t\tf.Close()\n")
+			} else {
+				fmt.Fprintf(&b, "\t\tif _, err := io.WriteString(part, %s); err != nil {\n\t\t\tpanic(err)\n\t\t}\n", goString(string(p.Value)))
+			}
+			b.WriteString("\t}\n")
+		}
+		b.WriteString("\tif err := mw.Close(); err != nil {\n\t\tpanic(err)\n\t}\n\n")
+		bodyArg = "&body"
+	case len(sr.Body) > 0:
+		imports["strings"] = true
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", goString(string(sr.Body)))
+		bodyArg = "body"
+	}
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", goString(sr.Method), goString(sr.URL), bodyArg)
+	for _, h := range sr.Headers {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", goString(h[0]), goString(h[1]))
+	}
+	if sr.Parts != nil {
+		b.WriteString("\treq.Header.Set(\"Content-Type\", mw.FormDataContentType())\n")
+	}
+	b.WriteString(`
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(res.Status)
+	fmt.Println(string(data))
+}
+`)
+	pkgs := make([]string, 0, len(imports))
+	for p := range imports {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+	var out strings.Builder
+	out.WriteString("package main\n\nimport (\n")
+	for _, p := range pkgs {
+		fmt.Fprintf(&out, "\t%q\n", p)
+	}
+	out.WriteString(")\n\nfunc main() {\n")
+	out.WriteString(b.String())
+	return out.String()
+}
+
+func (sr snippetRequest) python() string {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", jsString(sr.URL))
+	args := []string{jsString(sr.Method), "url"}
+	if len(sr.Headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, h := range mergedHeaders(sr.Headers) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsString(h[0]), jsString(h[1]))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	switch {
+	case sr.Parts != nil:
+		b.WriteString("files = [\n")
+		for _, p := range sr.Parts {
+			switch {
+			case p.FileName != "" && p.ContentType != "":
+				fmt.Fprintf(&b, "    (%s, (%s, open(%s, \"rb\"), %s)),\n", jsString(p.Name), jsString(p.FileName), jsString(p.FileName), jsString(p.ContentType))
+			case p.FileName != "":
+				fmt.Fprintf(&b, "    (%s, (%s, open(%s, \"rb\"))),\n", jsString(p.Name), jsString(p.FileName), jsString(p.FileName))
+			case p.ContentType != "":
+				fmt.Fprintf(&b, "    (%s, (None, %s, %s)),\n", jsString(p.Name), jsString(string(p.Value)), jsString(p.ContentType))
+			default:
+				fmt.Fprintf(&b, "    (%s, (None, %s)),\n", jsString(p.Name), jsString(string(p.Value)))
+			}
+		}
+		b.WriteString("]\n")
+		args = append(args, "files=files")
+	case len(sr.Body) > 0:
+		fmt.Fprintf(&b, "data = %s\n", jsString(string(sr.Body)))
+		args = append(args, "data=data.encode(\"utf-8\")")
+	}
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s)\nprint(response.status_code)\nprint(response.text)\n", strings.Join(args, ", "))
+	return b.String()
+}
+
+func (sr snippetRequest) jsFetch() string {
+	var b strings.Builder
+	hasFiles := false
+	for _, p := range sr.Parts {
+		hasFiles = hasFiles || p.FileName != ""
+	}
+	if hasFiles {
+		b.WriteString("import { readFile } from \"node:fs/promises\";\n\n")
+	}
+	body := ""
+	switch {
+	case sr.Parts != nil:
+		b.WriteString("const form = new FormData();\n")
+		for _, p := range sr.Parts {
+			switch {
+			case p.FileName != "":
+				opts := ""
+				if p.ContentType != "" {
+					opts = ", { type: " + jsString(p.ContentType) + " }"
+				}
+				fmt.Fprintf(&b, "form.append(%s, new Blob([await readFile(%s)]%s), %s);\n", jsString(p.Name), jsString(p.FileName), opts, jsString(p.FileName))
+			case p.ContentType != "":
+				fmt.Fprintf(&b, "form.append(%s, new Blob([%s], { type: %s }));\n", jsString(p.Name), jsString(string(p.Value)), jsString(p.ContentType))
+			default:
+				fmt.Fprintf(&b, "form.append(%s, %s);\n", jsString(p.Name), jsString(string(p.Value)))
+			}
+		}
+		b.WriteString("\n")
+		body = "form"
+	case len(sr.Body) > 0:
+		body = jsString(string(sr.Body))
+	}
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n  method: %s,\n", jsString(sr.URL), jsString(sr.Method))
+	if len(sr.Headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range mergedHeaders(sr.Headers) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsString(h[0]), jsString(h[1]))
+		}
+		b.WriteString("  },\n")
+	}
+	if body != "" {
+		fmt.Fprintf(&b, "  body: %s,\n", body)
+	}
+	b.WriteString("});\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+	return b.String()
+}
+
+func (sr snippetRequest) httpie() string {
+	first := []string{"http"}
+	if sr.Parts != nil {
+		first = append(first, "--multipart")
+	}
+	first = append(first, sr.Method, shellQuote(sr.URL))
+	args := []string{strings.Join(first, " ")}
+	for _, h := range sr.Headers {
+		if h[1] == "" {
+			args = append(args, shellQuote(httpieKey(h[0])+";"))
+			continue
+		}
+		args = append(args, shellQuote(httpieKey(h[0])+":"+h[1]))
+	}
+	for _, p := range sr.Parts {
+		var item string
+		if p.FileName != "" {
+			item = httpieKey(p.Name) + "@" + p.FileName
+		} else {
+			item = httpieKey(p.Name) + "=" + string(p.Value)
+		}
+		if p.ContentType != "" {
+			item += ";type=" + p.ContentType
+		}
+		args = append(args, shellQuote(item))
+	}
+	if len(sr.Body) > 0 {
+		args = append(args, "--raw "+shellQuote(string(sr.Body)))
+	}
+	return strings.Join(args, " \\\n  ")
+}
+
+// httpieKey escapes the request-item separators in a header or field name.
+func httpieKey(k string) string {
+	var b strings.Builder
+	for _, r := range k {
+		if strings.ContainsRune(`\:=@;`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mergedHeaders joins repeated headers with ", " for languages taking a
+// plain header map.
+func mergedHeaders(hs [][2]string) [][2]string {
+	var out [][2]string
+	for _, h := range hs {
+		if n := len(out); n > 0 && out[n-1][0] == h[0] {
+			out[n-1][1] += ", " + h[1]
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// goString quotes s as a Go string literal, preferring a raw string for
+// readable multi-line or quote-heavy text.
+func goString(s string) string {
+	if strings.ContainsAny(s, "\"\n") && !strings.ContainsAny(s, "`\r") && utf8.ValidString(s) {
+		raw := true
+		for _, r := range s {
+			if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+				raw = false
+				break
+			}
+		}
+		if raw {
+			return "`" + s + "`"
+		}
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,130 @@
+package export
+
+import (
+	"context"
+	"go/format"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+)
+
+func TestSnippets(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version":"1","name":"c","type":"collection"}`)
+	writeFile(t, filepath.Join(dir, "create.bru"), `meta {
+  name: Create
+  type: http
+  seq: 1
+}
+
+post {
+  url: https://api.test/users?dry=1
+  body: json
+  auth: none
+}
+
+headers {
+  Authorization: Bearer {{token}}
+}
+
+body:json {
+  {"name": "ada \"the\" first"}
+}
+`)
+	writeFile(t, filepath.Join(dir, "upload.bru"), `meta {
+  name: Upload
+  type: http
+  seq: 2
+}
+
+put {
+  url: https://api.test/files
+  body: multipart-form
+  auth: none
+}
+
+body:multipart-form {
+  note: line
+  file: @`+filepath.Join(dir, "data.txt")+`;type=text/plain
+}
+`)
+	writeFile(t, filepath.Join(dir, "data.txt"), "hello")
+	writeFile(t, filepath.Join(dir, "gql.bru"), `meta {
+  name: Query
+  type: graphql
+  seq: 3
+}
+
+post {
+  url: https://api.test/graphql
+  body: graphql
+  auth: none
+}
+
+body:graphql {
+  { me { id } }
+}
+`)
+
+	reqs, err := runner.BuildRequests(context.Background(), dir, runner.RunOptions{Vars: map[string]string{"token": "s3cr3t"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("requests: %+v", reqs)
+	}
+	create, upload, gql := reqs[0].Request, reqs[1].Request, reqs[2].Request
+
+	for _, pr := range reqs {
+		src, err := Snippet(pr.Request, "go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			t.Fatalf("%s: go snippet does not parse: %v\n%s", pr.Name, err, src)
+		}
+		if string(formatted) != src {
+			t.Fatalf("%s: go snippet not gofmt'ed:\n%s", pr.Name, src)
+		}
+	}
+
+	cases := []struct {
+		lang string
+		req  *http.Request
+		want []string
+	}{
+		{"go", create, []string{"strings.NewReader(`{\"name\":\"ada \\\"the\\\" first\"}`)", `http.NewRequest("POST", "https://api.test/users?dry=1", body)`, `req.Header.Add("Authorization", "Bearer s3cr3t")`}},
+		{"go", upload, []string{`mw.WriteField("note", "line")`, `os.Open("data.txt")`, `h.Set("Content-Type", "text/plain")`, `req.Header.Set("Content-Type", mw.FormDataContentType())`}},
+		{"python-requests", create, []string{`url = "https://api.test/users?dry=1"`, `"Authorization": "Bearer s3cr3t",`, `data = "{\"name\":\"ada \\\"the\\\" first\"}"`, `requests.request("POST", url, headers=headers, data=data.encode("utf-8"))`}},
+		{"python-requests", upload, []string{`("note", (None, "line")),`, `("file", ("data.txt", open("data.txt", "rb"), "text/plain")),`, `files=files`}},
+		{"js-fetch", upload, []string{`import { readFile } from "node:fs/promises";`, `form.append("note", "line");`, `new Blob([await readFile("data.txt")], { type: "text/plain" }), "data.txt");`, `body: form,`}},
+		{"js-fetch", gql, []string{`method: "POST",`, `body: "{\"query\":\"{ me { id } }\"}",`}},
+		{"httpie", create, []string{"http POST 'https://api.test/users?dry=1'", "'Authorization:Bearer s3cr3t'", `--raw '{"name":"ada \"the\" first"}'`}},
+		{"httpie", upload, []string{"http --multipart PUT https://api.test/files", "note=line", "'file@data.txt;type=text/plain'"}},
+	}
+	for _, tc := range cases {
+		out, err := Snippet(tc.req, tc.lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(out, want) {
+				t.Fatalf("%s snippet of %s missing %s:\n%s", tc.lang, tc.req.URL, want, out)
+			}
+		}
+		if strings.Contains(out, "boundary") {
+			t.Fatalf("%s snippet of %s leaks the multipart boundary:\n%s", tc.lang, tc.req.URL, out)
+		}
+	}
+	if body, _ := io.ReadAll(create.Body); !strings.Contains(string(body), "ada") {
+		t.Fatalf("body not restored: %q", body)
+	}
+	if _, err := Snippet(create, "cobol"); err == nil {
+		t.Fatal("expected unsupported language error")
+	}
+}
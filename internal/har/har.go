@@ -0,0 +1,165 @@
+// Package har records HTTP exchanges in HAR 1.2 format
+// (http://www.softwareishard.com/blog/har-12-spec/) so runs can be inspected
+// in browser devtools after the fact.
+package har
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// File is the top-level HAR document.
+type File struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded entries.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifies the application that produced the log.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request/response exchange.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	Connection      string   `json:"connection,omitempty"`
+	Comment         string   `json:"comment,omitempty"`
+}
+
+// Request describes the outgoing request.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response describes the received response. Error is a non-standard field
+// set when the transport failed before a response arrived.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+	Error       string      `json:"_error,omitempty"`
+}
+
+// Cookie is a request or response cookie.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+// NameValue is a header or query string pair.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData carries the request body.
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text"`
+	Params   []NameValue `json:"params,omitempty"`
+}
+
+// Content carries the response body; Encoding is "base64" for binary payloads.
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Timings are phase durations in milliseconds; -1 marks a phase that did not apply.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HeaderFilter decides how a header is written. It returns the value to keep
+// (possibly masked) and false when the header should be dropped.
+type HeaderFilter func(name, value string) (string, bool)
+
+// FilterHeaders returns a copy of the log with f applied to every request and
+// response header. Cookies follow their carrying header: they are dropped with
+// Cookie/Set-Cookie and masked when that header's value is masked.
+func (l Log) FilterHeaders(f HeaderFilter) Log {
+	out := l
+	out.Entries = make([]Entry, len(l.Entries))
+	for i, e := range l.Entries {
+		e.Request.Headers = filterPairs(e.Request.Headers, f)
+		e.Request.Cookies = filterCookies(e.Request.Cookies, "Cookie", f)
+		e.Response.Headers = filterPairs(e.Response.Headers, f)
+		e.Response.Cookies = filterCookies(e.Response.Cookies, "Set-Cookie", f)
+		out.Entries[i] = e
+	}
+	return out
+}
+
+func filterPairs(in []NameValue, f HeaderFilter) []NameValue {
+	out := make([]NameValue, 0, len(in))
+	for _, nv := range in {
+		v, keep := f(nv.Name, nv.Value)
+		if !keep {
+			continue
+		}
+		out = append(out, NameValue{Name: nv.Name, Value: v})
+	}
+	return out
+}
+
+func filterCookies(in []Cookie, header string, f HeaderFilter) []Cookie {
+	out := make([]Cookie, 0, len(in))
+	for _, c := range in {
+		v, keep := f(header, c.Value)
+		if !keep {
+			continue
+		}
+		c.Value = v
+		out = append(out, c)
+	}
+	return out
+}
+
+// WriteFile writes the log as an indented HAR document.
+func WriteFile(path string, l Log) error {
+	data, err := json.MarshalIndent(File{Log: l}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
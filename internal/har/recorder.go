@@ -0,0 +1,315 @@
+package har
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder captures every exchange sent through clients it wraps. It is safe
+// for concurrent use.
+type Recorder struct {
+	creator Creator
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder returns an empty recorder that stamps logs with the given creator.
+func NewRecorder(name, version string) *Recorder {
+	return &Recorder{creator: Creator{Name: name, Version: version}}
+}
+
+// Client returns a shallow copy of c whose transport records into r.
+func (r *Recorder) Client(c *http.Client) *http.Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	cp := *c
+	cp.Transport = r.Transport(c.Transport)
+	return &cp
+}
+
+// Transport wraps next (http.DefaultTransport when nil) so that every round
+// trip is appended to the recorder.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{rec: r, next: next}
+}
+
+// Log returns a snapshot of the recorded entries.
+func (r *Recorder) Log() Log {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	return Log{Version: "1.2", Creator: r.creator, Entries: entries}
+}
+
+func (r *Recorder) add(e Entry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+}
+
+type commentKey struct{}
+
+// WithComment attaches a comment (e.g. the originating .bru file) to entries
+// recorded for requests carrying ctx.
+func WithComment(ctx context.Context, comment string) context.Context {
+	return context.WithValue(ctx, commentKey{}, comment)
+}
+
+type transport struct {
+	rec  *Recorder
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	}
+
+	tm := &traceTimes{}
+	start := time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tm.trace()))
+
+	entry := Entry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Request:         buildRequest(req, reqBody),
+	}
+	if c, ok := req.Context().Value(commentKey{}).(string); ok {
+		entry.Comment = c
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		end := time.Now()
+		entry.Time = ms(end.Sub(start))
+		entry.Timings = tm.timings(start, end)
+		entry.Response = Response{Cookies: []Cookie{}, Headers: []NameValue{}, HeadersSize: -1, BodySize: -1, Error: err.Error()}
+		t.rec.add(entry)
+		return nil, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	end := time.Now()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Time = ms(end.Sub(start))
+	entry.Timings = tm.timings(start, end)
+	entry.ServerIPAddress = tm.remoteIP()
+	entry.Response = buildResponse(resp, body)
+	if readErr != nil {
+		entry.Response.Error = readErr.Error()
+	}
+	t.rec.add(entry)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return resp, nil
+}
+
+func buildRequest(req *http.Request, body []byte) Request {
+	out := Request{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []Cookie{},
+		Headers:     headerPairs(req.Header),
+		QueryString: queryPairs(req.URL.RawQuery),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	if out.HTTPVersion == "" {
+		out.HTTPVersion = "HTTP/1.1"
+	}
+	for _, c := range req.Cookies() {
+		out.Cookies = append(out.Cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+	if body != nil {
+		ct := req.Header.Get("Content-Type")
+		pd := &PostData{MimeType: ct, Text: string(body)}
+		if mt, _, _ := mime.ParseMediaType(ct); mt == "application/x-www-form-urlencoded" {
+			pd.Params = queryPairs(string(body))
+		}
+		out.PostData = pd
+	}
+	return out
+}
+
+func buildResponse(resp *http.Response, body []byte) Response {
+	out := Response{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []Cookie{},
+		Headers:     headerPairs(resp.Header),
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(body),
+		Content: Content{
+			Size:     len(body),
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+	}
+	if utf8.Valid(body) {
+		out.Content.Text = string(body)
+	} else {
+		out.Content.Text = base64.StdEncoding.EncodeToString(body)
+		out.Content.Encoding = "base64"
+	}
+	for _, c := range resp.Cookies() {
+		hc := Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HTTPOnly: c.HttpOnly, Secure: c.Secure}
+		if !c.Expires.IsZero() {
+			hc.Expires = c.Expires.UTC().Format(time.RFC3339)
+		}
+		out.Cookies = append(out.Cookies, hc)
+	}
+	return out
+}
+
+func headerPairs(h http.Header) []NameValue {
+	out := []NameValue{}
+	for name, vals := range h {
+		for _, v := range vals {
+			out = append(out, NameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// queryPairs keeps the original parameter order, unlike url.Values.
+func queryPairs(raw string) []NameValue {
+	out := []NameValue{}
+	for _, part := range strings.Split(raw, "&") {
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		if uk, err := url.QueryUnescape(k); err == nil {
+			k = uk
+		}
+		if uv, err := url.QueryUnescape(v); err == nil {
+			v = uv
+		}
+		out = append(out, NameValue{Name: k, Value: v})
+	}
+	return out
+}
+
+// traceTimes collects httptrace callbacks, which may fire on dialer goroutines.
+type traceTimes struct {
+	mu                      sync.Mutex
+	gotConn                 time.Time
+	remote                  string
+	dnsStart, dnsDone       time.Time
+	connStart, connDone     time.Time
+	tlsStart, tlsDone       time.Time
+	wroteRequest, firstByte time.Time
+}
+
+func (tt *traceTimes) trace() *httptrace.ClientTrace {
+	set := func(dst *time.Time) {
+		tt.mu.Lock()
+		if dst.IsZero() {
+			*dst = time.Now()
+		}
+		tt.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&tt.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&tt.dnsDone) },
+		ConnectStart:      func(string, string) { set(&tt.connStart) },
+		ConnectDone:       func(string, string, error) { set(&tt.connDone) },
+		TLSHandshakeStart: func() { set(&tt.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&tt.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			tt.mu.Lock()
+			tt.gotConn = time.Now()
+			if info.Conn != nil {
+				tt.remote = info.Conn.RemoteAddr().String()
+			}
+			tt.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&tt.wroteRequest) },
+		GotFirstResponseByte: func() { set(&tt.firstByte) },
+	}
+}
+
+func (tt *traceTimes) remoteIP() string {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	host := tt.remote
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.Trim(host, "[]")
+}
+
+func (tt *traceTimes) timings(start, end time.Time) Timings {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	span := func(a, b time.Time) float64 {
+		if a.IsZero() || b.IsZero() || b.Before(a) {
+			return -1
+		}
+		return ms(b.Sub(a))
+	}
+	t := Timings{
+		DNS:     span(tt.dnsStart, tt.dnsDone),
+		Connect: span(tt.connStart, tt.tlsDone),
+		SSL:     span(tt.tlsStart, tt.tlsDone),
+		Send:    span(tt.gotConn, tt.wroteRequest),
+		Wait:    span(tt.wroteRequest, tt.firstByte),
+		Receive: span(tt.firstByte, end),
+	}
+	if t.Connect < 0 {
+		t.Connect = span(tt.connStart, tt.connDone)
+	}
+	blocked := span(start, tt.gotConn)
+	if blocked >= 0 {
+		for _, phase := range []float64{t.DNS, t.Connect} {
+			if phase > 0 {
+				blocked -= phase
+			}
+		}
+		if blocked < 0 {
+			blocked = 0
+		}
+	}
+	t.Blocked = blocked
+	for _, p := range []*float64{&t.Send, &t.Wait, &t.Receive} {
+		if *p < 0 {
+			*p = 0
+		}
+	}
+	return t
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,109 @@
+package har
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorderCapturesExchange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"echo":"` + string(body) + `"}`))
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder("gru", "test")
+	client := rec.Client(srv.Client())
+	req, _ := http.NewRequestWithContext(WithComment(t.Context(), "users/create.bru"), http.MethodPost, srv.URL+"/users?b=2&a=1", strings.NewReader("hi"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.AddCookie(&http.Cookie{Name: "pref", Value: "dark"})
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(got) != `{"echo":"hi"}` {
+		t.Fatalf("response body not passed through: %q", got)
+	}
+
+	log := rec.Log()
+	if log.Version != "1.2" || log.Creator.Name != "gru" || len(log.Entries) != 1 {
+		t.Fatalf("unexpected log %+v", log)
+	}
+	e := log.Entries[0]
+	if e.Comment != "users/create.bru" || e.Request.Method != http.MethodPost {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if len(e.Request.QueryString) != 2 || e.Request.QueryString[0].Name != "b" {
+		t.Fatalf("query order not preserved: %+v", e.Request.QueryString)
+	}
+	if e.Request.PostData == nil || e.Request.PostData.Text != "hi" || e.Request.BodySize != 2 {
+		t.Fatalf("unexpected post data %+v", e.Request.PostData)
+	}
+	if len(e.Request.Cookies) != 1 || e.Request.Cookies[0].Name != "pref" {
+		t.Fatalf("request cookies %+v", e.Request.Cookies)
+	}
+	if e.Response.Status != http.StatusCreated || e.Response.Content.Text != `{"echo":"hi"}` || e.Response.Content.MimeType != "application/json" {
+		t.Fatalf("unexpected response %+v", e.Response)
+	}
+	if len(e.Response.Cookies) != 1 || !e.Response.Cookies[0].HTTPOnly {
+		t.Fatalf("response cookies %+v", e.Response.Cookies)
+	}
+	if e.Time <= 0 || e.Timings.Wait < 0 || e.ServerIPAddress != "127.0.0.1" {
+		t.Fatalf("timings not captured: time=%v timings=%+v ip=%q", e.Time, e.Timings, e.ServerIPAddress)
+	}
+}
+
+func TestRecorderRecordsTransportErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	rec := NewRecorder("gru", "test")
+	if _, err := rec.Client(nil).Get(url); err == nil {
+		t.Fatalf("expected connection error")
+	}
+	log := rec.Log()
+	if len(log.Entries) != 1 || log.Entries[0].Response.Error == "" {
+		t.Fatalf("expected failed entry, got %+v", log.Entries)
+	}
+}
+
+func TestLogFilterHeaders(t *testing.T) {
+	l := Log{Entries: []Entry{{
+		Request: Request{
+			Headers: []NameValue{{Name: "Authorization", Value: "Bearer x"}, {Name: "Cookie", Value: "s=1"}, {Name: "Accept", Value: "*/*"}},
+			Cookies: []Cookie{{Name: "s", Value: "1"}},
+		},
+		Response: Response{
+			Headers: []NameValue{{Name: "Set-Cookie", Value: "s=2"}},
+			Cookies: []Cookie{{Name: "s", Value: "2"}},
+		},
+	}}}
+	out := l.FilterHeaders(func(name, value string) (string, bool) {
+		switch strings.ToLower(name) {
+		case "authorization":
+			return "***", true
+		case "cookie", "set-cookie":
+			return "", false
+		}
+		return value, true
+	})
+	e := out.Entries[0]
+	if len(e.Request.Headers) != 2 || e.Request.Headers[0].Value != "***" {
+		t.Fatalf("request headers %+v", e.Request.Headers)
+	}
+	if len(e.Request.Cookies) != 0 || len(e.Response.Cookies) != 0 || len(e.Response.Headers) != 0 {
+		t.Fatalf("cookies not dropped: %+v", e)
+	}
+	if l.Entries[0].Request.Headers[0].Value != "Bearer x" {
+		t.Fatalf("filter mutated the source log")
+	}
+}
@@ -0,0 +1,252 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// bruPair is an ordered key/value line inside a .bru block. Disabled pairs are
+// written with Bruno's "~" prefix.
+type bruPair struct {
+	Key      string
+	Value    string
+	Disabled bool
+}
+
+// bruRequest is the importer-side model of a single .bru request file. Fields
+// left empty are omitted from the rendered output.
+type bruRequest struct {
+	Name        string
+	Seq         int
+	Method      string
+	URL         string
+	Headers     []bruPair
+	Query       []bruPair
+	PathParams  []bruPair
+	BodyKind    string // json|xml|text|graphql|form-urlencoded|multipart-form
+	Body        string
+	Form        []bruPair
+	GraphqlVars string
+	VarsPre     []bruPair
+	VarsPost    []bruPair
+	ScriptPre   string
+	ScriptPost  string
+	Tests       string // tests block body (without the surrounding braces)
+	Docs        string
+}
+
+// render produces .bru source. The request block comes first after meta
+// because the parser resets headers when it meets the verb block.
+func (r bruRequest) render() string {
+	var b strings.Builder
+	b.WriteString("meta {\n")
+	fmt.Fprintf(&b, "  name: %s\n", r.Name)
+	b.WriteString("  type: http\n")
+	if r.Seq > 0 {
+		fmt.Fprintf(&b, "  seq: %d\n", r.Seq)
+	}
+	b.WriteString("}\n\n")
+
+	method := strings.ToLower(r.Method)
+	if method == "" {
+		method = "get"
+	}
+	fmt.Fprintf(&b, "%s {\n  url: %s\n", method, r.URL)
+	if r.BodyKind != "" {
+		fmt.Fprintf(&b, "  body: %s\n", r.BodyKind)
+	}
+	b.WriteString("  auth: none\n}\n")
+
+	writePairs(&b, "params:query", r.Query)
+	writePairs(&b, "params:path", r.PathParams)
+	writePairs(&b, "headers", r.Headers)
+
+	switch r.BodyKind {
+	case "":
+	case "form-urlencoded", "multipart-form":
+		writePairs(&b, "body:"+r.BodyKind, r.Form)
+	default:
+		writeText(&b, "body:"+r.BodyKind, r.Body)
+		if r.BodyKind == "graphql" && strings.TrimSpace(r.GraphqlVars) != "" {
+			writeText(&b, "body:graphql:vars", r.GraphqlVars)
+		}
+	}
+
+	writePairs(&b, "vars:pre-request", r.VarsPre)
+	writePairs(&b, "vars:post-response", r.VarsPost)
+	writeText(&b, "script:pre-request", r.ScriptPre)
+	writeText(&b, "script:post-response", r.ScriptPost)
+	writeText(&b, "tests", r.Tests)
+	writeText(&b, "docs", r.Docs)
+	return b.String()
+}
+
+func writePairs(b *strings.Builder, block string, pairs []bruPair) {
+	if len(pairs) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s {\n", block)
+	for _, p := range pairs {
+		prefix := ""
+		if p.Disabled {
+			prefix = "~"
+		}
+		fmt.Fprintf(b, "  %s%s: %s\n", prefix, p.Key, p.Value)
+	}
+	b.WriteString("}\n")
+}
+
+func writeText(b *strings.Builder, block, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(strings.TrimLeft(text, "\r\n"), " \t\r\n"), "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = "  " + l
+		} else {
+			lines[i] = ""
+		}
+	}
+	fmt.Fprintf(b, "\n%s {\n%s\n}\n", block, strings.Join(lines, "\n"))
+}
+
+// baselineTests asserts the observed status code and, when known, the
+// response content type.
+func baselineTests(status int, contentType string) string {
+	var tests []string
+	if status > 0 {
+		tests = append(tests, fmt.Sprintf("test(\"status is %d\", function() {\n  expect(res.status).to.equal(%d);\n});", status, status))
+	} else {
+		tests = append(tests, "test(\"status ok\", function() {\n  expect(res.status).to.be.within(200, 299);\n});")
+	}
+	if mt := mediaTypeOnly(contentType); mt != "" {
+		tests = append(tests, fmt.Sprintf("test(\"content-type is %s\", function() {\n  expect(res.headers[\"content-type\"]).to.contain(%q);\n});", mt, mt))
+	}
+	return strings.Join(tests, "\n\n")
+}
+
+// basicCredential returns the basicAuth env value for user/pass. Templated
+// credentials cannot be encoded at import time, so CHANGEME is returned with
+// ok=false.
+func basicCredential(user, pass string) (value string, ok bool) {
+	if strings.Contains(user+pass, "{{") {
+		return "CHANGEME", false
+	}
+	return base64.StdEncoding.EncodeToString([]byte(user + ":" + pass)), true
+}
+
+func mediaTypeOnly(ct string) string {
+	mt, _, _ := strings.Cut(ct, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// bodyKindForContentType maps a request Content-Type to a .bru body block.
+func bodyKindForContentType(ct string) string {
+	mt := mediaTypeOnly(ct)
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		return "form-urlencoded"
+	case strings.HasPrefix(mt, "multipart/"):
+		return "multipart-form"
+	case mt == "application/graphql":
+		return "graphql"
+	default:
+		return bodyKindFromMediaType(mt)
+	}
+}
+
+// collectionWriter lays out an imported Bruno collection on disk. A writer
+// with an empty dir discards everything, mirroring --output-file-only imports.
+type collectionWriter struct {
+	dir      string
+	existing map[string]int
+	// keep leaves files already on disk alone (adding to a collection).
+	keep bool
+}
+
+func newCollectionWriter(dir, name string) (*collectionWriter, error) {
+	w := &collectionWriter{dir: dir, existing: map[string]int{}}
+	if dir == "" {
+		return w, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bruno.json"), fmt.Appendf(nil, `{"name":%q,"version":"1.0","type":"collection"}`, name), 0o644); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// openCollectionWriter is newCollectionWriter for adding requests to a
+// possibly existing collection: bruno.json and existing files are kept.
+func openCollectionWriter(dir, name string) (*collectionWriter, error) {
+	if dir != "" {
+		if _, err := os.Stat(filepath.Join(dir, "bruno.json")); err == nil {
+			return &collectionWriter{dir: dir, existing: map[string]int{}, keep: true}, nil
+		}
+	}
+	w, err := newCollectionWriter(dir, name)
+	if w != nil {
+		w.keep = true
+	}
+	return w, err
+}
+
+// writeRequest renders r into relDir under a unique file name and returns the
+// collection-relative path.
+func (w *collectionWriter) writeRequest(relDir string, r bruRequest) (string, error) {
+	name := r.Name
+	if name == "" {
+		name = strings.ToUpper(r.Method) + " " + r.URL
+	}
+	filename := filepath.Join(relDir, uniqueFileName(w.existing, relDir, name))
+	for w.keep && w.dir != "" && fileExists(filepath.Join(w.dir, filename)) {
+		filename = filepath.Join(relDir, uniqueFileName(w.existing, relDir, name))
+	}
+	return filename, w.writeFile(filename, r.render())
+}
+
+func (w *collectionWriter) writeFile(relPath, content string) error {
+	if w.dir == "" {
+		return nil
+	}
+	full := filepath.Join(w.dir, relPath)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(full, []byte(content), 0o644)
+}
+
+// writeEnv writes environments/<name>.bru with sorted vars.
+func (w *collectionWriter) writeEnv(name string, vars map[string]string) (string, error) {
+	rel := filepath.Join("environments", name+".bru")
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("  %s: %s", k, vars[k]))
+	}
+	return rel, w.writeFile(rel, "vars {\n"+strings.Join(lines, "\n")+"\n}\n")
+}
+
+// writeImportSummary emits the --output-file JSON summary shared by importers.
+func writeImportSummary(opts Options, name, format string) error {
+	if opts.OutputFile == "" {
+		return nil
+	}
+	return writeJSONFile(opts.OutputFile, map[string]any{
+		"name":   name,
+		"source": opts.Source,
+		"output": opts.OutputDir,
+		"format": format,
+	})
+}
@@ -0,0 +1,568 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"pkt.systems/pslog"
+)
+
+// curlCommand is one parsed curl invocation.
+type curlCommand struct {
+	Method   string
+	URL      string
+	Headers  []bruPair
+	Data     []string
+	Form     []bruPair
+	Get      bool
+	Insecure bool
+	Notes    []string
+}
+
+// curlValueFlags take an argument that gru does not use; they are skipped
+// together with their value.
+var curlValueFlags = map[string]struct{}{
+	"-o": {}, "--output": {}, "-m": {}, "--max-time": {}, "--connect-timeout": {},
+	"--retry": {}, "--retry-delay": {}, "--retry-max-time": {}, "-x": {}, "--proxy": {},
+	"-U": {}, "--proxy-user": {}, "-E": {}, "--cert": {}, "--key": {}, "--cacert": {},
+	"--capath": {}, "--cert-type": {}, "--key-type": {}, "--pass": {}, "-c": {},
+	"--cookie-jar": {}, "-w": {}, "--write-out": {}, "--resolve": {}, "--interface": {},
+	"--limit-rate": {}, "-r": {}, "--range": {}, "-K": {}, "--config": {}, "-D": {},
+	"--dump-header": {}, "--max-redirs": {}, "--ciphers": {}, "--tls-max": {},
+	"--expect100-timeout": {}, "--unix-socket": {}, "--abstract-unix-socket": {},
+	"--local-port": {}, "--keepalive-time": {}, "--variable": {}, "--request-target": {},
+	"-C": {}, "--continue-at": {}, "-Y": {}, "--speed-limit": {}, "-y": {}, "--speed-time": {},
+	"--trace": {}, "--trace-ascii": {}, "--stderr": {}, "--happy-eyeballs-timeout-ms": {},
+}
+
+// ImportCurl converts curl command lines (opts.Curl, or the file/URL in
+// opts.Source) into .bru requests. With an OutputDir the requests are added to
+// that collection (created when missing); otherwise the .bru text is written
+// to opts.Stdout.
+func ImportCurl(ctx context.Context, opts Options) error {
+	text := opts.Curl
+	if strings.TrimSpace(text) == "" {
+		data, err := readSource(ctx, opts.Source, opts.Insecure)
+		if err != nil {
+			return fmt.Errorf("load curl source: %w", err)
+		}
+		text = string(data)
+	}
+	cmds, err := splitCurlCommands(text)
+	if err != nil {
+		return err
+	}
+	if len(cmds) == 0 {
+		return fmt.Errorf("no curl command found")
+	}
+
+	if !opts.GenerateTestsSet {
+		opts.GenerateTests = true
+	}
+
+	log := opts.Logger
+	if log == nil {
+		log = pslog.NewWithOptions(os.Stderr, pslog.Options{Mode: pslog.ModeConsole, MinLevel: pslog.InfoLevel})
+	}
+	log = log.With("fn", pslog.CurrentFn())
+	log.Debug("import.curl.start", "source", opts.Source, "output", opts.OutputDir, "commands", len(cmds))
+
+	collectionName := opts.CollectionName
+	if collectionName == "" {
+		collectionName = "imported-curl"
+	}
+	var w *collectionWriter
+	if opts.OutputDir != "" {
+		if w, err = openCollectionWriter(opts.OutputDir, collectionName); err != nil {
+			return err
+		}
+	}
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	for i, args := range cmds {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cc, err := parseCurlArgs(args)
+		if err != nil {
+			return err
+		}
+		req := cc.bruRequest(log)
+		req.Seq = i + 1
+		if opts.GenerateTests {
+			req.Tests = baselineTests(0, "")
+		}
+		if opts.OutputDir == "" {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprint(stdout, req.render())
+			continue
+		}
+		filename, err := w.writeRequest("", req)
+		if err != nil {
+			return err
+		}
+		log.Info("import.curl.request.write", "method", req.Method, "url", req.URL, "file", filename)
+	}
+	if err := writeImportSummary(opts, collectionName, "bruno"); err != nil {
+		return err
+	}
+	log.Debug("import.curl.done", "output", opts.OutputDir, "requests", len(cmds))
+	return nil
+}
+
+// splitCurlCommands tokenizes shell text (quotes, $'...', backslash line
+// continuations, comments) into one argument list per curl invocation.
+// Commands end at unquoted newlines, ';', '&&' and '|'.
+func splitCurlCommands(text string) ([][]string, error) {
+	var (
+		cmds    [][]string
+		args    []string
+		cur     strings.Builder
+		inToken bool
+	)
+	flushToken := func() {
+		if inToken {
+			args = append(args, cur.String())
+			cur.Reset()
+			inToken = false
+		}
+	}
+	flushCmd := func() {
+		flushToken()
+		if len(args) > 0 {
+			cmds = append(cmds, args)
+			args = nil
+		}
+	}
+	rs := []rune(text)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch {
+		case c == '\\' && i+1 < len(rs) && (rs[i+1] == '\n' || rs[i+1] == '\r'):
+			// line continuation
+			i++
+			if rs[i] == '\r' && i+1 < len(rs) && rs[i+1] == '\n' {
+				i++
+			}
+		case c == '\\' && i+1 < len(rs):
+			i++
+			cur.WriteRune(rs[i])
+			inToken = true
+		case c == '\'':
+			end := indexRune(rs, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("parse curl: unterminated single quote")
+			}
+			cur.WriteString(string(rs[i+1 : end]))
+			inToken = true
+			i = end
+		case c == '$' && i+1 < len(rs) && rs[i+1] == '\'':
+			s, next, err := ansiCQuoted(rs, i+2)
+			if err != nil {
+				return nil, err
+			}
+			cur.WriteString(s)
+			inToken = true
+			i = next
+		case c == '"':
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '"'; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) && strings.ContainsRune("\"\\$`\n", rs[j+1]) {
+					j++
+					if rs[j] != '\n' {
+						cur.WriteRune(rs[j])
+					}
+					continue
+				}
+				cur.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("parse curl: unterminated double quote")
+			}
+			inToken = true
+			i = j
+		case c == '#' && !inToken:
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			flushCmd()
+		case c == '\n' || c == ';' || c == '|':
+			flushCmd()
+		case c == '&' && i+1 < len(rs) && rs[i+1] == '&':
+			i++
+			flushCmd()
+		case c == ' ' || c == '\t' || c == '\r':
+			flushToken()
+		default:
+			cur.WriteRune(c)
+			inToken = true
+		}
+	}
+	flushCmd()
+
+	var out [][]string
+	for _, cmd := range cmds {
+		if len(cmd) > 0 && (cmd[0] == "curl" || strings.HasSuffix(cmd[0], "/curl") || cmd[0] == "curl.exe") {
+			out = append(out, cmd[1:])
+		}
+	}
+	return out, nil
+}
+
+func indexRune(rs []rune, from int, r rune) int {
+	for i := from; i < len(rs); i++ {
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// ansiCQuoted decodes a bash $'...' string starting after the opening quote
+// and returns the decoded text and the index of the closing quote.
+func ansiCQuoted(rs []rune, i int) (string, int, error) {
+	var b bytes.Buffer
+	for ; i < len(rs); i++ {
+		c := rs[i]
+		if c == '\'' {
+			return b.String(), i, nil
+		}
+		if c != '\\' || i+1 >= len(rs) {
+			b.WriteString(string(c))
+			continue
+		}
+		i++
+		switch rs[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\', '\'', '"', '?':
+			b.WriteString(string(rs[i]))
+		case 'x':
+			n, used := 0, 0
+			for used < 2 && i+1 < len(rs) && isHex(rs[i+1]) {
+				i++
+				n = n*16 + hexVal(rs[i])
+				used++
+			}
+			b.WriteByte(byte(n))
+		case 'u', 'U':
+			maxDigits := 4
+			if rs[i] == 'U' {
+				maxDigits = 8
+			}
+			n, used := 0, 0
+			for used < maxDigits && i+1 < len(rs) && isHex(rs[i+1]) {
+				i++
+				n = n*16 + hexVal(rs[i])
+				used++
+			}
+			b.WriteString(string(rune(n)))
+		default:
+			b.WriteByte('\\')
+			b.WriteString(string(rs[i]))
+		}
+	}
+	return "", 0, fmt.Errorf("parse curl: unterminated $'...' string")
+}
+
+func isHex(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func hexVal(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'a' && r <= 'f':
+		return int(r-'a') + 10
+	default:
+		return int(r-'A') + 10
+	}
+}
+
+// parseCurlArgs interprets curl options (without the leading "curl").
+func parseCurlArgs(args []string) (curlCommand, error) {
+	var cc curlCommand
+	var head bool
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if cc.URL == "" {
+				cc.URL = arg
+			}
+			continue
+		}
+		name, value, hasValue := arg, "", false
+		if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			// bundled short options: -sSL, -XPOST, -kv
+			for j := 1; j < len(arg); j++ {
+				opt := "-" + string(arg[j])
+				if curlTakesValue(opt) {
+					name, value, hasValue = opt, arg[j+1:], j+1 < len(arg)
+					break
+				}
+				cc.flag(opt, &head)
+				name = ""
+			}
+			if name == "" {
+				continue
+			}
+		}
+		if curlTakesValue(name) && !hasValue {
+			if i+1 >= len(args) {
+				return cc, fmt.Errorf("parse curl: %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if err := cc.option(name, value, &head); err != nil {
+			return cc, err
+		}
+	}
+	if cc.URL == "" {
+		return cc, fmt.Errorf("parse curl: no URL")
+	}
+	if !strings.Contains(cc.URL, "://") {
+		cc.URL = "http://" + cc.URL
+	}
+	switch {
+	case cc.Method != "":
+	case head:
+		cc.Method = "HEAD"
+	case len(cc.Data) > 0 && !cc.Get, len(cc.Form) > 0:
+		cc.Method = "POST"
+	default:
+		cc.Method = "GET"
+	}
+	if cc.Get && len(cc.Data) > 0 {
+		sep := "?"
+		if strings.Contains(cc.URL, "?") {
+			sep = "&"
+		}
+		cc.URL += sep + strings.Join(cc.Data, "&")
+		cc.Data = nil
+	}
+	return cc, nil
+}
+
+func curlTakesValue(name string) bool {
+	switch name {
+	case "-X", "--request", "-H", "--header", "-d", "--data", "--data-raw", "--data-binary",
+		"--data-ascii", "--data-urlencode", "--json", "-F", "--form", "--form-string", "-u", "--user",
+		"-A", "--user-agent", "-e", "--referer", "-b", "--cookie", "--url", "--oauth2-bearer",
+		"-T", "--upload-file", "--url-query":
+		return true
+	}
+	_, ok := curlValueFlags[name]
+	return ok
+}
+
+// flag handles value-less options.
+func (cc *curlCommand) flag(name string, head *bool) {
+	switch name {
+	case "-G", "--get":
+		cc.Get = true
+	case "-I", "--head":
+		*head = true
+	case "-k", "--insecure":
+		cc.Insecure = true
+	}
+	// Everything else (-s, -L, -v, --compressed, ...) does not change the
+	// request; Go negotiates gzip itself, so --compressed needs no header.
+}
+
+func (cc *curlCommand) option(name, value string, head *bool) error {
+	switch name {
+	case "-X", "--request":
+		cc.Method = strings.ToUpper(value)
+	case "--url":
+		cc.URL = value
+	case "-H", "--header":
+		k, v, ok := strings.Cut(value, ":")
+		if !ok {
+			// "Name;" sends an empty header; anything else is malformed.
+			if k, found := strings.CutSuffix(value, ";"); found {
+				cc.Headers = append(cc.Headers, bruPair{Key: strings.TrimSpace(k)})
+			}
+			return nil
+		}
+		if v = strings.TrimSpace(v); v == "" {
+			return nil // "Name:" removes a header in curl
+		}
+		cc.Headers = append(cc.Headers, bruPair{Key: strings.TrimSpace(k), Value: v})
+	case "-d", "--data", "--data-ascii", "--data-binary":
+		if strings.HasPrefix(value, "@") {
+			cc.Notes = append(cc.Notes, "TODO(gru): request body was read from "+value[1:]+" by curl; paste its content into the body block.")
+		}
+		if name != "--data-binary" {
+			value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		}
+		cc.Data = append(cc.Data, value)
+	case "--data-raw":
+		cc.Data = append(cc.Data, value)
+	case "--json":
+		cc.Data = append(cc.Data, value)
+		if headerValue(cc.Headers, "Content-Type") == "" {
+			cc.Headers = append(cc.Headers, bruPair{Key: "Content-Type", Value: "application/json"})
+		}
+		if headerValue(cc.Headers, "Accept") == "" {
+			cc.Headers = append(cc.Headers, bruPair{Key: "Accept", Value: "application/json"})
+		}
+	case "--data-urlencode":
+		cc.Data = append(cc.Data, curlURLEncode(value))
+	case "--url-query":
+		sep := "?"
+		if strings.Contains(cc.URL, "?") {
+			sep = "&"
+		}
+		cc.URL += sep + curlURLEncode(value)
+	case "-F", "--form", "--form-string":
+		k, v, _ := strings.Cut(value, "=")
+		// "@path;type=..." already matches the .bru multipart file syntax.
+		if name != "--form-string" && strings.HasPrefix(v, "<") {
+			cc.Notes = append(cc.Notes, "TODO(gru): form field "+k+" was read from "+v[1:]+" by curl.")
+		}
+		cc.Form = append(cc.Form, bruPair{Key: k, Value: v})
+	case "-u", "--user":
+		cc.Headers = append(cc.Headers, bruPair{Key: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(value))})
+	case "--oauth2-bearer":
+		cc.Headers = append(cc.Headers, bruPair{Key: "Authorization", Value: "Bearer " + value})
+	case "-A", "--user-agent":
+		cc.Headers = append(cc.Headers, bruPair{Key: "User-Agent", Value: value})
+	case "-e", "--referer":
+		cc.Headers = append(cc.Headers, bruPair{Key: "Referer", Value: value})
+	case "-b", "--cookie":
+		if !strings.Contains(value, "=") {
+			cc.Notes = append(cc.Notes, "TODO(gru): cookies were read from file "+value+" by curl.")
+			return nil
+		}
+		cc.Headers = append(cc.Headers, bruPair{Key: "Cookie", Value: value})
+	case "-T", "--upload-file":
+		if cc.Method == "" {
+			cc.Method = "PUT"
+		}
+		cc.Notes = append(cc.Notes, "TODO(gru): curl uploaded the file "+value+" as the request body.")
+	default:
+		cc.flag(name, head)
+	}
+	return nil
+}
+
+// curlURLEncode mirrors --data-urlencode: "name=content" encodes content,
+// "=content" and "content" encode everything.
+func curlURLEncode(v string) string {
+	if name, content, ok := strings.Cut(v, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content)
+		}
+		return name + "=" + url.QueryEscape(content)
+	}
+	return url.QueryEscape(v)
+}
+
+// bruRequest maps the curl invocation onto a .bru request, choosing the body
+// block from the Content-Type (curl's default for -d is form-urlencoded).
+func (cc curlCommand) bruRequest(log pslog.Logger) bruRequest {
+	req := bruRequest{Method: cc.Method, URL: cc.URL}
+	if u, err := url.Parse(cc.URL); err == nil {
+		req.Name = strings.TrimSpace(cc.Method + " " + u.Path)
+		req.Query = queryPairs(u.RawQuery)
+	}
+
+	ct := headerValue(cc.Headers, "Content-Type")
+	switch {
+	case len(cc.Form) > 0:
+		req.BodyKind = "multipart-form"
+		req.Form = cc.Form
+		ct = "" // the runner sets the boundary
+	case len(cc.Data) > 0:
+		body := strings.Join(cc.Data, "&")
+		kind := bodyKindForContentType(ct)
+		if ct == "" {
+			kind = "form-urlencoded"
+			if json.Valid([]byte(body)) && strings.ContainsAny(body[:1], "{[") {
+				kind = "json"
+				log.Info("import.curl.body.json", "url", cc.URL, "hint", "curl would send this JSON as application/x-www-form-urlencoded; imported as body:json")
+			}
+		}
+		req.BodyKind = kind
+		switch kind {
+		case "form-urlencoded":
+			for _, kv := range strings.Split(body, "&") {
+				if kv == "" {
+					continue
+				}
+				k, v, _ := strings.Cut(kv, "=")
+				if uv, err := url.QueryUnescape(strings.ReplaceAll(v, "+", " ")); err == nil {
+					v = uv
+				}
+				if uk, err := url.QueryUnescape(strings.ReplaceAll(k, "+", " ")); err == nil {
+					k = uk
+				}
+				req.Form = append(req.Form, bruPair{Key: k, Value: v})
+			}
+			ct = ""
+		case "json":
+			var buf bytes.Buffer
+			if json.Indent(&buf, []byte(body), "", "  ") == nil {
+				body = buf.String()
+			}
+			req.Body = body
+		default:
+			req.Body = body
+		}
+	}
+	for _, h := range cc.Headers {
+		if strings.EqualFold(h.Key, "Content-Type") && ct == "" {
+			continue
+		}
+		if strings.EqualFold(h.Key, "Content-Length") || strings.EqualFold(h.Key, "Host") {
+			continue
+		}
+		req.Headers = append(req.Headers, h)
+	}
+	notes := cc.Notes
+	if cc.Insecure {
+		notes = append(notes, "curl ran with -k; run with gru run --insecure.")
+	}
+	for _, n := range notes {
+		log.Warn("import.curl.note", "url", cc.URL, "note", n)
+	}
+	req.Docs = strings.Join(notes, "\n")
+	return req
+}
+
+// queryPairs lists the parameters of a raw query string in order, unescaped
+// where possible, for a params:query block.
+func queryPairs(rawQuery string) []bruPair {
+	var pairs []bruPair
+	for _, kv := range strings.Split(rawQuery, "&") {
+		if kv == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(kv, "=")
+		if uk, err := url.QueryUnescape(k); err == nil {
+			k = uk
+		}
+		if uv, err := url.QueryUnescape(v); err == nil {
+			v = uv
+		}
+		pairs = append(pairs, bruPair{Key: k, Value: v})
+	}
+	return pairs
+}
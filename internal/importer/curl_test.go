@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+)
+
+func TestImportCurlBodiesAndFlags(t *testing.T) {
+	cmds := `# captured from the browser
+curl 'https://api.test/users?limit=10&q=a%20b' \
+  -XPOST -H 'Content-Type: application/json' -H "X-Trace: $'no'" \
+  --data-raw '{"name":"ada"}' -u bob:secret --compressed -k
+curl -sS https://api.test/form -d a=1 -d 'b=x+y'
+curl https://api.test/upload -F 'file=@./a.png;type=image/png' -F note=hi
+curl -G https://api.test/search -d q=go --data-urlencode 'tag=a&b'
+curl -I $'https://api.test/\x68ead'
+curl -X DELETE https://api.test/users/1 -H 'Accept;' -b 'sid=1; theme=dark'
+`
+	var out bytes.Buffer
+	err := ImportCurl(context.Background(), Options{Curl: cmds, Stdout: &out, GenerateTests: false, GenerateTestsSet: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"name: POST /users",
+		"url: https://api.test/users?limit=10&q=a%20b",
+		"  q: a b\n",
+		"  Authorization: Basic Ym9iOnNlY3JldA==\n",
+		"  X-Trace: $'no'\n",
+		"body:json {\n  {\n    \"name\": \"ada\"\n  }\n}",
+		"curl ran with -k",
+		"body:form-urlencoded {\n  a: 1\n  b: x y\n}",
+		"body:multipart-form {\n  file: @./a.png;type=image/png\n  note: hi\n}",
+		"url: https://api.test/search?q=go&tag=a%26b",
+		"  tag: a&b\n",
+		"head {\n  url: https://api.test/head",
+		"delete {\n  url: https://api.test/users/1",
+		"  Accept: \n",
+		"  Cookie: sid=1; theme=dark\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "meta {") != 6 {
+		t.Fatalf("expected 6 requests, got:\n%s", got)
+	}
+	if strings.Contains(got, "multipart/form-data") || strings.Contains(got, "tests {") {
+		t.Fatalf("unexpected content:\n%s", got)
+	}
+}
+
+func TestImportCurlWritesCollectionAndRuns(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-Api-Key") != "k1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "coll")
+	cmd := "curl -X PUT " + srv.URL + "/items/7 -H 'X-Api-Key: k1' -H 'Content-Type: application/json' -d '{\"done\":true}'"
+	for range 2 {
+		if err := ImportCurl(context.Background(), Options{Curl: cmd, OutputDir: out}); err != nil {
+			t.Fatalf("import: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bruFiles []string
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".bru") {
+			bruFiles = append(bruFiles, e.Name())
+		}
+	}
+	if len(bruFiles) != 2 {
+		t.Fatalf("second import should add a new file next to the first, got %v", bruFiles)
+	}
+
+	g, err := runner.New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.RunFile(context.Background(), filepath.Join(out, bruFiles[0]), runner.RunOptions{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !res.Passed || res.Status != http.StatusNoContent {
+		t.Fatalf("run failed: status=%d failures=%+v err=%s", res.Status, res.Failures, res.ErrorText)
+	}
+	if gotBody["done"] != true {
+		t.Fatalf("body not sent: %v", gotBody)
+	}
+}
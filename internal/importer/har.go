@@ -0,0 +1,345 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"pkt.systems/gruno/internal/har"
+	"pkt.systems/pslog"
+)
+
+// harDroppedHeaders are browser/transport headers that make no sense to replay.
+var harDroppedHeaders = map[string]struct{}{
+	"host":              {},
+	"connection":        {},
+	"content-length":    {},
+	"accept-encoding":   {},
+	"keep-alive":        {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+	"te":                {},
+	"priority":          {},
+}
+
+// ImportHAR converts captured browser traffic (HAR 1.2) into a Bruno
+// collection: one request per unique entry, origins and credentials lifted
+// into environments/local.bru, and baseline status/content-type tests.
+func ImportHAR(ctx context.Context, opts Options) error {
+	data, err := readSource(ctx, opts.Source, opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("load har source: %w", err)
+	}
+	var doc har.File
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse har: %w", err)
+	}
+
+	if !opts.GenerateTestsSet {
+		opts.GenerateTests = true
+	}
+
+	log := opts.Logger
+	if log == nil {
+		log = pslog.NewWithOptions(os.Stdout, pslog.Options{Mode: pslog.ModeConsole, MinLevel: pslog.InfoLevel})
+	}
+	log = log.With("fn", pslog.CurrentFn())
+	log.Info("import.har.start", "source", opts.Source, "output", opts.OutputDir, "entries", len(doc.Log.Entries))
+
+	collectionName := opts.CollectionName
+	if collectionName == "" {
+		collectionName = "imported-har"
+	}
+	w, err := newCollectionWriter(opts.OutputDir, collectionName)
+	if err != nil {
+		return err
+	}
+
+	entries := filterHAREntries(doc.Log.Entries, opts, log)
+	origins := harOrigins(entries)
+	secrets := &harSecrets{vars: map[string]string{}, byValue: map[string]string{}}
+	envVars := map[string]string{}
+	for origin, name := range origins {
+		envVars[name] = origin
+	}
+
+	for i, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		u, _ := url.Parse(e.Request.URL)
+		origin := u.Scheme + "://" + u.Host
+		query, repeated := harQuery(e.Request, u, secrets)
+		rawURL := "{{" + origins[origin] + "}}" + u.EscapedPath()
+		if repeated != "" {
+			rawURL += "?" + repeated
+		}
+		req := bruRequest{
+			Name:   strings.TrimSpace(strings.ToUpper(e.Request.Method) + " " + u.EscapedPath()),
+			Seq:    i + 1,
+			Method: e.Request.Method,
+			URL:    rawURL,
+			Query:  query,
+		}
+		pd := e.Request.PostData
+		if pd != nil && (pd.Text != "" || len(pd.Params) > 0) {
+			req.BodyKind = bodyKindForContentType(pd.MimeType)
+		}
+		for _, h := range e.Request.Headers {
+			lname := strings.ToLower(h.Name)
+			if _, drop := harDroppedHeaders[lname]; drop || strings.HasPrefix(lname, ":") || strings.HasPrefix(lname, "sec-") {
+				continue
+			}
+			// The runner encodes form bodies itself; a captured Content-Type
+			// would carry the browser's multipart boundary.
+			if lname == "content-type" && (req.BodyKind == "form-urlencoded" || req.BodyKind == "multipart-form") {
+				continue
+			}
+			req.Headers = append(req.Headers, bruPair{Key: h.Name, Value: secrets.template(lname, h.Value)})
+		}
+		if req.BodyKind != "" {
+			switch req.BodyKind {
+			case "form-urlencoded", "multipart-form":
+				params := pd.Params
+				if len(params) == 0 && req.BodyKind == "form-urlencoded" {
+					if vals, err := url.ParseQuery(pd.Text); err == nil {
+						for k, vs := range vals {
+							for _, v := range vs {
+								params = append(params, har.NameValue{Name: k, Value: v})
+							}
+						}
+						slices.SortFunc(params, func(a, b har.NameValue) int { return strings.Compare(a.Name, b.Name) })
+					}
+				}
+				for _, p := range params {
+					req.Form = append(req.Form, bruPair{Key: p.Name, Value: p.Value})
+				}
+			case "json":
+				var buf bytes.Buffer
+				if json.Indent(&buf, []byte(pd.Text), "", "  ") == nil {
+					req.Body = buf.String()
+				} else {
+					req.Body = pd.Text
+				}
+			default:
+				req.Body = pd.Text
+			}
+		}
+		if opts.GenerateTests {
+			req.Tests = baselineTests(e.Response.Status, e.Response.Content.MimeType)
+		}
+
+		relDir := ""
+		if len(origins) > 1 {
+			relDir = sanitizeFileName(u.Host)
+		}
+		filename, err := w.writeRequest(relDir, req)
+		if err != nil {
+			return err
+		}
+		log.Info("import.har.entry.write", "method", req.Method, "url", e.Request.URL, "status", e.Response.Status, "file", filename)
+	}
+
+	for k, v := range secrets.vars {
+		envVars[k] = v
+	}
+	if err := writeImportSummary(opts, collectionName, "bruno"); err != nil {
+		return err
+	}
+	if opts.OutputDir != "" {
+		envPath, err := w.writeEnv("local", envVars)
+		if err != nil {
+			return err
+		}
+		log.Debug("import.har.env.write", "path", envPath, "vars", len(envVars))
+	}
+	log.Info("import.har.done", "output", opts.OutputDir, "requests", len(entries))
+	return nil
+}
+
+// filterHAREntries applies host/method/content-type filters and drops
+// failed and duplicate (same method, URL and body) entries.
+func filterHAREntries(entries []har.Entry, opts Options, log pslog.Logger) []har.Entry {
+	seen := map[string]struct{}{}
+	var out []har.Entry
+	for _, e := range entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			log.Debug("import.har.entry.skip", "reason", "url", "url", e.Request.URL)
+			continue
+		}
+		if e.Response.Status == 0 {
+			log.Debug("import.har.entry.skip", "reason", "no-response", "url", e.Request.URL)
+			continue
+		}
+		if !matchesHost(u.Hostname(), opts.IncludeHosts) {
+			log.Debug("import.har.entry.skip", "reason", "host", "url", e.Request.URL)
+			continue
+		}
+		if len(opts.IncludeMethods) > 0 && !slices.ContainsFunc(opts.IncludeMethods, func(m string) bool { return strings.EqualFold(strings.TrimSpace(m), e.Request.Method) }) {
+			log.Debug("import.har.entry.skip", "reason", "method", "url", e.Request.URL, "method", e.Request.Method)
+			continue
+		}
+		if len(opts.IncludeContentTypes) > 0 {
+			mt := mediaTypeOnly(e.Response.Content.MimeType)
+			if !slices.ContainsFunc(opts.IncludeContentTypes, func(ct string) bool {
+				ct = strings.ToLower(strings.TrimSpace(ct))
+				return ct != "" && strings.Contains(mt, ct)
+			}) {
+				log.Debug("import.har.entry.skip", "reason", "content-type", "url", e.Request.URL, "ct", mt)
+				continue
+			}
+		}
+		key := strings.ToUpper(e.Request.Method) + " " + e.Request.URL
+		if e.Request.PostData != nil {
+			key += "\n" + e.Request.PostData.Text
+		}
+		if _, dup := seen[key]; dup {
+			log.Debug("import.har.entry.duplicate", "method", e.Request.Method, "url", e.Request.URL)
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, e)
+	}
+	return out
+}
+
+// matchesHost accepts exact hosts and subdomains of the listed hosts.
+func matchesHost(host string, hosts []string) bool {
+	if len(hosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(h), "."))
+		if h != "" && (host == h || strings.HasSuffix(host, "."+h)) {
+			return true
+		}
+	}
+	return false
+}
+
+// harOrigins maps each origin to an env var: the first seen becomes baseUrl,
+// the rest are named after their host.
+func harOrigins(entries []har.Entry) map[string]string {
+	out := map[string]string{}
+	for _, e := range entries {
+		u, _ := url.Parse(e.Request.URL)
+		origin := u.Scheme + "://" + u.Host
+		if _, ok := out[origin]; ok {
+			continue
+		}
+		if len(out) == 0 {
+			out[origin] = "baseUrl"
+			continue
+		}
+		out[origin] = toVarName(u.Hostname()) + "Url"
+	}
+	return out
+}
+
+// harQuery splits the captured query into params:query pairs and a raw query
+// string for keys that repeat. The runner holds params in a map, so repeated
+// keys stay in the URL to keep every value; all other keys move to params so
+// they are not sent twice. Credential-like values become env var references.
+func harQuery(r har.Request, u *url.URL, secrets *harSecrets) ([]bruPair, string) {
+	pairs := r.QueryString
+	if len(pairs) == 0 && u.RawQuery != "" {
+		vals, _ := url.ParseQuery(u.RawQuery)
+		for k, vs := range vals {
+			for _, v := range vs {
+				pairs = append(pairs, har.NameValue{Name: k, Value: v})
+			}
+		}
+		slices.SortStableFunc(pairs, func(a, b har.NameValue) int { return strings.Compare(a.Name, b.Name) })
+	}
+	counts := map[string]int{}
+	for _, q := range pairs {
+		counts[q.Name]++
+	}
+	var params []bruPair
+	var repeated []string
+	for _, q := range pairs {
+		value := secrets.queryValue(q.Name, q.Value)
+		if counts[q.Name] > 1 {
+			repeated = append(repeated, url.QueryEscape(q.Name)+"="+escapeTemplatedQuery(value))
+			continue
+		}
+		params = append(params, bruPair{Key: q.Name, Value: value})
+	}
+	return params, strings.Join(repeated, "&")
+}
+
+// escapeTemplatedQuery query-escapes a value but leaves a whole {{var}}
+// reference intact so the runner can still expand it.
+func escapeTemplatedQuery(v string) string {
+	if strings.HasPrefix(v, "{{") && strings.HasSuffix(v, "}}") {
+		return v
+	}
+	return url.QueryEscape(v)
+}
+
+// harSecrets replaces credential header values with env var references,
+// reusing one variable per distinct value.
+type harSecrets struct {
+	vars    map[string]string
+	byValue map[string]string
+}
+
+func (s *harSecrets) template(lname, value string) string {
+	switch lname {
+	case "authorization", "proxy-authorization":
+		scheme, cred, ok := strings.Cut(value, " ")
+		if !ok {
+			return s.ref("authToken", value)
+		}
+		base := "authToken"
+		if strings.EqualFold(scheme, "basic") {
+			base = "basicAuth"
+		}
+		return scheme + " " + s.ref(base, cred)
+	case "x-api-key", "api-key", "apikey", "x-auth-token":
+		return s.ref("apiKey", value)
+	case "cookie":
+		return s.ref("cookie", value)
+	}
+	return value
+}
+
+// queryValue templates credential-like query parameters (tokens, API keys,
+// signatures) the same way template does for headers.
+func (s *harSecrets) queryValue(name, value string) string {
+	if value == "" {
+		return value
+	}
+	switch strings.ToLower(strings.NewReplacer("-", "_").Replace(name)) {
+	case "access_token", "token", "id_token", "auth", "auth_token", "bearer":
+		return s.ref("authToken", value)
+	case "api_key", "apikey", "key", "x_api_key":
+		return s.ref("apiKey", value)
+	case "password", "passwd", "secret", "client_secret", "sig", "signature":
+		return s.ref("secret", value)
+	}
+	return value
+}
+
+func (s *harSecrets) ref(base, value string) string {
+	if name, ok := s.byValue[value]; ok {
+		return "{{" + name + "}}"
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := s.vars[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	s.vars[name] = value
+	s.byValue[value] = name
+	return "{{" + name + "}}"
+}
@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/har"
+	"pkt.systems/gruno/internal/runner"
+)
+
+func TestImportHARFiltersDedupesAndRuns(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(body)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	}))
+	defer srv.Close()
+
+	auth := []har.NameValue{{Name: "Authorization", Value: "Bearer tok123"}, {Name: "sec-ch-ua", Value: "x"}, {Name: ":authority", Value: "x"}}
+	entry := func(method, u string, status int, mime string, body *har.PostData) har.Entry {
+		return har.Entry{
+			Request:  har.Request{Method: method, URL: u, Headers: auth, PostData: body},
+			Response: har.Response{Status: status, Content: har.Content{MimeType: mime}},
+		}
+	}
+	doc := har.File{Log: har.Log{Version: "1.2", Entries: []har.Entry{
+		entry("GET", srv.URL+"/users", 200, "application/json; charset=utf-8", nil),
+		entry("GET", srv.URL+"/users", 200, "application/json; charset=utf-8", nil),
+		entry("POST", srv.URL+"/users", 201, "application/json", &har.PostData{MimeType: "application/json", Text: `{"name":"ada"}`}),
+		entry("OPTIONS", srv.URL+"/users", 204, "", nil),
+		entry("GET", srv.URL+"/logo.png", 200, "image/png", nil),
+		entry("GET", "https://cdn.other.test/app.js", 200, "application/json", nil),
+	}}}
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "capture.har")
+	data, _ := json.Marshal(doc)
+	if err := os.WriteFile(src, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(tmp, "out")
+	err := ImportHAR(context.Background(), Options{
+		Source:              src,
+		OutputDir:           out,
+		IncludeHosts:        []string{"127.0.0.1"},
+		IncludeMethods:      []string{"GET", "POST"},
+		IncludeContentTypes: []string{"json"},
+	})
+	if err != nil {
+		t.Fatalf("import har: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(out, "*.bru"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 requests after filtering/dedupe, got %v", files)
+	}
+	get, err := os.ReadFile(filepath.Join(out, "GET__users.bru"))
+	if err != nil {
+		t.Fatalf("read get: %v", err)
+	}
+	for _, want := range []string{"url: {{baseUrl}}/users", "Authorization: Bearer {{authToken}}", `expect(res.status).to.equal(200)`, `to.contain("application/json")`} {
+		if !strings.Contains(string(get), want) {
+			t.Fatalf("GET request missing %q:\n%s", want, get)
+		}
+	}
+	if strings.Contains(string(get), "sec-ch-ua") || strings.Contains(string(get), ":authority") {
+		t.Fatalf("browser headers not dropped:\n%s", get)
+	}
+	env, _ := os.ReadFile(filepath.Join(out, "environments", "local.bru"))
+	if !strings.Contains(string(env), "authToken: tok123") || !strings.Contains(string(env), "baseUrl: "+srv.URL) {
+		t.Fatalf("env missing extracted vars:\n%s", env)
+	}
+
+	g, err := runner.New(context.Background())
+	if err != nil {
+		t.Fatalf("runner: %v", err)
+	}
+	sum, err := g.RunFolder(context.Background(), out, runner.RunOptions{EnvPath: filepath.Join(out, "environments", "local.bru")})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Total != 2 || sum.Failed != 0 {
+		t.Fatalf("expected imported collection to pass, got %+v", sum)
+	}
+}
+
+func TestImportHARFormBodiesAndQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search":
+			if got := r.URL.Query()["id"]; len(got) != 2 || got[0] != "1" || got[1] != "2" {
+				http.Error(w, "ids "+strings.Join(got, ","), http.StatusBadRequest)
+				return
+			}
+			if r.URL.Query().Get("access_token") != "tok123" || r.URL.Query().Get("q") != "go" {
+				http.Error(w, "query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+		case "/login":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("user") != "ada" {
+				http.Error(w, "form", http.StatusBadRequest)
+				return
+			}
+		case "/upload":
+			if err := r.ParseMultipartForm(1 << 20); err != nil || r.FormValue("name") != "ada" {
+				http.Error(w, fmt.Sprintf("multipart: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	entry := func(method, u, ct string, body *har.PostData) har.Entry {
+		var headers []har.NameValue
+		if ct != "" {
+			headers = append(headers, har.NameValue{Name: "content-type", Value: ct})
+		}
+		return har.Entry{
+			Request:  har.Request{Method: method, URL: u, Headers: headers, PostData: body},
+			Response: har.Response{Status: 200, Content: har.Content{MimeType: "application/json"}},
+		}
+	}
+	search := entry("GET", srv.URL+"/search?id=1&id=2&q=go&access_token=tok123", "", nil)
+	search.Request.QueryString = []har.NameValue{{Name: "id", Value: "1"}, {Name: "id", Value: "2"}, {Name: "q", Value: "go"}, {Name: "access_token", Value: "tok123"}}
+	doc := har.File{Log: har.Log{Version: "1.2", Entries: []har.Entry{
+		search,
+		entry("POST", srv.URL+"/login", "application/x-www-form-urlencoded", &har.PostData{MimeType: "application/x-www-form-urlencoded", Text: "user=ada"}),
+		entry("POST", srv.URL+"/upload", "multipart/form-data; boundary=----WebKitFormBoundaryStale", &har.PostData{MimeType: "multipart/form-data; boundary=----WebKitFormBoundaryStale", Params: []har.NameValue{{Name: "name", Value: "ada"}}}),
+	}}}
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "capture.har")
+	data, _ := json.Marshal(doc)
+	if err := os.WriteFile(src, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(tmp, "out")
+	if err := ImportHAR(context.Background(), Options{Source: src, OutputDir: out}); err != nil {
+		t.Fatalf("import har: %v", err)
+	}
+
+	get, _ := os.ReadFile(filepath.Join(out, "GET__search.bru"))
+	for _, want := range []string{"url: {{baseUrl}}/search?id=1&id=2", "q: go", "access_token: {{authToken}}"} {
+		if !strings.Contains(string(get), want) {
+			t.Fatalf("GET request missing %q:\n%s", want, get)
+		}
+	}
+	if strings.Contains(string(get), "tok123") {
+		t.Fatalf("query token leaked into request:\n%s", get)
+	}
+	upload, _ := os.ReadFile(filepath.Join(out, "POST__upload.bru"))
+	if strings.Contains(string(upload), "headers {") || strings.Contains(string(upload), "WebKitFormBoundary") {
+		t.Fatalf("captured multipart content-type kept:\n%s", upload)
+	}
+
+	g, err := runner.New(context.Background())
+	if err != nil {
+		t.Fatalf("runner: %v", err)
+	}
+	sum, err := g.RunFolder(context.Background(), out, runner.RunOptions{EnvPath: filepath.Join(out, "environments", "local.bru")})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Total != 3 || sum.Failed != 0 {
+		for _, c := range sum.Cases {
+			t.Logf("%s: %s", c.Name, c.ErrorText)
+		}
+		t.Fatalf("expected imported collection to pass, got %+v", sum)
+	}
+}
@@ -0,0 +1,228 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkt.systems/gruno/internal/parser"
+	"pkt.systems/pslog"
+)
+
+// ImportHTTP converts a VS Code REST Client / JetBrains HTTP Client .http file
+// into a Bruno collection. File variables a request uses become its
+// vars:pre-request, response references become vars:post-response on the
+// producing request, and http-client.env.json environments next to the
+// source become environments/<name>.bru.
+func ImportHTTP(ctx context.Context, opts Options) error {
+	data, err := readSource(ctx, opts.Source, opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("load http source: %w", err)
+	}
+	hf, err := parser.ParseHTTP(ctx, opts.Source, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parse http file: %w", err)
+	}
+	if len(hf.Requests) == 0 {
+		return fmt.Errorf("no requests found in %s", opts.Source)
+	}
+
+	if !opts.GenerateTestsSet {
+		opts.GenerateTests = true
+	}
+
+	log := opts.Logger
+	if log == nil {
+		log = pslog.NewWithOptions(os.Stdout, pslog.Options{Mode: pslog.ModeConsole, MinLevel: pslog.InfoLevel})
+	}
+	log = log.With("fn", pslog.CurrentFn())
+	log.Info("import.http.start", "source", opts.Source, "output", opts.OutputDir, "requests", len(hf.Requests))
+
+	collectionName := opts.CollectionName
+	if collectionName == "" {
+		collectionName = strings.TrimSuffix(filepath.Base(opts.Source), filepath.Ext(opts.Source))
+	}
+	w, err := newCollectionWriter(opts.OutputDir, collectionName)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range hf.Notes {
+		log.Warn("import.http.note", "note", n)
+	}
+	for i, hr := range hf.Requests {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		req := httpBruRequest(hr, hf.Vars, filepath.Dir(opts.Source), log)
+		req.Seq = i + 1
+		if opts.GenerateTests {
+			req.Tests = baselineTests(0, "")
+		}
+		filename, err := w.writeRequest("", req)
+		if err != nil {
+			return err
+		}
+		log.Info("import.http.request.write", "name", req.Name, "method", req.Method, "file", filename)
+	}
+
+	if !isURL(opts.Source) {
+		envs, err := parser.HTTPClientEnvs(filepath.Dir(opts.Source))
+		if err != nil {
+			return fmt.Errorf("load http-client env: %w", err)
+		}
+		names := make([]string, 0, len(envs))
+		for name := range envs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rel, err := w.writeEnv(name, envs[name])
+			if err != nil {
+				return err
+			}
+			log.Info("import.http.env.write", "env", name, "file", rel)
+		}
+	}
+
+	if err := writeImportSummary(opts, collectionName, "bruno"); err != nil {
+		return err
+	}
+	log.Info("import.http.done", "output", opts.OutputDir, "requests", len(hf.Requests))
+	return nil
+}
+
+// httpBruRequest maps one .http request onto a .bru request. The body block
+// follows Content-Type; "< file" bodies are inlined when readable.
+func httpBruRequest(hr parser.HTTPRequest, fileVars []parser.HTTPPair, baseDir string, log pslog.Logger) bruRequest {
+	resolved := resolveHTTPFileVars(fileVars)
+	inline := func(s string) string { return inlineHTTPFileVars(s, resolved) }
+	hr.URL = inline(hr.URL)
+	hr.Body = inline(hr.Body)
+	hr.Headers = append([]parser.HTTPPair(nil), hr.Headers...)
+	for i := range hr.Headers {
+		hr.Headers[i].Value = inline(hr.Headers[i].Value)
+	}
+
+	req := bruRequest{Name: hr.DisplayName(), Method: hr.Method, URL: hr.URL}
+	if _, q, ok := strings.Cut(hr.URL, "?"); ok {
+		q, _, _ = strings.Cut(q, "#")
+		req.Query = queryPairs(q)
+	}
+	notes := append([]string(nil), hr.Notes...)
+
+	body := hr.Body
+	if hr.BodyFile != "" {
+		p := hr.BodyFile
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(baseDir, p)
+		}
+		if b, err := os.ReadFile(p); err == nil {
+			body = string(b)
+		} else {
+			notes = append(notes, "TODO(gru): request body was read from "+hr.BodyFile+"; paste its content into the body block.")
+		}
+	}
+
+	ct := ""
+	for _, h := range hr.Headers {
+		if strings.EqualFold(h.Key, "Content-Type") {
+			ct = h.Value
+		}
+	}
+	dropCT := false
+	if strings.TrimSpace(body) != "" {
+		kind := bodyKindForContentType(ct)
+		switch kind {
+		case "form-urlencoded":
+			var joined strings.Builder
+			for _, l := range strings.Split(body, "\n") {
+				joined.WriteString(strings.TrimSpace(l))
+			}
+			req.Form = queryPairs(joined.String())
+			dropCT = true
+		case "multipart-form":
+			// Raw multipart text with its own boundary is sent as is.
+			kind = "text"
+			req.Body = body
+		default:
+			req.Body = body
+		}
+		req.BodyKind = kind
+	}
+	for _, h := range hr.Headers {
+		if dropCT && strings.EqualFold(h.Key, "Content-Type") {
+			continue
+		}
+		req.Headers = append(req.Headers, bruPair{Key: h.Key, Value: h.Value})
+	}
+
+	// Only the file variables this request references.
+	used := map[string]struct{}{}
+	texts := []string{hr.URL, body}
+	for _, h := range hr.Headers {
+		texts = append(texts, h.Value)
+	}
+	for _, t := range texts {
+		for _, m := range parser.VarPattern.FindAllStringSubmatch(t, -1) {
+			used[strings.TrimSpace(m[1])] = struct{}{}
+		}
+	}
+	for _, v := range fileVars {
+		if _, ok := used[v.Key]; ok {
+			req.VarsPre = append(req.VarsPre, bruPair{Key: v.Key, Value: resolved[v.Key]})
+		}
+	}
+	for _, pv := range hr.PostVars {
+		req.VarsPost = append(req.VarsPost, bruPair{Key: pv.Key, Value: pv.Value})
+	}
+
+	for _, n := range notes {
+		log.Warn("import.http.note", "request", req.Name, "note", n)
+	}
+	req.Docs = strings.Join(notes, "\n")
+	return req
+}
+
+// resolveHTTPFileVars expands file variables that reference other file
+// variables, so each value can be written without nesting.
+func resolveHTTPFileVars(fileVars []parser.HTTPPair) map[string]string {
+	raw := map[string]string{}
+	for _, v := range fileVars {
+		raw[v.Key] = v.Value
+	}
+	out := map[string]string{}
+	for k, v := range raw {
+		for range 8 {
+			next := parser.VarPattern.ReplaceAllStringFunc(v, func(m string) string {
+				if rv, ok := raw[strings.TrimSpace(m[2:len(m)-2])]; ok {
+					return rv
+				}
+				return m
+			})
+			if next == v {
+				break
+			}
+			v = next
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// inlineHTTPFileVars replaces references to file variables whose value still
+// holds a {{...}} reference (response or environment values) with that value.
+// .bru vars:pre-request values are not expanded again, so these cannot be
+// written as request vars.
+func inlineHTTPFileVars(s string, resolved map[string]string) string {
+	return parser.VarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := resolved[strings.TrimSpace(m[2:len(m)-2])]; ok && strings.Contains(v, "{{") {
+			return v
+		}
+		return m
+	})
+}
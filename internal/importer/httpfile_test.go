@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+)
+
+func TestImportHTTPFileAndRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login":
+			if r.FormValue("user") != "ada" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"token": "tok-7"})
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer tok-7" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"ada"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	env := `{"dev": {"host": "` + srv.URL + `", "user": "ada"}, "prod": {"host": "https://api.example.com"}}`
+	if err := os.WriteFile(filepath.Join(dir, "http-client.env.json"), []byte(env), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `@token = {{login.response.body.$.token}}
+
+# @name login
+POST {{host}}/login
+Content-Type: application/x-www-form-urlencoded
+
+user={{user}}
+
+### Who am I
+GET {{host}}/me?verbose=1
+Authorization: Bearer {{token}}
+Accept: application/json
+`
+	srcPath := filepath.Join(dir, "requests.http")
+	if err := os.WriteFile(srcPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := ImportHTTP(context.Background(), Options{Source: srcPath, OutputDir: out}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	login := read("login.bru")
+	for _, want := range []string{"post {\n  url: {{host}}/login\n  body: form-urlencoded", "body:form-urlencoded {\n  user: {{user}}\n}", "vars:post-response {\n  login.response.body.token: res.body.token\n}"} {
+		if !strings.Contains(login, want) {
+			t.Fatalf("login.bru missing %q:\n%s", want, login)
+		}
+	}
+	if strings.Contains(login, "Content-Type") || strings.Contains(login, "vars:pre-request") {
+		t.Fatalf("login.bru has unexpected blocks:\n%s", login)
+	}
+	me := read("Who_am_I.bru")
+	for _, want := range []string{"seq: 2", "params:query {\n  verbose: 1\n}", "Authorization: Bearer {{login.response.body.token}}"} {
+		if !strings.Contains(me, want) {
+			t.Fatalf("Who_am_I.bru missing %q:\n%s", want, me)
+		}
+	}
+	if strings.Contains(me, "vars:pre-request") {
+		t.Fatalf("response reference should be inlined, not a request var:\n%s", me)
+	}
+	if got := read(filepath.Join("environments", "prod.bru")); got != "vars {\n  host: https://api.example.com\n}\n" {
+		t.Fatalf("prod env: %q", got)
+	}
+
+	g, err := runner.New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFolder(context.Background(), out, runner.RunOptions{EnvPath: filepath.Join(out, "environments", "dev.bru")})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Passed != 2 {
+		for _, c := range sum.Cases {
+			t.Logf("%s: status=%d failures=%+v err=%s", c.Name, c.Status, c.Failures, c.ErrorText)
+		}
+		t.Fatalf("summary: %+v", sum)
+	}
+}
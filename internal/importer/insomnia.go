@@ -0,0 +1,716 @@
+package importer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oasdiff/yaml"
+	"pkt.systems/pslog"
+)
+
+// insomniaItem is a request or request group. The same struct decodes v4
+// export resources (flat, linked by parentId) and v5 collection entries
+// (nested children, ids under meta).
+type insomniaItem struct {
+	ID             string         `json:"_id"`
+	Type           string         `json:"_type"`
+	ParentID       string         `json:"parentId"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	MetaSortKey    float64        `json:"metaSortKey"`
+	URL            string         `json:"url"`
+	Method         string         `json:"method"`
+	Body           insomniaBody   `json:"body"`
+	Parameters     []insomniaPair `json:"parameters"`
+	PathParameters []insomniaPair `json:"pathParameters"`
+	Headers        []insomniaPair `json:"headers"`
+	Authentication insomniaAuth   `json:"authentication"`
+	Environment    map[string]any `json:"environment"`
+	// v4 (Insomnia 9+) scripts
+	PreRequestScript    string `json:"preRequestScript"`
+	AfterResponseScript string `json:"afterResponseScript"`
+	// v5 layout
+	Meta struct {
+		ID          string  `json:"id"`
+		SortKey     float64 `json:"sortKey"`
+		Description string  `json:"description"`
+	} `json:"meta"`
+	Scripts struct {
+		PreRequest    string `json:"preRequest"`
+		AfterResponse string `json:"afterResponse"`
+	} `json:"scripts"`
+	Children []*insomniaItem `json:"children"`
+}
+
+func (it *insomniaItem) id() string {
+	if it.ID != "" {
+		return it.ID
+	}
+	return it.Meta.ID
+}
+
+func (it *insomniaItem) sortKey() float64 {
+	if it.MetaSortKey != 0 {
+		return it.MetaSortKey
+	}
+	return it.Meta.SortKey
+}
+
+func (it *insomniaItem) isFolder() bool {
+	return it.Type == "request_group" || it.Children != nil || strings.HasPrefix(it.Meta.ID, "fld_")
+}
+
+func (it *insomniaItem) docs() string {
+	if it.Description != "" {
+		return it.Description
+	}
+	return it.Meta.Description
+}
+
+func (it *insomniaItem) scripts() (pre, post string) {
+	pre, post = it.PreRequestScript, it.AfterResponseScript
+	if pre == "" {
+		pre = it.Scripts.PreRequest
+	}
+	if post == "" {
+		post = it.Scripts.AfterResponse
+	}
+	return strings.TrimSpace(pre), strings.TrimSpace(post)
+}
+
+type insomniaPair struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+	FileName string `json:"fileName"`
+}
+
+type insomniaBody struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []insomniaPair `json:"params"`
+	FileName string         `json:"fileName"`
+}
+
+// insomniaAuth keeps the raw authentication object; its fields vary by type.
+type insomniaAuth map[string]any
+
+func (a insomniaAuth) str(key string) string {
+	if v, ok := a[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func (a insomniaAuth) active() bool {
+	disabled, _ := a["disabled"].(bool)
+	t := a.str("type")
+	return !disabled && t != "" && t != "none" && t != "inherit"
+}
+
+type insomniaEnv struct {
+	ID              string         `json:"_id"`
+	Type            string         `json:"_type"`
+	ParentID        string         `json:"parentId"`
+	Name            string         `json:"name"`
+	Data            map[string]any `json:"data"`
+	SubEnvironments []insomniaEnv  `json:"subEnvironments"`
+}
+
+// insomniaExport is the union of the v4 ("__export_format": 4) and v5
+// ("type: collection.insomnia.rest/5.0") layouts.
+type insomniaExport struct {
+	ExportType   string            `json:"_type"`
+	ExportFormat int               `json:"__export_format"`
+	Resources    []json.RawMessage `json:"resources"`
+	Type         string            `json:"type"`
+	Name         string            `json:"name"`
+	Collection   []*insomniaItem   `json:"collection"`
+	Environments *insomniaEnv      `json:"environments"`
+}
+
+// insomniaScope is what a request group passes down to its children.
+type insomniaScope struct {
+	dir     string
+	auth    insomniaAuth
+	headers []bruPair
+	vars    []bruPair
+}
+
+// insomniaPending is a converted request waiting for response-tag chaining to
+// be resolved before it is written.
+type insomniaPending struct {
+	id  string
+	dir string
+	req bruRequest
+}
+
+type insomniaImport struct {
+	opts     Options
+	log      pslog.Logger
+	envVars  map[string]string
+	pending  []*insomniaPending
+	byID     map[string]*insomniaPending
+	refs     map[string]string // response tag key -> var name
+	refNames map[string]struct{}
+	postVars map[string][]bruPair
+	// refOrder records which request (by id) consumes which producer.
+	refOrder [][2]string
+	flagged  int
+}
+
+var (
+	insomniaVarTag      = regexp.MustCompile(`\{\{\s*(?:_\.)?([A-Za-z_$][\w$.\-]*(?:\[[^\]]+\])*)\s*\}\}`)
+	insomniaTemplateTag = regexp.MustCompile(`\{%\s*(\w+)(.*?)%\}`)
+	insomniaTagArg      = regexp.MustCompile(`'([^']*)'|"([^"]*)"|([^,\s]+)`)
+	insomniaPathIdent   = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+	insomniaAPI         = regexp.MustCompile(`\binsomnia\.`)
+	postmanAPI          = regexp.MustCompile(`\bpm\.`)
+)
+
+// ImportInsomnia converts an Insomnia export (v4 JSON or v5 YAML) into a Bruno
+// collection. Request groups become directories, the base environment goes to
+// environments/local.bru and each sub-environment (merged over the base) to
+// its own environments/<name>.bru. {% response %} tags are turned into
+// vars:post-response on the referenced request.
+func ImportInsomnia(ctx context.Context, opts Options) error {
+	data, err := readSource(ctx, opts.Source, opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("load insomnia source: %w", err)
+	}
+	var exp insomniaExport
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		err = json.Unmarshal(data, &exp)
+	} else {
+		err = yaml.Unmarshal(data, &exp)
+	}
+	if err != nil {
+		return fmt.Errorf("parse insomnia export: %w", err)
+	}
+
+	var (
+		roots []*insomniaItem
+		base  insomniaEnv
+		subs  []insomniaEnv
+		name  string
+	)
+	switch {
+	case exp.ExportType == "export" && exp.ExportFormat == 4:
+		roots, base, subs, name, err = insomniaV4Tree(exp.Resources)
+		if err != nil {
+			return err
+		}
+	case strings.HasPrefix(exp.Type, "collection.insomnia.rest/5"):
+		roots, name = exp.Collection, exp.Name
+		if exp.Environments != nil {
+			base, subs = *exp.Environments, exp.Environments.SubEnvironments
+		}
+	default:
+		return fmt.Errorf("unsupported insomnia export (want v4 JSON or v5 collection YAML, got format=%d type=%q)", exp.ExportFormat, exp.Type)
+	}
+
+	if !opts.GenerateTestsSet {
+		opts.GenerateTests = true
+	}
+
+	log := opts.Logger
+	if log == nil {
+		log = pslog.NewWithOptions(os.Stdout, pslog.Options{Mode: pslog.ModeConsole, MinLevel: pslog.InfoLevel})
+	}
+	log = log.With("fn", pslog.CurrentFn())
+	log.Info("import.insomnia.start", "source", opts.Source, "output", opts.OutputDir)
+
+	collectionName := opts.CollectionName
+	if collectionName == "" {
+		collectionName = name
+	}
+	if collectionName == "" {
+		collectionName = "imported-insomnia"
+	}
+	w, err := newCollectionWriter(opts.OutputDir, collectionName)
+	if err != nil {
+		return err
+	}
+
+	imp := &insomniaImport{
+		opts:     opts,
+		log:      log,
+		envVars:  map[string]string{},
+		byID:     map[string]*insomniaPending{},
+		refs:     map[string]string{},
+		refNames: map[string]struct{}{},
+		postVars: map[string][]bruPair{},
+	}
+	if err := imp.walk(ctx, roots, insomniaScope{}); err != nil {
+		return err
+	}
+	imp.checkOrder()
+
+	for _, p := range imp.pending {
+		p.req.VarsPost = imp.postVars[p.id]
+		filename, err := w.writeRequest(p.dir, p.req)
+		if err != nil {
+			return err
+		}
+		log.Info("import.insomnia.request.write", "name", p.req.Name, "verb", p.req.Method, "file", filename)
+	}
+
+	if err := writeImportSummary(opts, collectionName, "bruno"); err != nil {
+		return err
+	}
+	if opts.OutputDir != "" {
+		baseVars := imp.envData(base.Data)
+		for k, v := range imp.envVars {
+			if _, ok := baseVars[k]; !ok {
+				baseVars[k] = v
+			}
+		}
+		envPath, err := w.writeEnv("local", baseVars)
+		if err != nil {
+			return err
+		}
+		log.Debug("import.insomnia.env.write", "name", base.Name, "path", envPath, "vars", len(baseVars))
+		for i, sub := range subs {
+			vars := map[string]string{}
+			for k, v := range baseVars {
+				vars[k] = v
+			}
+			for k, v := range imp.envData(sub.Data) {
+				vars[k] = v
+			}
+			envName := sanitizeFileName(sub.Name)
+			if envName == "" || envName == "local" {
+				envName = fmt.Sprintf("environment-%d", i+1)
+			}
+			envPath, err := w.writeEnv(envName, vars)
+			if err != nil {
+				return err
+			}
+			log.Info("import.insomnia.env.write", "name", sub.Name, "path", envPath, "vars", len(vars))
+		}
+	}
+	if imp.flagged > 0 {
+		log.Warn("import.insomnia.review", "items", imp.flagged, "hint", "search for TODO(gru) in the imported collection")
+	}
+	log.Info("import.insomnia.done", "output", opts.OutputDir, "requests", len(imp.pending))
+	return nil
+}
+
+// insomniaV4Tree links v4 resources into a tree and picks out environments.
+// With several workspaces each becomes a top-level folder.
+func insomniaV4Tree(raw []json.RawMessage) (roots []*insomniaItem, base insomniaEnv, subs []insomniaEnv, name string, err error) {
+	var (
+		items      []*insomniaItem
+		envs       []insomniaEnv
+		workspaces []*insomniaItem
+	)
+	for _, r := range raw {
+		var probe struct {
+			Type string `json:"_type"`
+		}
+		if err := json.Unmarshal(r, &probe); err != nil {
+			return nil, base, nil, "", fmt.Errorf("parse insomnia resource: %w", err)
+		}
+		switch probe.Type {
+		case "workspace", "request", "request_group":
+			it := &insomniaItem{}
+			if err := json.Unmarshal(r, it); err != nil {
+				return nil, base, nil, "", fmt.Errorf("parse insomnia %s: %w", probe.Type, err)
+			}
+			if probe.Type == "workspace" {
+				workspaces = append(workspaces, it)
+			} else {
+				items = append(items, it)
+			}
+		case "environment":
+			var env insomniaEnv
+			if err := json.Unmarshal(r, &env); err != nil {
+				return nil, base, nil, "", fmt.Errorf("parse insomnia environment: %w", err)
+			}
+			envs = append(envs, env)
+		}
+	}
+
+	byID := map[string]*insomniaItem{}
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	wsByID := map[string]*insomniaItem{}
+	for _, ws := range workspaces {
+		ws.Type = "request_group"
+		ws.Children = []*insomniaItem{}
+		wsByID[ws.ID] = ws
+	}
+	for _, it := range items {
+		if parent, ok := byID[it.ParentID]; ok {
+			parent.Children = append(parent.Children, it)
+		} else if ws, ok := wsByID[it.ParentID]; ok {
+			ws.Children = append(ws.Children, it)
+		} else {
+			roots = append(roots, it)
+		}
+	}
+	switch len(workspaces) {
+	case 0:
+	case 1:
+		name = workspaces[0].Name
+		roots = append(workspaces[0].Children, roots...)
+	default:
+		roots = append(workspaces, roots...)
+	}
+
+	// Base environments hang off a workspace, sub-environments off a base.
+	envIDs := map[string]struct{}{}
+	for _, env := range envs {
+		envIDs[env.ID] = struct{}{}
+	}
+	for _, env := range envs {
+		if _, sub := envIDs[env.ParentID]; sub {
+			subs = append(subs, env)
+			continue
+		}
+		if base.Data == nil {
+			base = env
+			continue
+		}
+		for k, v := range env.Data {
+			base.Data[k] = v
+		}
+	}
+	return roots, base, subs, name, nil
+}
+
+func (imp *insomniaImport) walk(ctx context.Context, items []*insomniaItem, scope insomniaScope) error {
+	sorted := append([]*insomniaItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].sortKey() < sorted[j].sortKey() })
+	for _, it := range sorted {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if it.isFolder() {
+			child := scope
+			child.dir = filepath.Join(scope.dir, sanitizeFileName(it.Name))
+			if it.Authentication.active() {
+				child.auth = it.Authentication
+			}
+			child.headers = append(append([]bruPair{}, scope.headers...), imp.pairs(it.Headers)...)
+			child.vars = append([]bruPair{}, scope.vars...)
+			folderVars := imp.envData(it.Environment)
+			keys := make([]string, 0, len(folderVars))
+			for k := range folderVars {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				child.vars = append(child.vars, bruPair{Key: k, Value: folderVars[k]})
+			}
+			imp.log.Debug("import.insomnia.folder", "dir", child.dir)
+			if err := imp.walk(ctx, it.Children, child); err != nil {
+				return err
+			}
+			continue
+		}
+		p := &insomniaPending{id: it.id(), dir: scope.dir}
+		imp.pending = append(imp.pending, p)
+		if p.id != "" {
+			imp.byID[p.id] = p
+		}
+		p.req = imp.request(it, scope, p)
+	}
+	return nil
+}
+
+func (imp *insomniaImport) request(it *insomniaItem, scope insomniaScope, p *insomniaPending) bruRequest {
+	method := strings.ToUpper(it.Method)
+	if method == "" {
+		method = "GET"
+	}
+	req := bruRequest{
+		Name:    it.Name,
+		Seq:     len(imp.pending),
+		Method:  method,
+		URL:     imp.template(it.URL, p),
+		VarsPre: scope.vars,
+		Docs:    it.docs(),
+	}
+	for _, q := range it.Parameters {
+		req.Query = append(req.Query, bruPair{Key: q.Name, Value: imp.template(q.Value, p), Disabled: q.Disabled})
+	}
+	for _, pp := range it.PathParameters {
+		req.PathParams = append(req.PathParams, bruPair{Key: pp.Name, Value: imp.template(pp.Value, p)})
+	}
+	req.Headers = append(req.Headers, scope.headers...)
+	for _, h := range it.Headers {
+		req.Headers = append(req.Headers, bruPair{Key: h.Name, Value: imp.template(h.Value, p), Disabled: h.Disabled})
+	}
+
+	auth := it.Authentication
+	if !auth.active() && (auth.str("type") == "" || auth.str("type") == "inherit") {
+		auth = scope.auth
+	}
+	imp.applyAuth(&req, auth, p)
+	imp.applyBody(&req, it.Body, p)
+
+	pre, post := it.scripts()
+	if pre != "" {
+		req.ScriptPre = imp.translate(it.Name, pre)
+	}
+	if post != "" {
+		req.Tests = imp.translate(it.Name, post)
+	} else if imp.opts.GenerateTests {
+		req.Tests = baselineTests(0, "")
+	}
+	if strings.Contains(req.Docs, "TODO(gru)") {
+		imp.flagged++
+	}
+	return req
+}
+
+func (imp *insomniaImport) applyAuth(req *bruRequest, a insomniaAuth, p *insomniaPending) {
+	if a == nil || !a.active() {
+		return
+	}
+	switch t := strings.ToLower(a.str("type")); t {
+	case "bearer":
+		prefix := a.str("prefix")
+		if prefix == "" {
+			prefix = "Bearer"
+		}
+		req.Headers = append(req.Headers, bruPair{Key: "Authorization", Value: prefix + " " + imp.template(a.str("token"), p)})
+	case "oauth2":
+		token := "{{accessToken}}"
+		if _, ok := imp.envVars["accessToken"]; !ok {
+			imp.envVars["accessToken"] = "CHANGEME"
+		}
+		req.Headers = append(req.Headers, bruPair{Key: "Authorization", Value: "Bearer " + token})
+	case "basic":
+		user, pass := imp.template(a.str("username"), p), imp.template(a.str("password"), p)
+		req.Headers = append(req.Headers, bruPair{Key: "Authorization", Value: "Basic {{basicAuth}}"})
+		cred, ok := basicCredential(user, pass)
+		imp.envVars["basicAuth"] = cred
+		if !ok {
+			imp.log.Warn("import.insomnia.auth.basic.templated", "request", req.Name, "hint", "set basicAuth to base64(username:password)")
+		}
+	case "apikey":
+		key, value := a.str("key"), imp.template(a.str("value"), p)
+		if key == "" {
+			key = "X-API-Key"
+		}
+		switch a.str("addTo") {
+		case "queryParams":
+			req.Query = append(req.Query, bruPair{Key: key, Value: value})
+		case "cookie":
+			req.Headers = append(req.Headers, bruPair{Key: "Cookie", Value: key + "=" + value})
+		default:
+			req.Headers = append(req.Headers, bruPair{Key: key, Value: value})
+		}
+	default:
+		imp.log.Warn("import.insomnia.auth.unsupported", "request", req.Name, "type", t)
+		req.Docs = strings.TrimSpace(req.Docs + "\n\nTODO(gru): Insomnia auth type " + t + " was not imported.")
+	}
+}
+
+func (imp *insomniaImport) applyBody(req *bruRequest, b insomniaBody, p *insomniaPending) {
+	kind := bodyKindForContentType(b.MimeType)
+	switch {
+	case b.FileName != "" && len(b.Params) == 0:
+		imp.log.Warn("import.insomnia.body.file", "request", req.Name, "file", b.FileName)
+		req.Docs = strings.TrimSpace(req.Docs + "\n\nTODO(gru): binary file body " + b.FileName + " was not imported.")
+	case kind == "form-urlencoded" || kind == "multipart-form":
+		if len(b.Params) == 0 {
+			return
+		}
+		req.BodyKind = kind
+		for _, f := range b.Params {
+			value := imp.template(f.Value, p)
+			if f.Type == "file" {
+				value = "@" + f.FileName
+			}
+			req.Form = append(req.Form, bruPair{Key: f.Name, Value: value, Disabled: f.Disabled})
+		}
+	case kind == "graphql":
+		// Insomnia stores GraphQL as {"query": ..., "variables": {...}}.
+		var gql struct {
+			Query     string          `json:"query"`
+			Variables json.RawMessage `json:"variables"`
+		}
+		if err := json.Unmarshal([]byte(b.Text), &gql); err != nil || gql.Query == "" {
+			req.BodyKind, req.Body = "text", imp.template(b.Text, p)
+			return
+		}
+		req.BodyKind = "graphql"
+		req.Body = imp.template(gql.Query, p)
+		if vars := strings.TrimSpace(string(gql.Variables)); vars != "" && vars != "null" && vars != "{}" {
+			req.GraphqlVars = imp.template(vars, p)
+		}
+	case strings.TrimSpace(b.Text) != "":
+		if b.MimeType == "" {
+			kind = "text"
+		}
+		req.BodyKind = kind
+		req.Body = imp.template(b.Text, p)
+	}
+}
+
+// template rewrites Insomnia Nunjucks templates into Bruno's {{var}} syntax.
+// {% response %} tags become a variable fed by vars:post-response on the
+// referenced request; other tags are kept and flagged for review.
+func (imp *insomniaImport) template(s string, p *insomniaPending) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+	s = insomniaTemplateTag.ReplaceAllStringFunc(s, func(tag string) string {
+		m := insomniaTemplateTag.FindStringSubmatch(tag)
+		if m[1] == "response" {
+			if name, ok := imp.responseRef(m[2], p); ok {
+				return "{{" + name + "}}"
+			}
+		}
+		imp.log.Warn("import.insomnia.tag.unsupported", "tag", tag, "request", p.id)
+		imp.flagged++
+		return tag
+	})
+	return insomniaVarTag.ReplaceAllString(s, "{{$1}}")
+}
+
+// responseRef resolves a {% response 'field', 'req_id', 'filter', ... %} tag
+// into a variable name and registers the producing vars:post-response entry.
+func (imp *insomniaImport) responseRef(args string, p *insomniaPending) (string, bool) {
+	var parts []string
+	for _, m := range insomniaTagArg.FindAllStringSubmatch(args, -1) {
+		parts = append(parts, m[1]+m[2]+m[3])
+	}
+	if len(parts) < 2 {
+		return "", false
+	}
+	field, refID, filter := parts[0], parts[1], ""
+	if len(parts) > 2 {
+		filter = parts[2]
+	}
+	if strings.HasPrefix(filter, "b64::") {
+		enc := strings.TrimPrefix(filter, "b64::")
+		if i := strings.Index(enc, "::"); i >= 0 {
+			enc = enc[:i]
+		}
+		dec, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return "", false
+		}
+		filter = string(dec)
+	}
+
+	var expr, base string
+	switch field {
+	case "body":
+		if filter == "" {
+			expr, base = "res.body", "response"
+			break
+		}
+		path, ok := strings.CutPrefix(strings.TrimSpace(filter), "$")
+		if !ok || strings.Contains(path, "..") || strings.ContainsAny(path, "*?@") || (path != "" && path[0] != '.' && path[0] != '[') {
+			return "", false
+		}
+		expr = "res.body" + path
+		idents := insomniaPathIdent.FindAllString(path, -1)
+		base = "response"
+		if len(idents) > 0 {
+			base = idents[len(idents)-1]
+		}
+	case "header":
+		if filter == "" {
+			return "", false
+		}
+		expr = fmt.Sprintf("res.headers[%q]", strings.ToLower(filter))
+		base = toVarName(filter)
+	case "raw":
+		expr, base = "res.text()", "responseText"
+	default:
+		return "", false
+	}
+
+	key := refID + "\x00" + expr
+	imp.refOrder = append(imp.refOrder, [2]string{p.id, refID})
+	if name, ok := imp.refs[key]; ok {
+		return name, true
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := imp.refNames[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	imp.refs[key] = name
+	imp.refNames[name] = struct{}{}
+	imp.postVars[refID] = append(imp.postVars[refID], bruPair{Key: name, Value: expr})
+	return name, true
+}
+
+// checkOrder warns when a request consumes a response from a request that
+// does not run before it (or is not part of the export).
+func (imp *insomniaImport) checkOrder() {
+	for _, ref := range imp.refOrder {
+		consumer, producer := imp.byID[ref[0]], imp.byID[ref[1]]
+		switch {
+		case producer == nil:
+			imp.log.Warn("import.insomnia.response.missing", "request", ref[0], "references", ref[1])
+		case consumer != nil && producer.req.Seq >= consumer.req.Seq:
+			imp.log.Warn("import.insomnia.response.order", "request", consumer.req.Name, "references", producer.req.Name, "hint", "adjust meta seq so the referenced request runs first")
+		}
+	}
+}
+
+func (imp *insomniaImport) translate(name, code string) string {
+	// Insomnia's script API mirrors Postman's, so reuse the pm.* translator.
+	code = insomniaAPI.ReplaceAllString(code, "pm.")
+	out, flagged := translatePostmanScript(code)
+	for _, line := range flagged {
+		imp.log.Warn("import.insomnia.script.untranslated", "request", name, "line", line)
+	}
+	imp.flagged += len(flagged)
+	return postmanAPI.ReplaceAllString(out, "insomnia.")
+}
+
+func (imp *insomniaImport) pairs(list []insomniaPair) []bruPair {
+	var out []bruPair
+	for _, kv := range list {
+		out = append(out, bruPair{Key: kv.Name, Value: imp.template(kv.Value, &insomniaPending{}), Disabled: kv.Disabled})
+	}
+	return out
+}
+
+// envData flattens an Insomnia environment: nested objects become dotted keys
+// (matching {{ _.obj.key }} references) and arrays are stored as JSON.
+func (imp *insomniaImport) envData(data map[string]any) map[string]string {
+	out := map[string]string{}
+	var flatten func(prefix string, v any)
+	flatten = func(prefix string, v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, child := range val {
+				flatten(prefix+k+".", child)
+			}
+		case []any:
+			b, _ := json.Marshal(val)
+			out[strings.TrimSuffix(prefix, ".")] = string(b)
+		case string:
+			out[strings.TrimSuffix(prefix, ".")] = imp.template(val, &insomniaPending{})
+		case nil:
+			out[strings.TrimSuffix(prefix, ".")] = ""
+		default:
+			out[strings.TrimSuffix(prefix, ".")] = fmt.Sprint(val)
+		}
+	}
+	for k, v := range data {
+		flatten(k+".", v)
+	}
+	return out
+}
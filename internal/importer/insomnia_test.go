@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+)
+
+const insomniaV4Fixture = `{
+  "_type": "export",
+  "__export_format": 4,
+  "resources": [
+    {"_id": "wrk_1", "_type": "workspace", "parentId": null, "name": "Shop API"},
+    {"_id": "env_base", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"baseUrl": "http://placeholder", "user": {"name": "ada"}}},
+    {"_id": "env_stage", "_type": "environment", "parentId": "env_base", "name": "Staging", "data": {"baseUrl": "https://staging.example.com"}},
+    {"_id": "fld_auth", "_type": "request_group", "parentId": "wrk_1", "name": "Auth", "metaSortKey": -20},
+    {"_id": "fld_items", "_type": "request_group", "parentId": "wrk_1", "name": "Items", "metaSortKey": -10,
+      "environment": {"pageSize": 5},
+      "authentication": {"type": "bearer", "token": "{% response 'body', 'req_login', 'b64::JC50b2tlbg==::46b', 'never', 60 %}"}},
+    {"_id": "req_login", "_type": "request", "parentId": "fld_auth", "name": "Login", "metaSortKey": -5,
+      "method": "POST", "url": "{{ _.baseUrl }}/login",
+      "headers": [{"name": "Content-Type", "value": "application/json"}],
+      "body": {"mimeType": "application/json", "text": "{\"user\": \"{{ _.user.name }}\"}"}},
+    {"_id": "req_list", "_type": "request", "parentId": "fld_items", "name": "List items", "metaSortKey": -3,
+      "method": "GET", "url": "{{ _.baseUrl }}/items",
+      "parameters": [{"name": "limit", "value": "{{ _.pageSize }}"}, {"name": "debug", "value": "1", "disabled": true}],
+      "headers": [{"name": "X-Request-Id", "value": "{% response 'header', 'req_login', 'X-Request-Id', 'never', 60 %}"}],
+      "authentication": {"type": "inherit"},
+      "afterResponseScript": "insomnia.test(\"listed\", function () {\n  insomnia.expect(insomnia.response.code).to.equal(200);\n});"},
+    {"_id": "req_stamp", "_type": "request", "parentId": "fld_items", "name": "Stamp", "metaSortKey": -1,
+      "method": "GET", "url": "{{ _.baseUrl }}/stamp?id={% uuid 'v4' %}", "authentication": {}}
+  ]
+}`
+
+func TestImportInsomniaV4AndRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Request-Id", "rid-1")
+			_, _ = w.Write([]byte(`{"token":"tok-123"}`))
+		case "/items":
+			if r.Header.Get("Authorization") != "Bearer tok-123" || r.Header.Get("X-Request-Id") != "rid-1" || r.URL.Query().Get("limit") != "5" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "insomnia.json")
+	if err := os.WriteFile(src, []byte(strings.ReplaceAll(insomniaV4Fixture, "http://placeholder", srv.URL)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(tmp, "out")
+	if err := ImportInsomnia(context.Background(), Options{Source: src, OutputDir: out}); err != nil {
+		t.Fatalf("import insomnia: %v", err)
+	}
+
+	login, err := os.ReadFile(filepath.Join(out, "Auth", "Login.bru"))
+	if err != nil {
+		t.Fatalf("read login: %v", err)
+	}
+	for _, want := range []string{"url: {{baseUrl}}/login", `"user": "{{user.name}}"`, "vars:post-response {", "token: res.body.token", `xRequestId: res.headers["x-request-id"]`} {
+		if !strings.Contains(string(login), want) {
+			t.Fatalf("login missing %q:\n%s", want, login)
+		}
+	}
+	list, _ := os.ReadFile(filepath.Join(out, "Items", "List_items.bru"))
+	for _, want := range []string{"Authorization: Bearer {{token}}", "X-Request-Id: {{xRequestId}}", "pageSize: 5", "~debug: 1", `expect(res.status).to.equal(200);`} {
+		if !strings.Contains(string(list), want) {
+			t.Fatalf("list items missing %q:\n%s", want, list)
+		}
+	}
+	stamp, _ := os.ReadFile(filepath.Join(out, "Items", "Stamp.bru"))
+	if !strings.Contains(string(stamp), "{% uuid 'v4' %}") {
+		t.Fatalf("unsupported tags should be kept for review:\n%s", stamp)
+	}
+
+	local, _ := os.ReadFile(filepath.Join(out, "environments", "local.bru"))
+	staging, _ := os.ReadFile(filepath.Join(out, "environments", "Staging.bru"))
+	if !strings.Contains(string(local), "user.name: ada") || !strings.Contains(string(local), "baseUrl: "+srv.URL) {
+		t.Fatalf("unexpected base env:\n%s", local)
+	}
+	if !strings.Contains(string(staging), "baseUrl: https://staging.example.com") || !strings.Contains(string(staging), "user.name: ada") {
+		t.Fatalf("sub environment should be merged over base:\n%s", staging)
+	}
+
+	g, err := runner.New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFolder(context.Background(), out, runner.RunOptions{EnvPath: filepath.Join(out, "environments", "local.bru"), Recursive: true, RecursiveSet: true})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Total != 3 || sum.Failed != 0 {
+		for _, c := range sum.Cases {
+			t.Logf("%s status=%d passed=%v err=%s failures=%+v", c.Name, c.Status, c.Passed, c.ErrorText, c.Failures)
+		}
+		t.Fatalf("expected chained requests to pass, got %+v", sum)
+	}
+}
+
+func TestImportInsomniaV5YAML(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "collection.yaml")
+	yamlDoc := `type: collection.insomnia.rest/5.0
+name: Billing
+meta:
+  id: wrk_b
+collection:
+  - name: Invoices
+    meta:
+      id: fld_inv
+      sortKey: -2
+    children:
+      - url: "{{ _.baseUrl }}/invoices"
+        name: Create invoice
+        meta:
+          id: req_create
+          sortKey: -1
+        method: POST
+        body:
+          mimeType: application/x-www-form-urlencoded
+          params:
+            - name: amount
+              value: "10"
+        authentication:
+          type: basic
+          username: billing
+          password: secret
+  - url: "{{ _.baseUrl }}/graphql"
+    name: Query
+    meta:
+      id: req_gql
+      sortKey: 0
+    method: POST
+    body:
+      mimeType: application/graphql
+      text: '{"query":"{ invoices { id } }","variables":{"first":2}}'
+environments:
+  name: Base Environment
+  meta:
+    id: env_b
+  data:
+    baseUrl: http://localhost:9000
+  subEnvironments:
+    - name: Prod
+      meta:
+        id: env_p
+      data:
+        baseUrl: https://billing.example.com
+`
+	if err := os.WriteFile(src, []byte(yamlDoc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	if err := ImportInsomnia(context.Background(), Options{Source: src, OutputDir: out}); err != nil {
+		t.Fatalf("import insomnia v5: %v", err)
+	}
+	create, err := os.ReadFile(filepath.Join(out, "Invoices", "Create_invoice.bru"))
+	if err != nil {
+		t.Fatalf("read create: %v", err)
+	}
+	for _, want := range []string{"body: form-urlencoded", "amount: 10", "Authorization: Basic {{basicAuth}}", "seq: 1"} {
+		if !strings.Contains(string(create), want) {
+			t.Fatalf("create invoice missing %q:\n%s", want, create)
+		}
+	}
+	gql, _ := os.ReadFile(filepath.Join(out, "Query.bru"))
+	if !strings.Contains(string(gql), "body:graphql {") || !strings.Contains(string(gql), `"first":2`) {
+		t.Fatalf("graphql body not converted:\n%s", gql)
+	}
+	prod, _ := os.ReadFile(filepath.Join(out, "environments", "Prod.bru"))
+	local, _ := os.ReadFile(filepath.Join(out, "environments", "local.bru"))
+	if !strings.Contains(string(prod), "baseUrl: https://billing.example.com") || !strings.Contains(string(local), "basicAuth: YmlsbGluZzpzZWNyZXQ=") {
+		t.Fatalf("unexpected environments:\nprod=%s\nlocal=%s", prod, local)
+	}
+}
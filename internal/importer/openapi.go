@@ -64,7 +64,7 @@ func ImportOpenAPI(ctx context.Context, opts Options) error {
 		if opts.Insecure {
 			client = insecureHTTPClient()
 		}
-		data, err = fetchWithClient(opts.Source, client)
+		data, err = fetchWithClient(ctx, opts.Source, client)
 		location = mustParse(opts.Source)
 	} else {
 		if !filepath.IsAbs(opts.Source) {
@@ -601,12 +601,12 @@ func loadOpenAPIv3(ctx context.Context, data []byte, location *url.URL, opts Opt
 	if opts.Insecure || opts.AllowRemoteRefs {
 		client := insecureHTTPClient()
 		loader.ReadFromURIFunc = func(_ *openapi3.Loader, u *url.URL) ([]byte, error) {
-			return fetchExternal(u, client, opts)
+			return fetchExternal(ctx, u, client, opts)
 		}
 	}
 	if loader.ReadFromURIFunc == nil {
 		loader.ReadFromURIFunc = func(_ *openapi3.Loader, u *url.URL) ([]byte, error) {
-			return fetchExternal(u, http.DefaultClient, opts)
+			return fetchExternal(ctx, u, http.DefaultClient, opts)
 		}
 	}
 
@@ -632,18 +632,18 @@ func loadSwaggerAsV3(ctx context.Context, data []byte, location *url.URL, opts O
 	if opts.Insecure || opts.AllowRemoteRefs {
 		client := insecureHTTPClient()
 		loader.ReadFromURIFunc = func(_ *openapi3.Loader, u *url.URL) ([]byte, error) {
-			return fetchExternal(u, client, opts)
+			return fetchExternal(ctx, u, client, opts)
 		}
 	}
 	if loader.ReadFromURIFunc == nil {
 		loader.ReadFromURIFunc = func(_ *openapi3.Loader, u *url.URL) ([]byte, error) {
-			return fetchExternal(u, http.DefaultClient, opts)
+			return fetchExternal(ctx, u, http.DefaultClient, opts)
 		}
 	}
 	return openapi2conv.ToV3WithLoader(&doc2, loader, location)
 }
 
-func fetchExternal(u *url.URL, client *http.Client, opts Options) ([]byte, error) {
+func fetchExternal(ctx context.Context, u *url.URL, client *http.Client, opts Options) ([]byte, error) {
 	if client == nil {
 		client = http.DefaultClient
 	}
@@ -674,7 +674,7 @@ func fetchExternal(u *url.URL, client *http.Client, opts Options) ([]byte, error
 			return nil, fmt.Errorf("remote external ref blocked: %s (use --allow-remote-refs)", u.String())
 		}
 	}
-	return fetchWithClient(u.String(), client)
+	return fetchWithClient(ctx, u.String(), client)
 }
 
 func localPathFromFileURL(u *url.URL) string {
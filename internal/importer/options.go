@@ -2,6 +2,7 @@ package importer
 
 import "pkt.systems/pslog"
 import "net/url"
+import "io"
 
 // Options describes import settings for OpenAPI/WSDL conversion.
 type Options struct {
@@ -17,6 +18,20 @@ type Options struct {
 	GenerateTests    bool
 	GenerateTestsSet bool
 	IncludePaths     []string
+	// IncludeHosts, IncludeMethods and IncludeContentTypes filter captured
+	// traffic imports (har). Empty means no filtering.
+	IncludeHosts        []string
+	IncludeMethods      []string
+	IncludeContentTypes []string
+	// EnvironmentFiles lists extra environment exports (postman) to convert
+	// into environments/<name>.bru.
+	EnvironmentFiles []string
+	// Curl holds inline curl command line(s) for the curl import; Source is
+	// read when it is empty.
+	Curl string
+	// Stdout receives the rendered .bru when a curl import has no OutputDir
+	// (os.Stdout when nil).
+	Stdout io.Writer
 	// Strictness controls how deep/strict generated schema assertions should be.
 	// Values: "loose", "standard" (default), "strict".
 	Strictness string
@@ -0,0 +1,569 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pkt.systems/pslog"
+)
+
+// Postman v2.1 collection schema (subset used for import).
+type postmanCollection struct {
+	Info     postmanInfo    `json:"info"`
+	Item     []postmanItem  `json:"item"`
+	Variable []postmanKV    `json:"variable"`
+	Auth     *postmanAuth   `json:"auth"`
+	Event    []postmanEvent `json:"event"`
+}
+
+type postmanInfo struct {
+	Name        string      `json:"name"`
+	Schema      string      `json:"schema"`
+	Description postmanText `json:"description"`
+}
+
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description postmanText     `json:"description"`
+	Item        []postmanItem   `json:"item"`
+	Request     *postmanRequest `json:"request"`
+	Variable    []postmanKV     `json:"variable"`
+	Auth        *postmanAuth    `json:"auth"`
+	Event       []postmanEvent  `json:"event"`
+}
+
+type postmanRequest struct {
+	Method      string       `json:"method"`
+	Header      []postmanKV  `json:"header"`
+	URL         postmanURL   `json:"url"`
+	Body        *postmanBody `json:"body"`
+	Auth        *postmanAuth `json:"auth"`
+	Description postmanText  `json:"description"`
+}
+
+type postmanURL struct {
+	Raw      string      `json:"raw"`
+	Protocol string      `json:"protocol"`
+	Host     []string    `json:"host"`
+	Path     []string    `json:"path"`
+	Query    []postmanKV `json:"query"`
+	Variable []postmanKV `json:"variable"`
+}
+
+// UnmarshalJSON accepts both the string and the object form of a Postman URL.
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanURL
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*u = postmanURL(p)
+	return nil
+}
+
+func (u postmanURL) String() string {
+	if u.Raw != "" {
+		return u.Raw
+	}
+	s := strings.Join(u.Host, ".")
+	if u.Protocol != "" {
+		s = u.Protocol + "://" + s
+	}
+	if len(u.Path) > 0 {
+		s += "/" + strings.Join(u.Path, "/")
+	}
+	var q []string
+	for _, kv := range u.Query {
+		if !kv.Disabled {
+			q = append(q, kv.Key+"="+kv.String())
+		}
+	}
+	if len(q) > 0 {
+		s += "?" + strings.Join(q, "&")
+	}
+	return s
+}
+
+// postmanText is a description that may be a string or {content: "..."}.
+type postmanText string
+
+func (t *postmanText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = postmanText(s)
+		return nil
+	}
+	var obj struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*t = postmanText(obj.Content)
+	return nil
+}
+
+type postmanKV struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Type     string `json:"type"`
+	Src      any    `json:"src"`
+	Disabled bool   `json:"disabled"`
+	Enabled  *bool  `json:"enabled"` // environment files
+}
+
+func (kv postmanKV) String() string {
+	switch v := kv.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func (kv postmanKV) active() bool {
+	return !kv.Disabled && (kv.Enabled == nil || *kv.Enabled)
+}
+
+type postmanBody struct {
+	Mode       string      `json:"mode"`
+	Raw        string      `json:"raw"`
+	URLEncoded []postmanKV `json:"urlencoded"`
+	FormData   []postmanKV `json:"formdata"`
+	File       *struct {
+		Src string `json:"src"`
+	} `json:"file"`
+	GraphQL *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+type postmanAuth struct {
+	Type   string      `json:"type"`
+	Bearer []postmanKV `json:"bearer"`
+	Basic  []postmanKV `json:"basic"`
+	APIKey []postmanKV `json:"apikey"`
+	OAuth2 []postmanKV `json:"oauth2"`
+}
+
+func (a *postmanAuth) param(list []postmanKV, key string) string {
+	for _, kv := range list {
+		if kv.Key == key {
+			return kv.String()
+		}
+	}
+	return ""
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec postmanExec `json:"exec"`
+	} `json:"script"`
+	Disabled bool `json:"disabled"`
+}
+
+// postmanExec is a script body stored either as a line array or a string.
+type postmanExec string
+
+func (e *postmanExec) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*e = postmanExec(strings.Join(lines, "\n"))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*e = postmanExec(s)
+	return nil
+}
+
+type postmanEnvironment struct {
+	Name   string      `json:"name"`
+	Values []postmanKV `json:"values"`
+}
+
+// postmanScope carries what a folder passes down to its children: auth,
+// variables and collection/folder level scripts.
+type postmanScope struct {
+	dir        string
+	auth       *postmanAuth
+	vars       []bruPair
+	preScript  []string
+	testScript []string
+}
+
+type postmanImport struct {
+	opts    Options
+	log     pslog.Logger
+	w       *collectionWriter
+	envVars map[string]string
+	count   int
+	flagged int
+}
+
+// ImportPostman converts a Postman v2.1 collection (and optional environment
+// files in opts.EnvironmentFiles) into a Bruno collection. Folders become
+// directories, auth is rendered as headers, collection variables go to
+// environments/local.bru, folder variables to vars:pre-request, and common
+// pm.* script idioms are translated to bru/test/expect.
+func ImportPostman(ctx context.Context, opts Options) error {
+	data, err := readSource(ctx, opts.Source, opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("load postman source: %w", err)
+	}
+	var col postmanCollection
+	if err := json.Unmarshal(data, &col); err != nil {
+		return fmt.Errorf("parse postman collection: %w", err)
+	}
+	if col.Info.Schema != "" && !strings.Contains(col.Info.Schema, "v2.1") && !strings.Contains(col.Info.Schema, "v2.0") {
+		return fmt.Errorf("unsupported postman schema %q (want v2.1)", col.Info.Schema)
+	}
+	if col.Info.Name == "" && len(col.Item) == 0 {
+		return fmt.Errorf("parse postman collection: no info or items (is %s an environment file?)", opts.Source)
+	}
+
+	if !opts.GenerateTestsSet {
+		opts.GenerateTests = true
+	}
+
+	log := opts.Logger
+	if log == nil {
+		log = pslog.NewWithOptions(os.Stdout, pslog.Options{Mode: pslog.ModeConsole, MinLevel: pslog.InfoLevel})
+	}
+	log = log.With("fn", pslog.CurrentFn())
+	log.Info("import.postman.start", "source", opts.Source, "output", opts.OutputDir)
+
+	collectionName := opts.CollectionName
+	if collectionName == "" {
+		collectionName = col.Info.Name
+	}
+	if collectionName == "" {
+		collectionName = "imported-postman"
+	}
+	w, err := newCollectionWriter(opts.OutputDir, collectionName)
+	if err != nil {
+		return err
+	}
+
+	imp := &postmanImport{opts: opts, log: log, w: w, envVars: map[string]string{}}
+	for _, v := range col.Variable {
+		if v.active() {
+			imp.envVars[v.Key] = v.String()
+		}
+	}
+	root := postmanScope{auth: col.Auth}
+	root.preScript, root.testScript = postmanScripts(col.Event)
+	if err := imp.walk(ctx, col.Item, root); err != nil {
+		return err
+	}
+
+	if err := writeImportSummary(opts, collectionName, "bruno"); err != nil {
+		return err
+	}
+	if opts.OutputDir != "" {
+		envPath, err := w.writeEnv("local", imp.envVars)
+		if err != nil {
+			return err
+		}
+		log.Debug("import.postman.env.write", "path", envPath, "vars", len(imp.envVars))
+		for _, envFile := range opts.EnvironmentFiles {
+			if err := imp.writeEnvironment(ctx, envFile); err != nil {
+				return err
+			}
+		}
+	}
+	if imp.flagged > 0 {
+		log.Warn("import.postman.script.review", "untranslated", imp.flagged, "hint", "search for TODO(gru) in the imported collection")
+	}
+	log.Info("import.postman.done", "output", opts.OutputDir, "requests", imp.count)
+	return nil
+}
+
+func (imp *postmanImport) walk(ctx context.Context, items []postmanItem, scope postmanScope) error {
+	seq := 0
+	for _, it := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if it.Request == nil {
+			child := scope
+			child.dir = filepath.Join(scope.dir, sanitizeFileName(it.Name))
+			if it.Auth != nil {
+				child.auth = it.Auth
+			}
+			child.vars = append(append([]bruPair{}, scope.vars...), postmanPairs(it.Variable)...)
+			pre, test := postmanScripts(it.Event)
+			child.preScript = appendNonEmpty(scope.preScript, pre...)
+			child.testScript = appendNonEmpty(scope.testScript, test...)
+			imp.log.Debug("import.postman.folder", "dir", child.dir)
+			if err := imp.walk(ctx, it.Item, child); err != nil {
+				return err
+			}
+			continue
+		}
+		seq++
+		req := imp.request(it, scope, seq)
+		filename, err := imp.w.writeRequest(scope.dir, req)
+		if err != nil {
+			return err
+		}
+		imp.count++
+		imp.log.Info("import.postman.request.write", "name", it.Name, "verb", req.Method, "file", filename)
+	}
+	return nil
+}
+
+func (imp *postmanImport) request(it postmanItem, scope postmanScope, seq int) bruRequest {
+	pr := it.Request
+	method := strings.ToUpper(pr.Method)
+	if method == "" {
+		method = "GET"
+	}
+	req := bruRequest{
+		Name:    it.Name,
+		Seq:     seq,
+		Method:  method,
+		URL:     pr.URL.String(),
+		VarsPre: scope.vars,
+		Docs:    string(pr.Description),
+	}
+	if req.Docs == "" {
+		req.Docs = string(it.Description)
+	}
+	for _, q := range pr.URL.Query {
+		req.Query = append(req.Query, bruPair{Key: q.Key, Value: q.String(), Disabled: q.Disabled})
+	}
+	for _, v := range pr.URL.Variable {
+		req.PathParams = append(req.PathParams, bruPair{Key: v.Key, Value: v.String()})
+	}
+	for _, h := range pr.Header {
+		req.Headers = append(req.Headers, bruPair{Key: h.Key, Value: h.String(), Disabled: h.Disabled})
+	}
+
+	auth := scope.auth
+	if pr.Auth != nil {
+		auth = pr.Auth
+	}
+	imp.applyAuth(&req, auth)
+
+	if b := pr.Body; b != nil && !b.Disabled {
+		imp.applyBody(&req, b)
+	}
+
+	pre, test := postmanScripts(it.Event)
+	preScripts := appendNonEmpty(scope.preScript, pre...)
+	testScripts := appendNonEmpty(scope.testScript, test...)
+	if len(preScripts) > 0 {
+		req.ScriptPre = imp.translate(it.Name, strings.Join(preScripts, "\n\n"))
+	}
+	if len(testScripts) > 0 {
+		req.Tests = imp.translate(it.Name, strings.Join(testScripts, "\n\n"))
+	} else if imp.opts.GenerateTests {
+		req.Tests = baselineTests(0, "")
+	}
+	return req
+}
+
+func (imp *postmanImport) applyAuth(req *bruRequest, a *postmanAuth) {
+	if a == nil {
+		return
+	}
+	switch strings.ToLower(a.Type) {
+	case "", "noauth", "inherit":
+	case "bearer":
+		req.Headers = append(req.Headers, bruPair{Key: "Authorization", Value: "Bearer " + a.param(a.Bearer, "token")})
+	case "oauth2":
+		token := a.param(a.OAuth2, "accessToken")
+		if token == "" {
+			token = "{{accessToken}}"
+			imp.envVars["accessToken"] = "CHANGEME"
+		}
+		req.Headers = append(req.Headers, bruPair{Key: "Authorization", Value: "Bearer " + token})
+	case "basic":
+		user, pass := a.param(a.Basic, "username"), a.param(a.Basic, "password")
+		req.Headers = append(req.Headers, bruPair{Key: "Authorization", Value: "Basic {{basicAuth}}"})
+		cred, ok := basicCredential(user, pass)
+		imp.envVars["basicAuth"] = cred
+		if !ok {
+			imp.log.Warn("import.postman.auth.basic.templated", "request", req.Name, "hint", "set basicAuth to base64(username:password)")
+		}
+	case "apikey":
+		key, value := a.param(a.APIKey, "key"), a.param(a.APIKey, "value")
+		if key == "" {
+			key = "X-API-Key"
+		}
+		if strings.EqualFold(a.param(a.APIKey, "in"), "query") {
+			req.Query = append(req.Query, bruPair{Key: key, Value: value})
+		} else {
+			req.Headers = append(req.Headers, bruPair{Key: key, Value: value})
+		}
+	default:
+		imp.log.Warn("import.postman.auth.unsupported", "request", req.Name, "type", a.Type)
+		req.Docs = strings.TrimSpace(req.Docs + "\n\nTODO(gru): Postman auth type " + a.Type + " was not imported.")
+	}
+}
+
+func (imp *postmanImport) applyBody(req *bruRequest, b *postmanBody) {
+	switch b.Mode {
+	case "raw":
+		if strings.TrimSpace(b.Raw) == "" {
+			return
+		}
+		switch strings.ToLower(b.Options.Raw.Language) {
+		case "json":
+			req.BodyKind = "json"
+		case "xml", "html":
+			req.BodyKind = "xml"
+		case "":
+			req.BodyKind = bodyKindForContentType(headerValue(req.Headers, "Content-Type"))
+		default:
+			req.BodyKind = "text"
+		}
+		req.Body = b.Raw
+	case "urlencoded":
+		req.BodyKind = "form-urlencoded"
+		for _, kv := range b.URLEncoded {
+			req.Form = append(req.Form, bruPair{Key: kv.Key, Value: kv.String(), Disabled: kv.Disabled})
+		}
+	case "formdata":
+		req.BodyKind = "multipart-form"
+		for _, kv := range b.FormData {
+			value := kv.String()
+			if kv.Type == "file" {
+				value = "@" + postmanSrc(kv.Src)
+			}
+			req.Form = append(req.Form, bruPair{Key: kv.Key, Value: value, Disabled: kv.Disabled})
+		}
+	case "graphql":
+		if b.GraphQL != nil {
+			req.BodyKind = "graphql"
+			req.Body = b.GraphQL.Query
+			req.GraphqlVars = b.GraphQL.Variables
+		}
+	case "file":
+		src := ""
+		if b.File != nil {
+			src = b.File.Src
+		}
+		imp.log.Warn("import.postman.body.file", "request", req.Name, "src", src)
+		req.Docs = strings.TrimSpace(req.Docs + "\n\nTODO(gru): binary file body " + src + " was not imported.")
+	}
+}
+
+func (imp *postmanImport) translate(name, code string) string {
+	out, flagged := translatePostmanScript(code)
+	for _, line := range flagged {
+		imp.log.Warn("import.postman.script.untranslated", "request", name, "line", line)
+	}
+	imp.flagged += len(flagged)
+	return out
+}
+
+func (imp *postmanImport) writeEnvironment(ctx context.Context, path string) error {
+	data, err := readSource(ctx, path, imp.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("load postman environment: %w", err)
+	}
+	var env postmanEnvironment
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("parse postman environment %s: %w", path, err)
+	}
+	name := env.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	vars := map[string]string{}
+	for k, v := range imp.envVars {
+		vars[k] = v
+	}
+	for _, v := range env.Values {
+		if v.active() {
+			vars[v.Key] = v.String()
+		}
+	}
+	envPath, err := imp.w.writeEnv(sanitizeFileName(name), vars)
+	if err != nil {
+		return err
+	}
+	imp.log.Info("import.postman.env.write", "name", name, "path", envPath, "vars", len(vars))
+	return nil
+}
+
+func postmanScripts(events []postmanEvent) (pre, test []string) {
+	for _, ev := range events {
+		code := strings.TrimSpace(string(ev.Script.Exec))
+		if ev.Disabled || code == "" {
+			continue
+		}
+		switch ev.Listen {
+		case "prerequest":
+			pre = append(pre, code)
+		case "test":
+			test = append(test, code)
+		}
+	}
+	return pre, test
+}
+
+func postmanPairs(kvs []postmanKV) []bruPair {
+	var out []bruPair
+	for _, kv := range kvs {
+		if kv.active() {
+			out = append(out, bruPair{Key: kv.Key, Value: kv.String()})
+		}
+	}
+	return out
+}
+
+func postmanSrc(src any) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			return fmt.Sprint(v[0])
+		}
+	}
+	return ""
+}
+
+func appendNonEmpty(base []string, more ...string) []string {
+	out := append([]string{}, base...)
+	for _, m := range more {
+		if strings.TrimSpace(m) != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func headerValue(pairs []bruPair, name string) string {
+	for _, p := range pairs {
+		if strings.EqualFold(p.Key, name) && !p.Disabled {
+			return p.Value
+		}
+	}
+	return ""
+}
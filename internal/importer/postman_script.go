@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// postmanRewrite is one pm.* idiom and its Bruno/gru equivalent. Rules run in
+// order, so more specific patterns come first.
+type postmanRewrite struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+var postmanRewrites = []postmanRewrite{
+	{regexp.MustCompile(`pm\.response\.to\.have\.status\(\s*(\d+)\s*\)`), `expect(res.status).to.equal($1)`},
+	{regexp.MustCompile(`pm\.response\.to\.be\.(ok|success)\b`), `expect(res.status).to.be.within(200, 299)`},
+	{regexp.MustCompile(`pm\.response\.to\.have\.header\(\s*([^)]+?)\s*\)`), `expect(res.headers[String($1).toLowerCase()]).to.exist`},
+	{regexp.MustCompile(`pm\.response\.headers\.get\(\s*([^)]+?)\s*\)`), `res.headers[String($1).toLowerCase()]`},
+	{regexp.MustCompile(`pm\.response\.json\(\s*\)`), `res.body`},
+	{regexp.MustCompile(`JSON\.parse\(\s*responseBody\s*\)`), `res.body`},
+	{regexp.MustCompile(`pm\.response\.text\(\s*\)`), `res.text()`},
+	{regexp.MustCompile(`pm\.response\.code\b`), `res.status`},
+	{regexp.MustCompile(`\bresponseCode\.code\b`), `res.status`},
+	{regexp.MustCompile(`pm\.response\.responseTime\b`), `res.getResponseTime()`},
+	{regexp.MustCompile(`pm\.environment\.set\(`), `bru.setEnvVar(`},
+	{regexp.MustCompile(`pm\.environment\.get\(`), `bru.getEnvVar(`},
+	{regexp.MustCompile(`pm\.(?:collectionVariables|variables|globals)\.set\(`), `bru.setVar(`},
+	{regexp.MustCompile(`pm\.(?:collectionVariables|variables|globals)\.get\(`), `bru.getVar(`},
+	{regexp.MustCompile(`pm\.iterationData\.get\(`), `bru.runner.iterationData.get(`},
+	{regexp.MustCompile(`pm\.info\.iteration\b`), `bru.runner.iterationIndex`},
+	{regexp.MustCompile(`pm\.info\.iterationCount\b`), `bru.runner.totalIterations`},
+	{regexp.MustCompile(`pm\.test\(`), `test(`},
+	{regexp.MustCompile(`pm\.expect\(`), `expect(`},
+	{regexp.MustCompile(`postman\.setEnvironmentVariable\(`), `bru.setEnvVar(`},
+	{regexp.MustCompile(`postman\.getEnvironmentVariable\(`), `bru.getEnvVar(`},
+	{regexp.MustCompile(`postman\.setGlobalVariable\(`), `bru.setVar(`},
+	{regexp.MustCompile(`postman\.getGlobalVariable\(`), `bru.getVar(`},
+}
+
+// legacyTestAssign matches the pre-pm sandbox idiom: tests["name"] = expr;
+var legacyTestAssign = regexp.MustCompile(`^(\s*)tests\[\s*(["'].*?["'])\s*\]\s*=\s*(.+?);?\s*$`)
+
+// postmanLeftover finds pm/postman API use that no rule translated.
+var postmanLeftover = regexp.MustCompile(`\b(?:pm|postman)\.[A-Za-z_]`)
+
+const postmanTodo = "// TODO(gru): untranslated Postman API, review:"
+
+// translatePostmanScript rewrites common pm.* idioms into Bruno test/expect/bru
+// calls. Lines still using the Postman API are kept as-is, preceded by a
+// TODO(gru) marker, and returned so the caller can report them.
+func translatePostmanScript(code string) (string, []string) {
+	lines := strings.Split(code, "\n")
+	var out, flagged []string
+	for _, line := range lines {
+		if m := legacyTestAssign.FindStringSubmatch(line); m != nil {
+			line = m[1] + "test(" + m[2] + ", function() { expect(" + m[3] + ").to.equal(true); });"
+		}
+		for _, rw := range postmanRewrites {
+			line = rw.re.ReplaceAllString(line, rw.repl)
+		}
+		if postmanLeftover.MatchString(line) && !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			out = append(out, indent+postmanTodo)
+			flagged = append(flagged, strings.TrimSpace(line))
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n"), flagged
+}
@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/internal/runner"
+)
+
+const postmanFixture = `{
+  "info": {"name": "Legacy Suite", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "http://placeholder"}, {"key": "token", "value": "t0k"}],
+  "event": [{"listen": "test", "script": {"exec": ["pm.test(\"collection check\", function () {", "  pm.expect(pm.response.code).to.be.below(500);", "});"]}}],
+  "item": [
+    {
+      "name": "Users",
+      "variable": [{"key": "userId", "value": "42"}],
+      "item": [
+        {
+          "name": "Create user",
+          "event": [{"listen": "test", "script": {"exec": [
+            "pm.test(\"created\", function () {",
+            "  pm.response.to.have.status(201);",
+            "  const body = pm.response.json();",
+            "  pm.expect(body.name).to.eql(\"ada\");",
+            "  pm.environment.set(\"createdId\", body.id);",
+            "});"
+          ]}}],
+          "request": {
+            "method": "POST",
+            "header": [{"key": "Content-Type", "value": "application/json"}, {"key": "X-Debug", "value": "1", "disabled": true}],
+            "url": {"raw": "{{baseUrl}}/users", "host": ["{{baseUrl}}"], "path": ["users"]},
+            "body": {"mode": "raw", "raw": "{\"name\":\"ada\"}", "options": {"raw": {"language": "json"}}}
+          }
+        },
+        {
+          "name": "Get user",
+          "event": [{"listen": "test", "script": {"exec": [
+            "pm.test(\"reads the environment\", function () {",
+            "  pm.expect(String(pm.environment.get(\"createdId\"))).to.eql(\"7\");",
+            "  pm.expect(postman.getEnvironmentVariable(\"baseUrl\")).to.match(/^http/);",
+            "});"
+          ]}}],
+          "request": {
+            "method": "GET",
+            "url": {"raw": "{{baseUrl}}/users/:id?verbose=true", "query": [{"key": "verbose", "value": "true"}], "variable": [{"key": "id", "value": "{{userId}}"}]}
+          }
+        },
+        {
+          "name": "Login form",
+          "request": {
+            "method": "POST",
+            "auth": {"type": "basic", "basic": [{"key": "username", "value": "ada"}, {"key": "password", "value": "pw"}]},
+            "url": "{{baseUrl}}/login",
+            "body": {"mode": "urlencoded", "urlencoded": [{"key": "remember", "value": "yes"}]}
+          },
+          "event": [{"listen": "prerequest", "script": {"exec": "pm.sendRequest(\"{{baseUrl}}/warmup\", function () {});"}}]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestImportPostmanCollectionAndRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/users":
+			if r.Header.Get("Authorization") != "Bearer t0k" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":7,` + strings.TrimPrefix(string(body), "{")))
+		case r.URL.Path == "/users/42" && r.URL.Query().Get("verbose") == "true":
+			_, _ = w.Write([]byte(`{"id":42}`))
+		case r.URL.Path == "/login":
+			user, pass, _ := r.BasicAuth()
+			_ = r.ParseForm()
+			if user != "ada" || pass != "pw" || r.PostForm.Get("remember") != "yes" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "legacy.postman_collection.json")
+	if err := os.WriteFile(src, []byte(postmanFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	envSrc := filepath.Join(tmp, "ci.postman_environment.json")
+	if err := os.WriteFile(envSrc, []byte(`{"name":"ci","values":[{"key":"baseUrl","value":"`+srv.URL+`","enabled":true},{"key":"unused","value":"x","enabled":false}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(tmp, "out")
+	if err := ImportPostman(context.Background(), Options{Source: src, OutputDir: out, EnvironmentFiles: []string{envSrc}}); err != nil {
+		t.Fatalf("import postman: %v", err)
+	}
+
+	create, err := os.ReadFile(filepath.Join(out, "Users", "Create_user.bru"))
+	if err != nil {
+		t.Fatalf("read create: %v", err)
+	}
+	for _, want := range []string{
+		"Authorization: Bearer {{token}}",
+		"~X-Debug: 1",
+		"userId: 42",
+		`test("created", function () {`,
+		"expect(res.status).to.equal(201);",
+		"const body = res.body;",
+		`bru.setEnvVar("createdId", body.id);`,
+		`test("collection check", function () {`,
+	} {
+		if !strings.Contains(string(create), want) {
+			t.Fatalf("create user missing %q:\n%s", want, create)
+		}
+	}
+	get, _ := os.ReadFile(filepath.Join(out, "Users", "Get_user.bru"))
+	if !strings.Contains(string(get), `bru.getEnvVar("createdId")`) || !strings.Contains(string(get), `bru.getEnvVar("baseUrl")`) {
+		t.Fatalf("get user should read env vars with bru.getEnvVar:\n%s", get)
+	}
+	login, _ := os.ReadFile(filepath.Join(out, "Users", "Login_form.bru"))
+	if !strings.Contains(string(login), postmanTodo) || !strings.Contains(string(login), "Basic {{basicAuth}}") {
+		t.Fatalf("login should flag pm.sendRequest and use basic auth:\n%s", login)
+	}
+	env, _ := os.ReadFile(filepath.Join(out, "environments", "ci.bru"))
+	if !strings.Contains(string(env), "baseUrl: "+srv.URL) || !strings.Contains(string(env), "token: t0k") || strings.Contains(string(env), "unused") {
+		t.Fatalf("unexpected ci env:\n%s", env)
+	}
+
+	// The untranslated pre-request line would fail at runtime; drop it and run the rest.
+	cleaned := strings.ReplaceAll(string(login), `pm.sendRequest("{{baseUrl}}/warmup", function () {});`, "")
+	if err := os.WriteFile(filepath.Join(out, "Users", "Login_form.bru"), []byte(cleaned), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := runner.New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFolder(context.Background(), out, runner.RunOptions{EnvPath: filepath.Join(out, "environments", "ci.bru"), Recursive: true, RecursiveSet: true})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Total != 3 || sum.Failed != 0 {
+		for _, c := range sum.Cases {
+			t.Logf("%s passed=%v err=%s failures=%+v", c.Name, c.Passed, c.ErrorText, c.Failures)
+		}
+		t.Fatalf("expected imported collection to pass, got %+v", sum)
+	}
+}
+
+func TestTranslatePostmanScript(t *testing.T) {
+	in := strings.Join([]string{
+		`tests["Status code is 200"] = responseCode.code === 200;`,
+		`var ct = pm.response.headers.get("Content-Type");`,
+		`pm.collectionVariables.set("n", pm.iterationData.get("n"));`,
+		`pm.environment.set("token", pm.environment.get("seed"));`,
+		`postman.getEnvironmentVariable("host");`,
+		`pm.environment.unset("old");`,
+	}, "\n")
+	got, flagged := translatePostmanScript(in)
+	for _, want := range []string{
+		`test("Status code is 200", function() { expect(res.status === 200).to.equal(true); });`,
+		`var ct = res.headers[String("Content-Type").toLowerCase()];`,
+		`bru.setVar("n", bru.runner.iterationData.get("n"));`,
+		`bru.setEnvVar("token", bru.getEnvVar("seed"));`,
+		`bru.getEnvVar("host");`,
+		postmanTodo + "\n" + `pm.environment.unset("old");`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("translation missing %q:\n%s", want, got)
+		}
+	}
+	if len(flagged) != 1 {
+		t.Fatalf("expected one flagged line, got %v", flagged)
+	}
+}
@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -26,11 +27,15 @@ func insecureHTTPClient() *http.Client {
 	}
 }
 
-func fetchWithClient(src string, client *http.Client) ([]byte, error) {
+func fetchWithClient(ctx context.Context, src string, client *http.Client) ([]byte, error) {
 	if client == nil {
 		client = http.DefaultClient
 	}
-	resp, err := client.Get(src)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +46,18 @@ func fetchWithClient(src string, client *http.Client) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// readSource loads an import source from a URL or the local filesystem.
+func readSource(ctx context.Context, source string, insecure bool) ([]byte, error) {
+	switch {
+	case isURL(source) && insecure:
+		return fetchWithClient(ctx, source, insecureHTTPClient())
+	case isURL(source):
+		return fetchWithClient(ctx, source, nil)
+	default:
+		return os.ReadFile(source)
+	}
+}
+
 func isURL(s string) bool {
 	u, err := url.Parse(s)
 	return err == nil && u.Scheme != "" && u.Host != ""
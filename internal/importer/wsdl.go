@@ -83,29 +83,15 @@ func ImportWSDL(ctx context.Context, opts Options) error {
 		return fmt.Errorf("--source is required")
 	}
 
-	var data []byte
-	var err error
-	switch {
-	case isURL(opts.Source) && opts.Insecure:
-		data, err = fetchWithClient(opts.Source, insecureHTTPClient())
-	case isURL(opts.Source):
-		data, err = fetchWithClient(opts.Source, nil)
-	default:
-		data, err = os.ReadFile(opts.Source)
-	}
+	def, err := loadWSDL(ctx, opts.Source, opts.Insecure)
 	if err != nil {
-		return fmt.Errorf("load wsdl: %w", err)
+		return err
 	}
 
 	if !opts.GenerateTestsSet {
 		opts.GenerateTests = true
 	}
 
-	var def wsdlDefinitions
-	if err := xml.Unmarshal(data, &def); err != nil {
-		return fmt.Errorf("parse wsdl: %w", err)
-	}
-
 	if opts.OutputDir != "" {
 		if err := os.MkdirAll(opts.OutputDir, 0o755); err != nil {
 			return err
@@ -121,6 +107,7 @@ func ImportWSDL(ctx context.Context, opts Options) error {
 	}
 
 	typeIndex := buildElementIndex(def)
+	opElements := operationElements(def)
 
 	baseURL := firstAddress(def)
 	if baseURL == "" {
@@ -158,7 +145,7 @@ func ImportWSDL(ctx context.Context, opts Options) error {
 			envelope := soapEnvelope(def.TargetNamespace, opName)
 			testsBlock := ""
 			if opts.GenerateTests {
-				if tb := buildWSDLTests(opName, typeIndex); tb != "" {
+				if tb := buildWSDLTests(opName, opElements[opName].output, typeIndex); tb != "" {
 					testsBlock = tb
 				} else {
 					testsBlock = defaultWSDLTests(opName)
@@ -167,21 +154,27 @@ func ImportWSDL(ctx context.Context, opts Options) error {
 				testsBlock = defaultWSDLTests(opName)
 			}
 
-			bru := fmt.Sprintf(`meta { name: %s, type: http }
+			bru := fmt.Sprintf(`meta {
+  name: %s
+  type: http
+}
 
 post {
   url: {{baseUrl}}
-  headers {
-    Content-Type: text/xml
-    SOAPAction: "%s"
-  }
-  body:xml {
+  body: xml
+}
+
+headers {
+  Content-Type: text/xml
+  SOAPAction: "%s"
+}
+
+body:xml {
 %s
-  }
 }
 
 %s
-`, opName, action, indent(envelope, "    "), testsBlock)
+`, opName, action, indent(envelope, "  "), testsBlock)
 			filename := filepath.Join(dir, sanitizeFileName(opName)+".bru")
 			if err := writeFile(filename, bru); err != nil {
 				return err
@@ -200,14 +193,54 @@ post {
 	return nil
 }
 
+// loadWSDL reads a WSDL from a path or URL and decodes the supported subset.
+func loadWSDL(ctx context.Context, source string, insecure bool) (wsdlDefinitions, error) {
+	data, err := readSource(ctx, source, insecure)
+	if err != nil {
+		return wsdlDefinitions{}, fmt.Errorf("load wsdl: %w", err)
+	}
+	var def wsdlDefinitions
+	if err := xml.Unmarshal(data, &def); err != nil {
+		return wsdlDefinitions{}, fmt.Errorf("parse wsdl: %w", err)
+	}
+	return def, nil
+}
+
 // Simple WSDL structs (subset).
 type wsdlDefinitions struct {
-	XMLName         xml.Name      `xml:"definitions"`
-	Name            string        `xml:"name,attr"`
-	TargetNamespace string        `xml:"targetNamespace,attr"`
-	Services        []wsdlService `xml:"service"`
-	Bindings        []wsdlBinding `xml:"binding"`
-	Types           []xsdSchema   `xml:"types>schema"`
+	XMLName         xml.Name       `xml:"definitions"`
+	Name            string         `xml:"name,attr"`
+	TargetNamespace string         `xml:"targetNamespace,attr"`
+	Services        []wsdlService  `xml:"service"`
+	Bindings        []wsdlBinding  `xml:"binding"`
+	Messages        []wsdlMessage  `xml:"message"`
+	PortTypes       []wsdlPortType `xml:"portType"`
+	Types           []xsdSchema    `xml:"types>schema"`
+}
+
+type wsdlMessage struct {
+	Name  string        `xml:"name,attr"`
+	Parts []wsdlMsgPart `xml:"part"`
+}
+
+type wsdlMsgPart struct {
+	Name    string `xml:"name,attr"`
+	Element string `xml:"element,attr"`
+}
+
+type wsdlPortType struct {
+	Name       string       `xml:"name,attr"`
+	Operations []wsdlPortOp `xml:"operation"`
+}
+
+type wsdlPortOp struct {
+	Name   string        `xml:"name,attr"`
+	Input  wsdlOpMessage `xml:"input"`
+	Output wsdlOpMessage `xml:"output"`
+}
+
+type wsdlOpMessage struct {
+	Message string `xml:"message,attr"`
 }
 
 type wsdlService struct {
@@ -240,10 +273,11 @@ type wsdlBindingOp struct {
 
 // XSD subset for schema-driven assertions.
 type xsdSchema struct {
-	TargetNamespace string           `xml:"targetNamespace,attr"`
-	Elements        []xsdElement     `xml:"element"`
-	SimpleTypes     []xsdSimpleType  `xml:"simpleType"`
-	ComplexTypes    []xsdComplexType `xml:"complexType"`
+	TargetNamespace    string           `xml:"targetNamespace,attr"`
+	ElementFormDefault string           `xml:"elementFormDefault,attr"`
+	Elements           []xsdElement     `xml:"element"`
+	SimpleTypes        []xsdSimpleType  `xml:"simpleType"`
+	ComplexTypes       []xsdComplexType `xml:"complexType"`
 }
 
 type xsdElement struct {
@@ -295,6 +329,42 @@ type xsdValue struct {
 	Value string `xml:"value,attr"`
 }
 
+// wsdlOpElements names the top-level schema elements carried by an operation's messages.
+type wsdlOpElements struct {
+	input  string
+	output string
+}
+
+// operationElements resolves portType input/output messages to element local names.
+func operationElements(def wsdlDefinitions) map[string]wsdlOpElements {
+	messages := map[string]wsdlMessage{}
+	for _, msg := range def.Messages {
+		messages[msg.Name] = msg
+	}
+	messageElement := func(ref string) string {
+		msg, ok := messages[localName(ref)]
+		if !ok {
+			return ""
+		}
+		for _, part := range msg.Parts {
+			if part.Element != "" {
+				return localName(part.Element)
+			}
+		}
+		return ""
+	}
+	out := map[string]wsdlOpElements{}
+	for _, pt := range def.PortTypes {
+		for _, op := range pt.Operations {
+			out[op.Name] = wsdlOpElements{
+				input:  messageElement(op.Input.Message),
+				output: messageElement(op.Output.Message),
+			}
+		}
+	}
+	return out
+}
+
 func firstAddress(def wsdlDefinitions) string {
 	for _, s := range def.Services {
 		for _, p := range s.Ports {
@@ -361,8 +431,11 @@ func buildElementIndex(def wsdlDefinitions) map[string]xsdElement {
 	return index
 }
 
-func buildWSDLTests(opName string, index map[string]xsdElement) string {
-	respEl, ok := index[opName+"Response"]
+func buildWSDLTests(opName, outputName string, index map[string]xsdElement) string {
+	if outputName == "" {
+		outputName = opName + "Response"
+	}
+	respEl, ok := index[outputName]
 	if !ok || respEl.ComplexType == nil {
 		return defaultWSDLTests(opName)
 	}
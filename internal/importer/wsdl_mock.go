@@ -0,0 +1,511 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pkt.systems/pslog"
+)
+
+const (
+	soap11EnvelopeNS = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12EnvelopeNS = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// MockOptions configures the WSDL-backed SOAP mock responder.
+type MockOptions struct {
+	Source   string
+	Insecure bool
+	// Faults maps operation names to the SOAP Fault returned instead of a response envelope.
+	Faults map[string]MockFault
+	// MTOM serves base64Binary response elements as multipart/related XOP attachments.
+	MTOM bool
+	// Logger receives per-request dispatch logs; nil discards them.
+	Logger pslog.Logger
+}
+
+// MockFault describes a SOAP Fault served for an operation.
+type MockFault struct {
+	Code   string // faultcode (SOAP 1.1) or Code/Value (SOAP 1.2); defaults to Server/Receiver
+	String string // faultstring / Reason text
+	Detail string // optional raw XML placed inside the detail element
+	Status int    // HTTP status; defaults to 500
+}
+
+// WSDLMock is an http.Handler answering SOAP requests with schema-shaped envelopes.
+type WSDLMock struct {
+	def     wsdlDefinitions
+	index   map[string]xsdElement
+	forms   map[string]xsdForm
+	ops     map[string]mockOperation
+	actions map[string]string // soapAction -> operation
+	inputs  map[string]string // input element local name -> operation
+	opts    MockOptions
+	log     pslog.Logger
+}
+
+type mockOperation struct {
+	name     string
+	action   string
+	output   string
+	outputNS string
+}
+
+// xsdForm records namespace placement for a top-level element.
+type xsdForm struct {
+	namespace string
+	qualified bool
+}
+
+// ParseMockFault parses "message", "code|message" or "code|message|status" into a MockFault.
+func ParseMockFault(spec string) (MockFault, error) {
+	parts := strings.Split(spec, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	switch len(parts) {
+	case 1:
+		return MockFault{String: parts[0]}, nil
+	case 2:
+		return MockFault{Code: parts[0], String: parts[1]}, nil
+	case 3:
+		status, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return MockFault{}, fmt.Errorf("fault status %q: %w", parts[2], err)
+		}
+		return MockFault{Code: parts[0], String: parts[1], Status: status}, nil
+	default:
+		return MockFault{}, fmt.Errorf("invalid fault spec %q (want code|message|status)", spec)
+	}
+}
+
+// NewWSDLMock loads a WSDL and prepares a SOAP responder for its operations.
+func NewWSDLMock(ctx context.Context, opts MockOptions) (*WSDLMock, error) {
+	if opts.Source == "" {
+		return nil, fmt.Errorf("wsdl mock: MockOptions.Source is required")
+	}
+	def, err := loadWSDL(ctx, opts.Source, opts.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	log := opts.Logger
+	if log == nil {
+		log = pslog.New(io.Discard)
+	}
+	log = log.With("fn", pslog.CurrentFn())
+
+	m := &WSDLMock{
+		def:     def,
+		index:   buildElementIndex(def),
+		forms:   map[string]xsdForm{},
+		ops:     map[string]mockOperation{},
+		actions: map[string]string{},
+		inputs:  map[string]string{},
+		opts:    opts,
+		log:     log,
+	}
+	for _, s := range def.Types {
+		for _, el := range s.Elements {
+			m.forms[el.Name] = xsdForm{namespace: s.TargetNamespace, qualified: s.ElementFormDefault == "qualified"}
+		}
+	}
+
+	elements := operationElements(def)
+	for _, b := range def.Bindings {
+		for _, bop := range b.Operations {
+			if bop.Name == "" {
+				continue
+			}
+			op := mockOperation{name: bop.Name, action: strings.Trim(bop.Soap.Action, `"`)}
+			if els, ok := elements[bop.Name]; ok {
+				op.output = els.output
+				if els.input != "" {
+					m.inputs[els.input] = bop.Name
+				}
+			}
+			if op.output == "" {
+				op.output = bop.Name + "Response"
+			}
+			op.outputNS = def.TargetNamespace
+			if form, ok := m.forms[op.output]; ok && form.namespace != "" {
+				op.outputNS = form.namespace
+			}
+			m.ops[op.name] = op
+			m.inputs[op.name] = op.name
+			if op.action != "" {
+				m.actions[op.action] = op.name
+			}
+		}
+	}
+	for name := range opts.Faults {
+		if _, ok := m.ops[name]; !ok {
+			return nil, fmt.Errorf("fault configured for unknown operation %q", name)
+		}
+	}
+	log.Info("mock.wsdl.loaded", "source", opts.Source, "operations", len(m.ops), "mtom", opts.MTOM, "faults", len(opts.Faults))
+	return m, nil
+}
+
+// Operations lists the operation names the mock can answer, sorted.
+func (m *WSDLMock) Operations() []string {
+	names := make([]string, 0, len(m.ops))
+	for name := range m.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP dispatches on SOAPAction (or the SOAP 1.2 action parameter) and
+// falls back to the local name of the first element inside soap:Body.
+func (m *WSDLMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	envNS, root := soapBodyRoot(body)
+	soap12 := envNS == soap12EnvelopeNS || strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "application/soap+xml")
+	if envNS == "" {
+		envNS = soap11EnvelopeNS
+		if soap12 {
+			envNS = soap12EnvelopeNS
+		}
+	}
+
+	opName, via := m.dispatch(r, root)
+	if opName == "" {
+		m.log.Warn("mock.wsdl.unmatched", "action", requestAction(r), "root", root)
+		code := "soap:Client"
+		if soap12 {
+			code = "soap:Sender"
+		}
+		m.writeFault(w, envNS, soap12, MockFault{Code: code, String: fmt.Sprintf("no operation matches action %q or element %q", requestAction(r), root), Status: http.StatusInternalServerError})
+		return
+	}
+	m.log.Info("mock.wsdl.request", "op", opName, "via", via)
+
+	if fault, ok := m.opts.Faults[opName]; ok {
+		m.writeFault(w, envNS, soap12, fault)
+		return
+	}
+
+	op := m.ops[opName]
+	rc := &renderContext{index: m.index, mtom: m.opts.MTOM}
+	payload := m.renderOutput(op, rc)
+	// Namespaces are declared on the Envelope so payload elements stay attribute-free.
+	envelope := fmt.Sprintf(`<soap:Envelope xmlns:soap="%s" xmlns:tns="%s"><soap:Body>%s</soap:Body></soap:Envelope>`, envNS, op.outputNS, payload)
+
+	contentType := "text/xml; charset=utf-8"
+	if soap12 {
+		contentType = "application/soap+xml; charset=utf-8"
+	}
+	if !m.opts.MTOM || len(rc.attachments) == 0 {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(envelope))
+		return
+	}
+	m.writeMTOM(w, envelope, contentType, rc.attachments)
+}
+
+func (m *WSDLMock) dispatch(r *http.Request, root string) (string, string) {
+	if action := requestAction(r); action != "" {
+		if op, ok := m.actions[action]; ok {
+			return op, "action"
+		}
+	}
+	if root != "" {
+		if op, ok := m.inputs[root]; ok {
+			return op, "element"
+		}
+	}
+	return "", ""
+}
+
+func (m *WSDLMock) renderOutput(op mockOperation, rc *renderContext) string {
+	el, ok := m.index[op.output]
+	if !ok {
+		return fmt.Sprintf(`<tns:%s><result>ok</result></tns:%s>`, op.output, op.output)
+	}
+	form := m.forms[op.output]
+	rc.prefix = ""
+	if form.qualified {
+		rc.prefix = "tns:"
+	}
+	inner := renderElementValue(el, rc, 1)
+	return fmt.Sprintf(`<tns:%s>%s</tns:%s>`, op.output, inner, op.output)
+}
+
+func (m *WSDLMock) writeFault(w http.ResponseWriter, envNS string, soap12 bool, f MockFault) {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	msg := f.String
+	if msg == "" {
+		msg = "mock fault"
+	}
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(msg))
+	escaped := buf.String()
+
+	var fault string
+	if soap12 {
+		code := f.Code
+		if code == "" {
+			code = "soap:Receiver"
+		}
+		detail := ""
+		if f.Detail != "" {
+			detail = "<soap:Detail>" + f.Detail + "</soap:Detail>"
+		}
+		fault = fmt.Sprintf(`<soap:Fault><soap:Code><soap:Value>%s</soap:Value></soap:Code><soap:Reason><soap:Text xml:lang="en">%s</soap:Text></soap:Reason>%s</soap:Fault>`, code, escaped, detail)
+		w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	} else {
+		code := f.Code
+		if code == "" {
+			code = "soap:Server"
+		}
+		detail := ""
+		if f.Detail != "" {
+			detail = "<detail>" + f.Detail + "</detail>"
+		}
+		fault = fmt.Sprintf(`<soap:Fault><faultcode>%s</faultcode><faultstring>%s</faultstring>%s</soap:Fault>`, code, escaped, detail)
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `<soap:Envelope xmlns:soap="%s"><soap:Body>%s</soap:Body></soap:Envelope>`, envNS, fault)
+}
+
+func (m *WSDLMock) writeMTOM(w http.ResponseWriter, envelope, rootType string, attachments []mtomAttachment) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	soapType, _, _ := mime.ParseMediaType(rootType)
+
+	rootHdr := make(textproto.MIMEHeader)
+	rootHdr.Set("Content-Type", fmt.Sprintf(`application/xop+xml; charset=UTF-8; type="%s"`, soapType))
+	rootHdr.Set("Content-Transfer-Encoding", "8bit")
+	rootHdr.Set("Content-ID", "<rootpart@gru>")
+	pw, _ := mw.CreatePart(rootHdr)
+	_, _ = pw.Write([]byte(envelope))
+
+	for _, a := range attachments {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Type", "application/octet-stream")
+		h.Set("Content-Transfer-Encoding", "binary")
+		h.Set("Content-ID", "<"+a.id+">")
+		pw, _ := mw.CreatePart(h)
+		_, _ = pw.Write(a.data)
+	}
+	_ = mw.Close()
+
+	w.Header().Set("Content-Type", fmt.Sprintf(`multipart/related; type="application/xop+xml"; start="<rootpart@gru>"; start-info="%s"; boundary=%s`, soapType, mw.Boundary()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// requestAction returns the SOAP 1.1 SOAPAction header or the SOAP 1.2 action parameter.
+func requestAction(r *http.Request) string {
+	if a := strings.Trim(r.Header.Get("SOAPAction"), `" `); a != "" {
+		return a
+	}
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		return strings.Trim(params["action"], `" `)
+	}
+	return ""
+}
+
+// soapBodyRoot returns the envelope namespace and the local name of the first element inside Body.
+func soapBodyRoot(body []byte) (string, string) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	envNS := ""
+	inBody := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return envNS, ""
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case se.Name.Local == "Envelope" && envNS == "":
+			envNS = se.Name.Space
+		case se.Name.Local == "Body" && !inBody:
+			inBody = true
+		case inBody:
+			return envNS, se.Name.Local
+		}
+	}
+}
+
+type mtomAttachment struct {
+	id   string
+	data []byte
+}
+
+// renderContext carries state while rendering schema-shaped sample XML.
+type renderContext struct {
+	index       map[string]xsdElement
+	prefix      string
+	mtom        bool
+	attachments []mtomAttachment
+}
+
+func renderElement(el xsdElement, rc *renderContext, depth int) string {
+	if depth > 4 {
+		return ""
+	}
+	name := el.Name
+	if name == "" {
+		name = localName(el.Type)
+	}
+	if name == "" {
+		name = "Value"
+	}
+
+	min := occursToInt(el.MinOccurs, 1)
+	var b strings.Builder
+	for range min {
+		b.WriteString("<" + rc.prefix + name + ">")
+		b.WriteString(renderElementValue(el, rc, depth+1))
+		b.WriteString("</" + rc.prefix + name + ">")
+	}
+	return b.String()
+}
+
+func renderElementValue(el xsdElement, rc *renderContext, depth int) string {
+	// Prefer inline complex/simple definitions
+	if el.ComplexType != nil {
+		return renderComplex(el.ComplexType, rc, depth)
+	}
+	if el.SimpleType != nil {
+		return rc.renderSimple(resolveRestriction(el.SimpleType.Restriction, rc.index))
+	}
+
+	// Named type reference
+	if el.Type != "" {
+		if ref, ok := rc.index[localName(el.Type)]; ok {
+			if ref.ComplexType != nil {
+				return renderComplex(ref.ComplexType, rc, depth)
+			}
+			if ref.SimpleType != nil {
+				return rc.renderSimple(resolveRestriction(ref.SimpleType.Restriction, rc.index))
+			}
+		}
+		base := localName(el.Type)
+		return rc.renderSimple(xsdRestriction{Base: base})
+	}
+
+	// Fallback
+	return "value"
+}
+
+func renderComplex(ct *xsdComplexType, rc *renderContext, depth int) string {
+	var b strings.Builder
+	for _, child := range ct.Sequence.Elements {
+		b.WriteString(renderElement(child, rc, depth+1))
+	}
+	return b.String()
+}
+
+// renderSimple renders a sample value; with MTOM enabled binary values become XOP includes.
+func (rc *renderContext) renderSimple(r xsdRestriction) string {
+	base := localName(r.Base)
+	val := renderBaseValue(base, r)
+	if rc.mtom && base == "base64Binary" {
+		raw, err := base64.StdEncoding.DecodeString(val)
+		if err == nil {
+			id := "attachment" + strconv.Itoa(len(rc.attachments)+1) + "@gru"
+			rc.attachments = append(rc.attachments, mtomAttachment{id: id, data: raw})
+			return fmt.Sprintf(`<xop:Include xmlns:xop="http://www.w3.org/2004/08/xop/include" href="cid:%s"/>`, id)
+		}
+	}
+	return val
+}
+
+func renderBaseValue(base string, r xsdRestriction) string {
+	// enums win
+	if len(r.Enums) > 0 {
+		return r.Enums[0].Value
+	}
+
+	// numeric bounds
+	if len(r.MinInclusive) > 0 {
+		return r.MinInclusive[0].Value
+	}
+	if len(r.MinExclusive) > 0 {
+		// return minExclusive + 1
+		if v, err := strconv.Atoi(r.MinExclusive[0].Value); err == nil {
+			return strconv.Itoa(v + 1)
+		}
+	}
+
+	switch base {
+	case "base64Binary":
+		want := 1
+		if len(r.MinLengthVals) > 0 {
+			if v, err := strconv.Atoi(r.MinLengthVals[0].Value); err == nil {
+				want = v
+			}
+		}
+		buf := bytes.Repeat([]byte{0x41}, want)
+		return base64.StdEncoding.EncodeToString(buf)
+	case "hexBinary":
+		want := 1
+		if len(r.MinLengthVals) > 0 {
+			if v, err := strconv.Atoi(r.MinLengthVals[0].Value); err == nil {
+				want = v
+			}
+		}
+		return strings.Repeat("A", want*2)
+	case "boolean":
+		return "true"
+	case "int", "integer", "decimal", "float", "double", "long", "short", "byte", "unsignedInt", "unsignedShort", "unsignedLong", "unsignedByte", "positiveInteger", "nonNegativeInteger":
+		return "1"
+	case "date":
+		return "2020-01-02"
+	case "dateTime":
+		return "2020-01-02T03:04:05Z"
+	case "time":
+		return "03:04:05Z"
+	case "anyURI":
+		return "http://example.com/value"
+	}
+
+	// patterns (best-effort)
+	if r.Pattern.Value != "" {
+		p := r.Pattern.Value
+		switch {
+		case strings.Contains(p, `\d`), strings.Contains(p, "[0-9]"):
+			return "123"
+		case strings.Contains(p, "[A-Z]"):
+			return "ABC"
+		case strings.Contains(p, "[a-z]"):
+			return "abc"
+		}
+	}
+
+	// lengths
+	val := "value"
+	if len(r.MinLengthVals) > 0 {
+		if n, err := strconv.Atoi(r.MinLengthVals[0].Value); err == nil && n > len(val) {
+			val = strings.Repeat("x", n)
+		}
+	}
+
+	return val
+}
@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWSDLMockDispatchesOnActionAndBodyElement(t *testing.T) {
+	spec := filepath.Join("..", "..", "sampledata", "wsdl", "schema_sample.wsdl")
+	mock, err := NewWSDLMock(context.Background(), MockOptions{Source: spec})
+	if err != nil {
+		t.Fatalf("mock: %v", err)
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	envelope := `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ns="http://example.com/schema"><soapenv:Body><ns:GetStatus><id>1</id></ns:GetStatus></soapenv:Body></soapenv:Envelope>`
+	cases := []struct {
+		name   string
+		action string
+	}{
+		{"by action", mock.ops["GetStatus"].action},
+		{"by element", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(envelope))
+			req.Header.Set("Content-Type", "text/xml")
+			if tc.action != "" {
+				req.Header.Set("SOAPAction", `"`+tc.action+`"`)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status %d: %s", resp.StatusCode, body)
+			}
+			for _, want := range []string{"<tns:GetStatusResponse>", "<state>OPEN</state>", "<code>1</code>"} {
+				if !strings.Contains(string(body), want) {
+					t.Fatalf("response missing %q: %s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestWSDLMockConfiguredFault(t *testing.T) {
+	spec := filepath.Join("..", "..", "sampledata", "wsdl", "schema_sample.wsdl")
+	fault, err := ParseMockFault("soap:Client|invalid id|400")
+	if err != nil {
+		t.Fatalf("parse fault: %v", err)
+	}
+	mock, err := NewWSDLMock(context.Background(), MockOptions{Source: spec, Faults: map[string]MockFault{"GetStatus": fault}})
+	if err != nil {
+		t.Fatalf("mock: %v", err)
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "text/xml", strings.NewReader(`<Envelope xmlns="http://schemas.xmlsoap.org/soap/envelope/"><Body><GetStatus/></Body></Envelope>`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "<faultcode>soap:Client</faultcode>") || !strings.Contains(string(body), "<faultstring>invalid id</faultstring>") {
+		t.Fatalf("unexpected fault body: %s", body)
+	}
+
+	if _, err := NewWSDLMock(context.Background(), MockOptions{Source: spec, Faults: map[string]MockFault{"Nope": {}}}); err == nil {
+		t.Fatalf("expected error for fault on unknown operation")
+	}
+}
+
+func TestWSDLMockUnmatchedFaultCodePerVersion(t *testing.T) {
+	spec := filepath.Join("..", "..", "sampledata", "wsdl", "schema_sample.wsdl")
+	mock, err := NewWSDLMock(context.Background(), MockOptions{Source: spec})
+	if err != nil {
+		t.Fatalf("mock: %v", err)
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	cases := []struct {
+		name        string
+		contentType string
+		envelope    string
+		want        string
+	}{
+		{"soap11", "text/xml", `<Envelope xmlns="http://schemas.xmlsoap.org/soap/envelope/"><Body><Nope/></Body></Envelope>`, "<faultcode>soap:Client</faultcode>"},
+		{"soap12", "application/soap+xml", `<Envelope xmlns="http://www.w3.org/2003/05/soap-envelope"><Body><Nope/></Body></Envelope>`, "<soap:Value>soap:Sender</soap:Value>"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL, tc.contentType, strings.NewReader(tc.envelope))
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("expected 500, got %d", resp.StatusCode)
+			}
+			if !strings.Contains(string(body), tc.want) {
+				t.Fatalf("fault missing %q: %s", tc.want, body)
+			}
+		})
+	}
+}
+
+func TestWSDLMockServesMTOMAttachments(t *testing.T) {
+	spec := filepath.Join("..", "..", "sampledata", "wsdl", "attachment_sample.wsdl")
+	mock, err := NewWSDLMock(context.Background(), MockOptions{Source: spec, MTOM: true})
+	if err != nil {
+		t.Fatalf("mock: %v", err)
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><Upload/></s:Body></s:Envelope>`))
+	req.Header.Set("SOAPAction", "http://example.com/attachment/Upload")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+
+	mt, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mt != "multipart/related" {
+		t.Fatalf("expected multipart/related, got %q (%v)", resp.Header.Get("Content-Type"), err)
+	}
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	root, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("root part: %v", err)
+	}
+	rootBody, _ := io.ReadAll(root)
+	if !strings.Contains(string(rootBody), `<xop:Include`) {
+		t.Fatalf("root part missing xop include: %s", rootBody)
+	}
+	att, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("attachment part: %v", err)
+	}
+	data, _ := io.ReadAll(att)
+	if att.Header.Get("Content-ID") != "<attachment1@gru>" || string(data) != "A" {
+		t.Fatalf("unexpected attachment %q %q", att.Header.Get("Content-ID"), data)
+	}
+}
+
+func TestNewWSDLMockOptionsErrors(t *testing.T) {
+	if _, err := NewWSDLMock(context.Background(), MockOptions{}); err == nil || !strings.Contains(err.Error(), "MockOptions.Source") {
+		t.Fatalf("expected a MockOptions.Source error, got %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("cancelled context still fetched the WSDL")
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewWSDLMock(ctx, MockOptions{Source: srv.URL + "/service.wsdl"}); err == nil {
+		t.Fatal("expected an error loading a remote WSDL with a cancelled context")
+	}
+}
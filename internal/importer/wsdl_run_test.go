@@ -1,17 +1,13 @@
 package importer
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/xml"
 	"fmt"
-	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"testing"
 
@@ -33,18 +29,11 @@ func TestImportWSDLAndRunAgainstMock(t *testing.T) {
 
 	for _, spec := range fixtures {
 		t.Run(filepath.Base(spec.spec), func(t *testing.T) {
-			wsdlDef, idx := mustLoadWSDL(t, spec.spec)
-			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				bodyBytes, _ := io.ReadAll(r.Body)
-				op := extractSoapOperation(bodyBytes)
-				if op == "" {
-					op = "Operation"
-				}
-				w.Header().Set("Content-Type", "text/xml")
-				w.WriteHeader(http.StatusOK)
-				resp := buildSoapResponse(wsdlDef, idx, op)
-				_, _ = w.Write([]byte(resp))
-			}))
+			mock, err := NewWSDLMock(context.Background(), MockOptions{Source: spec.spec})
+			if err != nil {
+				t.Fatalf("mock: %v", err)
+			}
+			srv := httptest.NewServer(mock)
 			defer srv.Close()
 
 			tmp := t.TempDir()
@@ -194,148 +183,3 @@ func mustLoadWSDL(t *testing.T, path string) (wsdlDefinitions, map[string]xsdEle
 	}
 	return def, buildElementIndex(def)
 }
-
-func buildSoapResponse(def wsdlDefinitions, idx map[string]xsdElement, op string) string {
-	respEl, ok := idx[op+"Response"]
-	body := ""
-	if ok {
-		body = renderElement(respEl, idx, 0)
-	} else {
-		body = fmt.Sprintf("<%sResponse><result>ok</result></%sResponse>", op, op)
-	}
-	return fmt.Sprintf(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>%s</soap:Body></soap:Envelope>`, body)
-}
-
-func renderElement(el xsdElement, idx map[string]xsdElement, depth int) string {
-	if depth > 4 {
-		return ""
-	}
-	name := el.Name
-	if name == "" {
-		name = localName(el.Type)
-	}
-	if name == "" {
-		name = "Value"
-	}
-
-	min := occursToInt(el.MinOccurs, 1)
-	var b strings.Builder
-	for range min {
-		b.WriteString("<" + name + ">")
-		b.WriteString(renderElementValue(el, idx, depth+1))
-		b.WriteString("</" + name + ">")
-	}
-	return b.String()
-}
-
-func renderElementValue(el xsdElement, idx map[string]xsdElement, depth int) string {
-	// Prefer inline complex/simple definitions
-	if el.ComplexType != nil {
-		return renderComplex(el.ComplexType, idx, depth)
-	}
-	if el.SimpleType != nil {
-		return renderSimple(resolveRestriction(el.SimpleType.Restriction, idx))
-	}
-
-	// Named type reference
-	if el.Type != "" {
-		if ref, ok := idx[localName(el.Type)]; ok {
-			if ref.ComplexType != nil {
-				return renderComplex(ref.ComplexType, idx, depth)
-			}
-			if ref.SimpleType != nil {
-				return renderSimple(resolveRestriction(ref.SimpleType.Restriction, idx))
-			}
-		}
-		base := localName(el.Type)
-		return renderBaseValue(base, xsdRestriction{Base: base})
-	}
-
-	// Fallback
-	return "value"
-}
-
-func renderComplex(ct *xsdComplexType, idx map[string]xsdElement, depth int) string {
-	var b strings.Builder
-	for _, child := range ct.Sequence.Elements {
-		b.WriteString(renderElement(child, idx, depth+1))
-	}
-	return b.String()
-}
-
-func renderSimple(r xsdRestriction) string {
-	return renderBaseValue(localName(r.Base), r)
-}
-
-func renderBaseValue(base string, r xsdRestriction) string {
-	// enums win
-	if len(r.Enums) > 0 {
-		return r.Enums[0].Value
-	}
-
-	// numeric bounds
-	if len(r.MinInclusive) > 0 {
-		return r.MinInclusive[0].Value
-	}
-	if len(r.MinExclusive) > 0 {
-		// return minExclusive + 1
-		if v, err := strconv.Atoi(r.MinExclusive[0].Value); err == nil {
-			return strconv.Itoa(v + 1)
-		}
-	}
-
-	switch base {
-	case "base64Binary":
-		want := 1
-		if len(r.MinLengthVals) > 0 {
-			if v, err := strconv.Atoi(r.MinLengthVals[0].Value); err == nil {
-				want = v
-			}
-		}
-		buf := bytes.Repeat([]byte{0x41}, want)
-		return base64.StdEncoding.EncodeToString(buf)
-	case "hexBinary":
-		want := 1
-		if len(r.MinLengthVals) > 0 {
-			if v, err := strconv.Atoi(r.MinLengthVals[0].Value); err == nil {
-				want = v
-			}
-		}
-		return strings.Repeat("A", want*2)
-	case "boolean":
-		return "true"
-	case "int", "integer", "decimal", "float", "double", "long", "short", "byte", "unsignedInt", "unsignedShort", "unsignedLong", "unsignedByte", "positiveInteger", "nonNegativeInteger":
-		return "1"
-	case "date":
-		return "2020-01-02"
-	case "dateTime":
-		return "2020-01-02T03:04:05Z"
-	case "time":
-		return "03:04:05Z"
-	case "anyURI":
-		return "http://example.com/value"
-	}
-
-	// patterns (best-effort)
-	if r.Pattern.Value != "" {
-		p := r.Pattern.Value
-		switch {
-		case strings.Contains(p, `\d`), strings.Contains(p, "[0-9]"):
-			return "123"
-		case strings.Contains(p, "[A-Z]"):
-			return "ABC"
-		case strings.Contains(p, "[a-z]"):
-			return "abc"
-		}
-	}
-
-	// lengths
-	val := "value"
-	if len(r.MinLengthVals) > 0 {
-		if n, err := strconv.Atoi(r.MinLengthVals[0].Value); err == nil && n > len(val) {
-			val = strings.Repeat("x", n)
-		}
-	}
-
-	return val
-}
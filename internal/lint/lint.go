@@ -0,0 +1,305 @@
+// Package lint checks Bruno collections for mistakes that break or weaken a
+// run: parse errors, unresolved variables, duplicate seq numbers, requests
+// without tests, hard-coded secrets, invalid JavaScript, unknown blocks and
+// missing file bodies. Rules are plain values; callers can add their own.
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"pkt.systems/gruno/bru"
+	"pkt.systems/gruno/internal/parser"
+)
+
+// Severity is the level of a finding. The values match SARIF levels.
+type Severity string
+
+// Severities, from most to least severe.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Note    Severity = "note"
+	// Off disables a rule in Config.Severity.
+	Off Severity = "off"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case Error:
+		return 3
+	case Warning:
+		return 2
+	case Note:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as min.
+func (s Severity) AtLeast(min Severity) bool {
+	return min.rank() > 0 && s.rank() >= min.rank()
+}
+
+// Finding is one problem reported by a rule. Line and Col are 1-based and
+// zero when the position is unknown.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Col      int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	pos := f.File
+	if f.Line > 0 {
+		pos = fmt.Sprintf("%s:%d:%d", f.File, f.Line, max(f.Col, 1))
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", pos, f.Severity, f.Message, f.Rule)
+}
+
+// Rule is one check. Check returns the findings for the whole collection;
+// Run fills in their Rule and Severity.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Check       func(c *Collection) []Finding
+}
+
+// File is one .bru file of the collection.
+type File struct {
+	Path   string
+	Source []byte
+	// Tree is the syntax tree, nil when the file does not parse.
+	Tree *bru.File
+	// Case is the runner's view of request files.
+	Case parser.ParsedFile
+	// Err is the parse error, if any.
+	Err error
+	// Request reports whether the file holds a request block.
+	Request bool
+	// Env reports whether the file is an environment (under environments/).
+	Env bool
+}
+
+// Collection is the set of files a lint run checks.
+type Collection struct {
+	Root  string
+	Files []*File
+	// Vars holds the variables of the chosen environment (vars and the names
+	// in vars:secret) and the overrides. It is nil when neither was given.
+	Vars map[string]string
+}
+
+// Config selects and tunes rules; it is the content of .grulint.json.
+type Config struct {
+	// Enable limits the run to these rules when set.
+	Enable []string `json:"enable,omitempty"`
+	// Disable turns rules off.
+	Disable []string `json:"disable,omitempty"`
+	// Severity overrides rule severities; "off" disables a rule.
+	Severity map[string]Severity `json:"severity,omitempty"`
+}
+
+// LoadConfig reads a JSON Config from path.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Options configure Run.
+type Options struct {
+	Config
+	// EnvPath is the environment .bru file unresolved variables are checked
+	// against.
+	EnvPath string
+	// Vars override or add variables (like --var).
+	Vars map[string]string
+	// Rules replaces DefaultRules when set.
+	Rules []Rule
+}
+
+// ActiveRules returns the rules opts selects, with severities applied.
+func ActiveRules(opts Options) ([]Rule, error) {
+	rules := opts.Rules
+	if rules == nil {
+		rules = DefaultRules()
+	}
+	known := map[string]bool{}
+	for _, r := range rules {
+		known[r.ID] = true
+	}
+	for _, id := range append(append(slices.Clone(opts.Enable), opts.Disable...), slices.Collect(maps.Keys(opts.Severity))...) {
+		if !known[id] {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+	}
+	for id, sev := range opts.Severity {
+		if sev != Off && sev.rank() == 0 {
+			return nil, fmt.Errorf("rule %s: unknown severity %q (error, warning, note, off)", id, sev)
+		}
+	}
+	var out []Rule
+	for _, r := range rules {
+		if len(opts.Enable) > 0 && !slices.Contains(opts.Enable, r.ID) || slices.Contains(opts.Disable, r.ID) {
+			continue
+		}
+		if sev, ok := opts.Severity[r.ID]; ok {
+			r.Severity = sev
+		}
+		if r.Severity == Off {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Run lints the .bru file or folder at path and returns the findings ordered
+// by file and position.
+func Run(ctx context.Context, path string, opts Options) ([]Finding, error) {
+	rules, err := ActiveRules(opts)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Load(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, r := range rules {
+		for _, f := range r.Check(c) {
+			f.Rule, f.Severity = r.ID, r.Severity
+			out = append(out, f)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Col != b.Col {
+			return a.Col < b.Col
+		}
+		return a.Rule < b.Rule
+	})
+	return out, nil
+}
+
+// Load reads the .bru files under path (or the single file at path) and the
+// environment. Files that do not parse are kept with Err set.
+func Load(ctx context.Context, path string, opts Options) (*Collection, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Collection{Root: path}
+	var paths []string
+	if info.IsDir() {
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != path && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(strings.ToLower(d.Name()), ".bru") {
+				paths = append(paths, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		c.Root = filepath.Dir(path)
+		paths = []string{path}
+	}
+
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := loadFile(ctx, c.Root, p)
+		if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, f)
+	}
+
+	if opts.EnvPath != "" || len(opts.Vars) > 0 {
+		c.Vars = map[string]string{}
+		if opts.EnvPath != "" {
+			env, err := bru.ParseFile(opts.EnvPath)
+			if err != nil {
+				return nil, fmt.Errorf("load env: %w", err)
+			}
+			for _, b := range env.Blocks {
+				if b.Name != "vars" && b.Name != "vars:secret" {
+					continue
+				}
+				for _, e := range b.Entries {
+					if e.Key != "" && !e.Disabled {
+						c.Vars[e.Key] = e.Value
+					}
+				}
+			}
+		}
+		maps.Copy(c.Vars, opts.Vars)
+	}
+	return c, nil
+}
+
+func loadFile(ctx context.Context, root, path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Path: path, Source: src}
+	if rel, err := filepath.Rel(root, path); err == nil {
+		for _, dir := range strings.Split(filepath.Dir(rel), string(os.PathSeparator)) {
+			if strings.EqualFold(dir, "environments") {
+				f.Env = true
+			}
+		}
+	}
+	if f.Tree, f.Err = bru.Parse(src); f.Err != nil {
+		f.Tree = nil
+		return f, nil
+	}
+	for _, b := range f.Tree.Blocks {
+		if slices.Contains(verbs, b.Name) {
+			f.Request = !f.Env
+		}
+	}
+	if f.Request {
+		f.Case, f.Err = parser.Parse(ctx, path, bytes.NewReader(src))
+	}
+	return f, nil
+}
+
+var verbs = []string{"get", "post", "put", "delete", "patch", "options", "head", "connect", "trace"}
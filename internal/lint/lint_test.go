@@ -0,0 +1,243 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCollection(t *testing.T) string {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version":"1","name":"c","type":"collection"}`)
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), `vars {
+  baseUrl: https://api.test
+  password: hunter22
+}
+
+vars:secret [
+  token
+]
+`)
+	writeFile(t, filepath.Join(dir, "users", "login.bru"), `meta {
+  name: Login
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/login
+  body: json
+  auth: none
+}
+
+body:json {
+  {"user": "{{user}}"}
+}
+
+vars:post-response {
+  session: res.body.session
+}
+
+tests {
+  test("ok", function() {
+    expect(res.status).to.equal(200);
+  });
+}
+`)
+	writeFile(t, filepath.Join(dir, "users", "me.bru"), `meta {
+  name: Me
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/me?s={{session}}&r={{$randomInt}}
+  auth: none
+}
+
+headers {
+  Authorization: Bearer abc123
+  X-Token: {{token}}
+}
+
+script:pre-request {
+  bru.setVar("trace", 1);
+}
+
+tests {
+  test("broken", function() {
+    expect(res.status).to.equal(200;
+  });
+}
+
+sidecar {
+  x: 1
+}
+`)
+	writeFile(t, filepath.Join(dir, "users", "upload.bru"), `meta {
+  name: Upload
+  type: http
+  seq: 2
+}
+
+put {
+  url: {{baseUrl}}/files/{{trace}}
+  body: multipart-form
+  auth: none
+}
+
+body:multipart-form {
+  ok: @file(data.txt)
+  missing: @file(nope.bin)
+  legacy: @./gone.bin;type=application/octet-stream
+}
+
+assert {
+  res.status: eq 200
+}
+`)
+	writeFile(t, filepath.Join(dir, "users", "data.txt"), "x")
+	writeFile(t, filepath.Join(dir, "orders", "list.bru"), `meta {
+  name: List
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/orders
+  auth: none
+}
+`)
+	writeFile(t, filepath.Join(dir, "broken.bru"), "meta {\n  name: x\n")
+	return dir
+}
+
+func TestRunDefaultRules(t *testing.T) {
+	dir := testCollection(t)
+	findings, err := Run(context.Background(), dir, Options{EnvPath: filepath.Join(dir, "environments", "local.bru")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		rel, _ := filepath.Rel(dir, f.File)
+		f.File = filepath.ToSlash(rel)
+		got = append(got, f.String())
+	}
+	want := []string{
+		"broken.bru:1:1: error: block meta is not closed (parse-error)",
+		"environments/local.bru:3:3: error: password is stored in plain text; list it in vars:secret (hardcoded-secret)",
+		"orders/list.bru:7:1: warning: request has no tests or assertions (missing-tests)",
+		"users/login.bru:14:13: error: variable {{user}} is not defined (unresolved-var)",
+		"users/me.bru:4:3: warning: seq 1 is also used by login.bru (duplicate-seq)",
+		"users/me.bru:13:3: error: hard-coded Authorization header; take it from a {{variable}} (hardcoded-secret)",
+		"users/me.bru:23:36: error: tests: Unexpected token ; (invalid-js)",
+		"users/me.bru:27:1: warning: unknown block \"sidecar\" (unknown-block)",
+		"users/upload.bru:15:3: error: missing: file nope.bin does not exist (missing-file)",
+		"users/upload.bru:16:3: error: legacy: file ./gone.bin does not exist (missing-file)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Without an environment the unresolved-var rule does not run.
+	findings, err = Run(context.Background(), dir, Options{Config: Config{Enable: []string{"unresolved-var"}}})
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("unresolved-var without env: %v %v", findings, err)
+	}
+}
+
+func TestConfigAndReports(t *testing.T) {
+	dir := testCollection(t)
+	opts := Options{Config: Config{
+		Disable:  []string{"hardcoded-secret", "parse-error"},
+		Severity: map[string]Severity{"missing-tests": Note, "invalid-js": Off},
+	}}
+	findings, err := Run(context.Background(), dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		switch f.Rule {
+		case "hardcoded-secret", "parse-error", "invalid-js":
+			t.Fatalf("disabled rule reported: %v", f)
+		case "missing-tests":
+			if f.Severity != Note || f.Severity.AtLeast(Warning) || !f.Severity.AtLeast(Note) {
+				t.Fatalf("severity override: %v", f)
+			}
+		}
+	}
+
+	custom := Rule{ID: "no-put", Description: "PUT is not allowed", Severity: Warning, Check: func(c *Collection) []Finding {
+		var out []Finding
+		for _, f := range c.Files {
+			if f.Request && f.Case.Request.Verb == "PUT" {
+				out = append(out, Finding{File: f.Path, Message: "PUT request"})
+			}
+		}
+		return out
+	}}
+	findings, err = Run(context.Background(), dir, Options{Rules: append(DefaultRules(), custom), Config: Config{Enable: []string{"no-put"}}})
+	if err != nil || len(findings) != 1 || findings[0].Rule != "no-put" || findings[0].Severity != Warning {
+		t.Fatalf("custom rule: %v %v", findings, err)
+	}
+	if _, err := Run(context.Background(), dir, Options{Config: Config{Disable: []string{"nope"}}}); err == nil {
+		t.Fatal("expected unknown rule error")
+	}
+
+	rules, _ := ActiveRules(Options{})
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, findings, rules); err != nil {
+		t.Fatal(err)
+	}
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &sarif); err != nil {
+		t.Fatal(err)
+	}
+	if sarif.Version != "2.1.0" || len(sarif.Runs) != 1 || len(sarif.Runs[0].Tool.Driver.Rules) != len(DefaultRules()) ||
+		len(sarif.Runs[0].Results) != 1 || sarif.Runs[0].Results[0].Level != "warning" ||
+		!strings.HasSuffix(sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI, "users/upload.bru") {
+		t.Fatalf("sarif:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, nil); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Fatalf("empty json: %q %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// WriteText writes one "file:line:col: severity: message (rule)" line per
+// finding.
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the findings as an indented JSON array.
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log (GitHub code scanning,
+// GitLab, Azure DevOps). rules describe the rules that ran.
+func WriteSARIF(w io.Writer, findings []Finding, rules []Rule) error {
+	type (
+		message struct {
+			Text string `json:"text"`
+		}
+		config struct {
+			Level Severity `json:"level"`
+		}
+		artifact struct {
+			URI string `json:"uri"`
+		}
+		region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn,omitempty"`
+		}
+		physical struct {
+			ArtifactLocation artifact `json:"artifactLocation"`
+			Region           *region  `json:"region,omitempty"`
+		}
+		location struct {
+			PhysicalLocation physical `json:"physicalLocation"`
+		}
+		result struct {
+			RuleID    string     `json:"ruleId"`
+			RuleIndex int        `json:"ruleIndex"`
+			Level     Severity   `json:"level"`
+			Message   message    `json:"message"`
+			Locations []location `json:"locations"`
+		}
+		rule struct {
+			ID                   string  `json:"id"`
+			ShortDescription     message `json:"shortDescription"`
+			DefaultConfiguration config  `json:"defaultConfiguration"`
+		}
+		driver struct {
+			Name           string `json:"name"`
+			InformationURI string `json:"informationUri"`
+			Rules          []rule `json:"rules"`
+		}
+		run struct {
+			Tool struct {
+				Driver driver `json:"driver"`
+			} `json:"tool"`
+			Results []result `json:"results"`
+		}
+		log struct {
+			Schema  string `json:"$schema"`
+			Version string `json:"version"`
+			Runs    []run  `json:"runs"`
+		}
+	)
+
+	var r run
+	r.Tool.Driver = driver{Name: "gru lint", InformationURI: "https://github.com/sa6mwa/gruno", Rules: []rule{}}
+	index := map[string]int{}
+	for _, rl := range rules {
+		index[rl.ID] = len(r.Tool.Driver.Rules)
+		r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, rule{ID: rl.ID, ShortDescription: message{rl.Description}, DefaultConfiguration: config{rl.Severity}})
+	}
+	r.Results = []result{}
+	for _, f := range findings {
+		res := result{RuleID: f.Rule, RuleIndex: index[f.Rule], Level: f.Severity, Message: message{f.Message}}
+		loc := location{PhysicalLocation: physical{ArtifactLocation: artifact{URI: filepath.ToSlash(f.File)}}}
+		if f.Line > 0 {
+			loc.PhysicalLocation.Region = &region{StartLine: f.Line, StartColumn: f.Col}
+		}
+		res.Locations = []location{loc}
+		r.Results = append(r.Results, res)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []run{r},
+	})
+}
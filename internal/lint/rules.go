@@ -0,0 +1,469 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+	"pkt.systems/gruno/bru"
+	"pkt.systems/gruno/internal/parser"
+)
+
+// DefaultRules returns the built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		{ID: "parse-error", Description: "File does not parse", Severity: Error, Check: checkParse},
+		{ID: "unresolved-var", Description: "{{variable}} is not defined by the environment, --var or the collection", Severity: Error, Check: checkUnresolvedVars},
+		{ID: "duplicate-seq", Description: "Two requests in one folder share a seq number", Severity: Warning, Check: checkDuplicateSeq},
+		{ID: "missing-tests", Description: "Request has no tests or assertions", Severity: Warning, Check: checkMissingTests},
+		{ID: "hardcoded-secret", Description: "Secret or Authorization value is written in the file", Severity: Error, Check: checkSecrets},
+		{ID: "invalid-js", Description: "Script or tests block is not valid JavaScript", Severity: Error, Check: checkJS},
+		{ID: "unknown-block", Description: "Block name is not one Bruno reads", Severity: Warning, Check: checkUnknownBlocks},
+		{ID: "missing-file", Description: "File body or multipart file does not exist", Severity: Error, Check: checkFiles},
+	}
+}
+
+var posErrRe = regexp.MustCompile(`^(\d+):(\d+): (.*)$`)
+
+func checkParse(c *Collection) []Finding {
+	var out []Finding
+	for _, f := range c.Files {
+		if f.Err == nil {
+			continue
+		}
+		var list parser.ErrorList
+		if errors.As(f.Err, &list) {
+			for _, e := range list {
+				msg := e.Msg
+				if e.Block != "" {
+					msg = e.Block + ": " + msg
+				}
+				if e.Hint != "" {
+					msg += "; " + e.Hint
+				}
+				out = append(out, Finding{File: f.Path, Line: e.Line, Col: e.Col, Message: msg})
+			}
+			continue
+		}
+		fd := Finding{File: f.Path, Message: f.Err.Error()}
+		if m := posErrRe.FindStringSubmatch(f.Err.Error()); m != nil {
+			fd.Line, _ = strconv.Atoi(m[1])
+			fd.Col, _ = strconv.Atoi(m[2])
+			fd.Message = m[3]
+		}
+		out = append(out, fd)
+	}
+	return out
+}
+
+// span is the line range (1-based, inclusive) of a top-level block.
+type span struct {
+	block      *bru.Block
+	start, end int
+}
+
+// spans returns the line ranges of the blocks of f.
+func spans(f *File) []span {
+	total := lineCount(f.Source)
+	var out []span
+	for i, b := range f.Tree.Blocks {
+		end := total - len(f.Tree.Trailing)
+		if i+1 < len(f.Tree.Blocks) {
+			next := f.Tree.Blocks[i+1]
+			end = next.Pos.Line - len(next.Leading) - 1
+		}
+		out = append(out, span{block: b, start: b.Pos.Line, end: end})
+	}
+	return out
+}
+
+// lineCount counts the lines of src.
+func lineCount(src []byte) int {
+	n := strings.Count(string(src), "\n")
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// scriptBlock reports whether a block holds JavaScript.
+func scriptBlock(name string) bool {
+	return name == "tests" || name == "script" || strings.HasPrefix(name, "script:")
+}
+
+var setVarRe = regexp.MustCompile(`(?:bru\.set(?:Env|GlobalEnv)?Var|(?:pm|insomnia)\.(?:environment|collectionVariables|variables|globals)\.set)\(\s*["'` + "`" + `]([^"'` + "`" + `]+)`)
+
+// defined collects the variables a run can define: c.Vars, vars:pre-request
+// and vars:post-response keys and names set from scripts.
+func defined(c *Collection) map[string]bool {
+	names := map[string]bool{}
+	for k := range c.Vars {
+		names[k] = true
+	}
+	for _, f := range c.Files {
+		if f.Tree == nil {
+			continue
+		}
+		for _, b := range f.Tree.Blocks {
+			switch {
+			case b.Name == "vars:pre-request", b.Name == "vars:post-response", b.Name == "vars" && !f.Env:
+				for _, e := range b.Entries {
+					if e.Key != "" {
+						names[e.Key] = true
+					}
+				}
+			case scriptBlock(b.Name):
+				for _, m := range setVarRe.FindAllStringSubmatch(b.Text, -1) {
+					names[m[1]] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+func checkUnresolvedVars(c *Collection) []Finding {
+	if c.Vars == nil {
+		return nil
+	}
+	names := defined(c)
+	var out []Finding
+	for _, f := range c.Files {
+		if f.Tree == nil || f.Env {
+			continue
+		}
+		lines := strings.Split(string(f.Source), "\n")
+		for _, s := range spans(f) {
+			switch name := s.block.Name; {
+			case name == "docs", name == "vars:post-response", name == "assert", scriptBlock(name):
+				continue
+			}
+			for ln := s.start; ln <= s.end && ln <= len(lines); ln++ {
+				line := lines[ln-1]
+				if s.block.Kind != bru.Text && strings.HasPrefix(strings.TrimSpace(line), "//") {
+					continue
+				}
+				for _, m := range parser.VarPattern.FindAllStringSubmatchIndex(line, -1) {
+					name := strings.TrimSpace(line[m[2]:m[3]])
+					if names[name] || strings.HasPrefix(name, "$") || strings.HasPrefix(name, "process.env.") {
+						continue
+					}
+					out = append(out, Finding{File: f.Path, Line: ln, Col: m[0] + 1, Message: fmt.Sprintf("variable {{%s}} is not defined", name)})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func checkDuplicateSeq(c *Collection) []Finding {
+	type key struct {
+		dir string
+		seq float64
+	}
+	groups := map[key][]*File{}
+	for _, f := range c.Files {
+		if f.Request && f.Err == nil && f.Case.Meta.Seq != 0 {
+			k := key{filepath.Dir(f.Path), f.Case.Meta.Seq}
+			groups[k] = append(groups[k], f)
+		}
+	}
+	var out []Finding
+	for k, files := range groups {
+		if len(files) < 2 {
+			continue
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		for _, f := range files[1:] {
+			fd := Finding{File: f.Path, Message: fmt.Sprintf("seq %s is also used by %s", strconv.FormatFloat(k.seq, 'f', -1, 64), filepath.Base(files[0].Path))}
+			if meta := f.Tree.Block("meta"); meta != nil {
+				if e := meta.Entry("seq"); e != nil {
+					fd.Line, fd.Col = e.Pos.Line, e.Pos.Col
+				}
+			}
+			out = append(out, fd)
+		}
+	}
+	return out
+}
+
+func checkMissingTests(c *Collection) []Finding {
+	// collection.bru and folder.bru tests run for every request below them.
+	tested := map[string]bool{}
+	for _, f := range c.Files {
+		if f.Tree == nil || f.Request || f.Env {
+			continue
+		}
+		if b := f.Tree.Block("tests"); b != nil && strings.TrimSpace(b.Text) != "" {
+			tested[filepath.Dir(f.Path)] = true
+		}
+	}
+	var out []Finding
+	for _, f := range c.Files {
+		if !f.Request || f.Err != nil {
+			continue
+		}
+		if strings.TrimSpace(f.Case.TestsRaw) != "" || len(f.Case.Assert) > 0 || strings.Contains(f.Case.Scripts.PostResponse, "test(") {
+			continue
+		}
+		inherited := false
+		for dir := filepath.Dir(f.Path); ; dir = filepath.Dir(dir) {
+			if tested[dir] {
+				inherited = true
+				break
+			}
+			if rel, err := filepath.Rel(c.Root, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+		if inherited {
+			continue
+		}
+		fd := Finding{File: f.Path, Message: "request has no tests or assertions"}
+		for _, b := range f.Tree.Blocks {
+			if f.Case.Request.Verb != "" && strings.EqualFold(b.Name, f.Case.Request.Verb) {
+				fd.Line, fd.Col = b.Pos.Line, b.Pos.Col
+			}
+		}
+		out = append(out, fd)
+	}
+	return out
+}
+
+var (
+	sensitiveHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key", "api-key", "x-auth-token"}
+	authSecrets      = map[string][]string{
+		"auth:bearer": {"token"},
+		"auth:basic":  {"password"},
+		"auth:digest": {"password"},
+		"auth:ntlm":   {"password"},
+		"auth:wsse":   {"password"},
+		"auth:apikey": {"value"},
+		"auth:oauth2": {"client_secret", "password"},
+		"auth:awsv4":  {"secretAccessKey", "sessionToken"},
+	}
+	secretKeyRe   = regexp.MustCompile(`(?i)(pass(word|wd)?|secret|token|api[-_]?key|access[-_]?key|private[-_]?key|credential)`)
+	secretBlocks  = []string{"headers", "params:query", "vars", "vars:pre-request", "body:form-urlencoded", "body:multipart-form"}
+	tokenPatterns = []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{"AWS access key", regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)},
+		{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+		{"Stripe secret key", regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{16,}`)},
+		{"JSON Web Token", regexp.MustCompile(`\beyJ[\w-]{8,}\.eyJ[\w-]{8,}\.[\w-]{8,}`)},
+		{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |OPENSSH |DSA )?PRIVATE KEY-----`)},
+	}
+)
+
+// literal reports whether v is a value written in the file rather than taken
+// from a {{variable}}.
+func literal(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "true", "false", "null", "none":
+		return false
+	}
+	return !strings.Contains(v, "{{")
+}
+
+func checkSecrets(c *Collection) []Finding {
+	var out []Finding
+	for _, f := range c.Files {
+		if f.Tree == nil {
+			continue
+		}
+		for _, b := range f.Tree.Blocks {
+			out = append(out, secretsIn(f, b)...)
+		}
+	}
+	return out
+}
+
+func secretsIn(f *File, b *bru.Block) []Finding {
+	var out []Finding
+	add := func(pos bru.Pos, msg string) {
+		out = append(out, Finding{File: f.Path, Line: pos.Line, Col: pos.Col, Message: msg})
+	}
+	switch b.Kind {
+	case bru.Text:
+		if b.Name == "docs" {
+			return nil
+		}
+		for i, line := range strings.Split(b.Text, "\n") {
+			for _, p := range tokenPatterns {
+				if loc := p.re.FindStringIndex(line); loc != nil {
+					add(bru.Pos{Line: b.Pos.Line + 1 + i, Col: loc[0] + 1}, fmt.Sprintf("%s in %s", p.name, b.Name))
+				}
+			}
+		}
+		return out
+	case bru.List:
+		return nil
+	}
+	for _, e := range b.Entries {
+		if e.Block != nil {
+			out = append(out, secretsIn(f, e.Block)...)
+			continue
+		}
+		if e.Key == "" || !literal(e.Value) {
+			continue
+		}
+		switch {
+		case b.Name == "headers" && containsFold(sensitiveHeaders, e.Key):
+			add(e.Pos, fmt.Sprintf("hard-coded %s header; take it from a {{variable}}", e.Key))
+		case containsFold(authSecrets[b.Name], e.Key):
+			add(e.Pos, fmt.Sprintf("hard-coded %s in %s; take it from a {{variable}}", e.Key, b.Name))
+		case f.Env && b.Name == "vars" && secretKeyRe.MatchString(e.Key):
+			add(e.Pos, fmt.Sprintf("%s is stored in plain text; list it in vars:secret", e.Key))
+		case !f.Env && containsFold(secretBlocks, b.Name) && secretKeyRe.MatchString(e.Key):
+			add(e.Pos, fmt.Sprintf("hard-coded %s in %s; take it from a {{variable}}", e.Key, b.Name))
+		default:
+			for _, p := range tokenPatterns {
+				if p.re.MatchString(e.Value) {
+					add(e.Pos, fmt.Sprintf("%s in %s %s", p.name, b.Name, e.Key))
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+var jsErrRe = regexp.MustCompile(`Line (\d+):(\d+) (.*)`)
+
+func checkJS(c *Collection) []Finding {
+	var out []Finding
+	for _, f := range c.Files {
+		if f.Tree == nil {
+			continue
+		}
+		lines := strings.Split(string(f.Source), "\n")
+		for _, b := range f.Tree.Blocks {
+			if !scriptBlock(b.Name) || b.Kind != bru.Text || strings.TrimSpace(b.Text) == "" {
+				continue
+			}
+			if _, err := goja.Compile(b.Name, b.Text, false); err != nil {
+				fd := Finding{File: f.Path, Line: b.Pos.Line, Col: b.Pos.Col, Message: fmt.Sprintf("%s: %v", b.Name, err)}
+				if m := jsErrRe.FindStringSubmatch(err.Error()); m != nil {
+					line, _ := strconv.Atoi(m[1])
+					fd.Col, _ = strconv.Atoi(m[2])
+					fd.Message = fmt.Sprintf("%s: %s", b.Name, strings.TrimSpace(m[3]))
+					header := lines[b.Pos.Line-1]
+					if i := strings.Index(header, b.Text); i >= 0 && strings.HasSuffix(strings.TrimSpace(header), "}") {
+						// Inline block: the script shares the header line.
+						fd.Col += i
+					} else {
+						fd.Line += line
+					}
+				}
+				out = append(out, fd)
+			}
+		}
+	}
+	return out
+}
+
+func checkUnknownBlocks(c *Collection) []Finding {
+	var out []Finding
+	for _, f := range c.Files {
+		if f.Tree == nil {
+			continue
+		}
+		var walk func(blocks []*bru.Block)
+		walk = func(blocks []*bru.Block) {
+			for _, b := range blocks {
+				if !bru.KnownBlock(b.Name) {
+					out = append(out, Finding{File: f.Path, Line: b.Pos.Line, Col: b.Pos.Col, Message: fmt.Sprintf("unknown block %q", b.Name)})
+				}
+				var nested []*bru.Block
+				for _, e := range b.Entries {
+					if e.Block != nil {
+						nested = append(nested, e.Block)
+					}
+				}
+				walk(nested)
+			}
+		}
+		walk(f.Tree.Blocks)
+	}
+	return out
+}
+
+var fileRefRe = regexp.MustCompile(`@file\(([^)]*)\)`)
+
+func checkFiles(c *Collection) []Finding {
+	var out []Finding
+	for _, f := range c.Files {
+		if !f.Request || f.Tree == nil {
+			continue
+		}
+		for _, b := range f.Tree.Blocks {
+			if b.Name != "body:multipart-form" && b.Name != "body:file" {
+				continue
+			}
+			for _, e := range b.Entries {
+				if e.Key == "" || e.Disabled {
+					continue
+				}
+				var refs []string
+				for _, m := range fileRefRe.FindAllStringSubmatch(e.Value, -1) {
+					refs = append(refs, m[1])
+				}
+				if refs == nil && b.Name == "body:multipart-form" && strings.HasPrefix(e.Value, "@") {
+					// gru's own syntax: @path;type=...;cid=...
+					path, _, _ := strings.Cut(strings.TrimPrefix(e.Value, "@"), ";")
+					refs = append(refs, path)
+				}
+				for _, ref := range refs {
+					if path, ok := resolveRef(c, f, ref); !ok {
+						out = append(out, Finding{File: f.Path, Line: e.Pos.Line, Col: e.Pos.Col, Message: fmt.Sprintf("%s: file %s does not exist", e.Key, path)})
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// resolveRef expands the variables in a file reference and looks for it
+// relative to the working directory (as gru run opens it), the .bru file
+// and the collection root. References that still hold variables are not
+// checked.
+func resolveRef(c *Collection, f *File, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	ref = parser.VarPattern.ReplaceAllStringFunc(ref, func(m string) string {
+		if v, ok := c.Vars[strings.TrimSpace(m[2:len(m)-2])]; ok {
+			return v
+		}
+		return m
+	})
+	if ref == "" || strings.Contains(ref, "{{") {
+		return ref, true
+	}
+	candidates := []string{ref}
+	if !filepath.IsAbs(ref) {
+		candidates = append(candidates, filepath.Join(filepath.Dir(f.Path), ref), filepath.Join(c.Root, ref))
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return ref, true
+		}
+	}
+	return ref, false
+}
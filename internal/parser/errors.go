@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a parse diagnostic with its position in the file.
+type Error struct {
+	File string
+	// Line and Col are 1-based; Line is 0 when the position is unknown.
+	Line int
+	Col  int
+	// Block is the block the problem is in, e.g. "meta" or "tests".
+	Block string
+	Msg   string
+	// Hint suggests a fix; it may be empty.
+	Hint string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, ":%d:%d", e.Line, max(e.Col, 1))
+	}
+	if sb.Len() > 0 {
+		sb.WriteString(": ")
+	}
+	if e.Block != "" {
+		sb.WriteString(e.Block + ": ")
+	}
+	sb.WriteString(e.Msg)
+	if e.Hint != "" {
+		sb.WriteString(" (" + e.Hint + ")")
+	}
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// ErrorList holds every diagnostic of a parse, in file order. Parse and
+// ParseFile return one when a file has problems; errors.As finds the
+// individual *Error values.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
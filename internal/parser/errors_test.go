@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseReportsAllErrorsWithPositions(t *testing.T) {
+	bru := `meta {
+  name: Broken
+  seq: x
+}
+
+get {
+  url: https://example.com
+
+script:pre-request {
+  if (x) {
+}
+
+tests {
+  test("ok", function() {});
+}
+`
+	_, err := Parse(context.Background(), "broken.bru", strings.NewReader(bru))
+	var list ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected ErrorList, got %T: %v", err, err)
+	}
+	want := []string{
+		`broken.bru:3:8: meta: seq "x" is not a number (use a number such as seq: 1)`,
+		`broken.bru:6:1: get: block is not closed before line 9 (add the missing })`,
+	}
+	if got := strings.Split(err.Error(), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("errors:\n%s\nwant:\n%s", err, strings.Join(want, "\n"))
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatal("expected the strconv error to be wrapped")
+	}
+}
+
+func TestParseRecoversFromUnbalancedScript(t *testing.T) {
+	bru := `get {
+  url: https://example.com
+}
+
+script:pre-request {
+  const s = "{";
+}
+
+tests {
+  test("ok", function() {});
+}
+`
+	pf, err := Parse(context.Background(), "a.bru", strings.NewReader(bru))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if strings.TrimSpace(pf.Scripts.PreRequest) != `const s = "{";` {
+		t.Fatalf("script swallowed the file: %q", pf.Scripts.PreRequest)
+	}
+	if !strings.Contains(pf.TestsRaw, `test("ok"`) {
+		t.Fatalf("tests block lost: %q", pf.TestsRaw)
+	}
+
+	_, err = Parse(context.Background(), "b.bru", strings.NewReader("get {\n  url: https://example.com\n}\n\ndocs {\n  text\n"))
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Line != 5 || perr.Block != "docs" || !strings.Contains(perr.Msg, "end of file") {
+		t.Fatalf("unclosed docs: %v", err)
+	}
+}
+
+func TestDiscoverCasesCollectErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok.bru":      "get {\n  url: https://example.com\n}\n",
+		"bad.bru":     "meta {\n  seq: one\n}\n\nget {\n  url: https://example.com\n}\n",
+		"sub/bad.bru": "post {\n  url: https://example.com\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := DiscoverCases(dir, true); err == nil {
+		t.Fatal("expected DiscoverCases to fail on the first bad file")
+	}
+	cases, diags, err := DiscoverCasesWith(dir, DiscoverOptions{Recursive: true, CollectErrors: true})
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(cases) != 1 || filepath.Base(cases[0].FilePath) != "ok.bru" {
+		t.Fatalf("cases: %+v", cases)
+	}
+	if len(diags) != 2 || filepath.Base(diags[0].File) != "bad.bru" || diags[0].Line != 2 || diags[1].Line != 1 {
+		t.Fatalf("diagnostics: %v", diags)
+	}
+}
@@ -0,0 +1,511 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// HTTPFile is a parsed VS Code REST Client / JetBrains HTTP Client file.
+// Response references between requests ({{login.response.body.$.token}}) are
+// rewritten to plain variables that the producing request sets through
+// PostVars, so the file runs through the same case model as .bru files.
+type HTTPFile struct {
+	Path     string
+	Vars     []HTTPPair // @name = value definitions, in file order
+	Requests []HTTPRequest
+	// Notes lists unsupported file-level constructs.
+	Notes []string
+}
+
+// HTTPPair is an ordered name/value line (header or variable).
+type HTTPPair struct {
+	Key   string
+	Value string
+}
+
+// HTTPRequest is one ###-separated request of an HTTPFile.
+type HTTPRequest struct {
+	// Name is the "# @name" used for response references; Title is the text
+	// after the ### separator.
+	Name    string
+	Title   string
+	Method  string
+	URL     string
+	Headers []HTTPPair
+	Body    string
+	// BodyFile is set for "< ./file" bodies, relative to the .http file.
+	BodyFile string
+	// PostVars are variables later requests reference, mapped to res.*
+	// expressions evaluated after this request (vars:post-response).
+	PostVars []HTTPPair
+	// Notes lists unsupported constructs that were dropped or kept verbatim.
+	Notes []string
+	Line  int
+}
+
+// DisplayName is the case name: @name, then the ### title, then method and path.
+func (r HTTPRequest) DisplayName() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Title != "":
+		return r.Title
+	}
+	u := r.URL
+	if rest, ok := strings.CutPrefix(u, "{{"); ok {
+		// {{baseUrl}}/path
+		_, u, _ = strings.Cut(rest, "}}")
+	} else if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = ""
+		if i := strings.Index(rest, "/"); i >= 0 {
+			u = rest[i:]
+		}
+	}
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	if u == "" {
+		u = "/"
+	}
+	return r.Method + " " + u
+}
+
+var (
+	httpMethods = map[string]struct{}{
+		"GET": {}, "POST": {}, "PUT": {}, "PATCH": {}, "DELETE": {}, "HEAD": {}, "OPTIONS": {}, "TRACE": {}, "CONNECT": {},
+	}
+	httpVarDef     = regexp.MustCompile(`^@([\w.-]+)\s*=\s*(.*)$`)
+	httpNameTag    = regexp.MustCompile(`^(?:#|//)\s*@name(?:\s*=\s*|\s+)(\S+)\s*$`)
+	httpVersion    = regexp.MustCompile(`\s+HTTP/[\d.]+$`)
+	httpHeaderLine = regexp.MustCompile(`^([!#$%&'*+.^_` + "`" + `|~0-9A-Za-z-]+)\s*:\s*(.*)$`)
+	httpRef        = regexp.MustCompile(`\{\{\s*([\w-]+)\.(response|request)\.(body|headers)\.([^}]*?)\s*\}\}`)
+	httpPathIdent  = regexp.MustCompile(`[A-Za-z_$][\w$]*|\d+`)
+)
+
+// IsHTTPFile reports whether name has a .http or .rest extension.
+func IsHTTPFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".http" || ext == ".rest"
+}
+
+// ParseHTTPFile reads and parses a .http/.rest file.
+func ParseHTTPFile(ctx context.Context, path string) (HTTPFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return HTTPFile{}, err
+	}
+	defer f.Close()
+	return ParseHTTP(ctx, path, f)
+}
+
+// ParseHTTP parses .http content read from r; path names the file in errors
+// and anchors "< ./file" bodies.
+func ParseHTTP(ctx context.Context, path string, r io.Reader) (HTTPFile, error) {
+	hf := HTTPFile{Path: path}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	var (
+		cur      *HTTPRequest
+		title    string
+		name     string
+		state    int
+		body     []string
+		handler  []string
+		pre      []string
+		lineNo   int
+		finished []HTTPRequest
+	)
+	const (
+		statePreamble = iota
+		stateHeaders
+		stateBody
+		stateHandler
+		statePreScript
+	)
+	flush := func() {
+		if cur != nil {
+			cur.Body = strings.TrimRight(strings.Join(body, "\n"), " \t\r\n")
+			if strings.TrimSpace(strings.Join(handler, "")) != "" {
+				cur.Notes = append(cur.Notes, "response handler script is not supported and was dropped:\n"+strings.TrimSpace(strings.Join(handler, "\n")))
+			}
+			finished = append(finished, *cur)
+		}
+		cur, title, name, state, body, handler, pre = nil, "", "", statePreamble, nil, nil, nil
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return HTTPFile{}, err
+		}
+		lineNo++
+		raw := strings.TrimRight(scanner.Text(), "\r")
+		line := strings.TrimSpace(raw)
+
+		if strings.HasPrefix(line, "###") {
+			flush()
+			title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+
+		switch state {
+		case statePreamble:
+			switch {
+			case line == "":
+			case httpNameTag.MatchString(line):
+				name = httpNameTag.FindStringSubmatch(line)[1]
+			case strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//"):
+			case strings.HasPrefix(line, "< {%"):
+				pre = append(pre, raw)
+				if !strings.HasSuffix(line, "%}") || len(line) < len("< {%%}") {
+					state = statePreScript
+				}
+			case httpVarDef.MatchString(line):
+				m := httpVarDef.FindStringSubmatch(line)
+				hf.Vars = append(hf.Vars, HTTPPair{Key: m[1], Value: strings.TrimSpace(m[2])})
+			default:
+				method, target := "GET", line
+				if verb, rest, ok := strings.Cut(line, " "); ok {
+					if _, known := httpMethods[strings.ToUpper(verb)]; known {
+						method, target = strings.ToUpper(verb), strings.TrimSpace(rest)
+					}
+				}
+				target = httpVersion.ReplaceAllString(target, "")
+				cur = &HTTPRequest{Name: name, Title: title, Method: method, URL: target, Line: lineNo}
+				if len(pre) > 0 {
+					cur.Notes = append(cur.Notes, "pre-request script is not supported and was dropped:\n"+strings.TrimSpace(strings.Join(pre, "\n")))
+					pre = nil
+				}
+				state = stateHeaders
+			}
+		case stateHeaders:
+			switch {
+			case line == "":
+				state = stateBody
+			case len(cur.Headers) == 0 && (strings.HasPrefix(line, "?") || strings.HasPrefix(line, "&")):
+				cur.URL += line
+			case strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//"):
+			case httpHeaderLine.MatchString(line):
+				m := httpHeaderLine.FindStringSubmatch(line)
+				cur.Headers = append(cur.Headers, HTTPPair{Key: m[1], Value: strings.TrimSpace(m[2])})
+			default:
+				// No blank line before the body.
+				state = stateBody
+				body = append(body, raw)
+			}
+		case stateBody:
+			switch {
+			case strings.HasPrefix(line, "> {%"):
+				handler = append(handler, raw)
+				if !strings.HasSuffix(line, "%}") || len(line) < len("> {%%}") {
+					state = stateHandler
+				}
+			case strings.HasPrefix(line, "> ") && strings.HasSuffix(line, ".js"):
+				handler = append(handler, raw)
+			case strings.HasPrefix(line, "<> ") || strings.HasPrefix(line, ">> ") || strings.HasPrefix(line, ">>! "):
+				// response references/redirects are editor features
+			case len(body) == 0 && (strings.HasPrefix(line, "< ") || strings.HasPrefix(line, "<@ ")):
+				cur.BodyFile = strings.TrimSpace(strings.TrimLeft(line, "<@"))
+			case len(body) == 0 && line == "":
+			default:
+				body = append(body, raw)
+			}
+		case stateHandler:
+			handler = append(handler, raw)
+			if strings.HasSuffix(line, "%}") {
+				state = stateBody
+			}
+		case statePreScript:
+			pre = append(pre, raw)
+			if strings.HasSuffix(line, "%}") {
+				state = statePreamble
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return HTTPFile{}, fmt.Errorf("%s: %w", path, err)
+	}
+	flush()
+	hf.Requests = finished
+
+	// File variables may build on earlier ones.
+	defined := map[string]string{}
+	for i, v := range hf.Vars {
+		v.Value = substituteVars(v.Value, defined)
+		hf.Vars[i] = v
+		defined[v.Key] = v.Value
+	}
+	hf.resolveRefs()
+	return hf, nil
+}
+
+// substituteVars replaces {{name}} placeholders found in vars and leaves the
+// rest (environment variables, dynamic variables) for run time.
+func substituteVars(s string, vars map[string]string) string {
+	return VarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[strings.TrimSpace(m[2:len(m)-2])]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// resolveRefs rewrites {{req.response.body.<path>}} and
+// {{req.response.headers.<name>}} into plain variables set by the named
+// request once it has run.
+func (hf *HTTPFile) resolveRefs() {
+	producers := map[string]int{}
+	for i, r := range hf.Requests {
+		if r.Name != "" {
+			if _, dup := producers[r.Name]; !dup {
+				producers[r.Name] = i
+			}
+		}
+	}
+	seen := map[string]struct{}{}
+	rewrite := func(s string, consumer int, notes *[]string) string {
+		return httpRef.ReplaceAllStringFunc(s, func(m string) string {
+			g := httpRef.FindStringSubmatch(m)
+			req, kind, part, sel := g[1], g[2], g[3], strings.TrimSpace(g[4])
+			idx, ok := producers[req]
+			if !ok || kind != "response" {
+				*notes = append(*notes, fmt.Sprintf("reference %s is not supported and was left as is", m))
+				return m
+			}
+			if consumer >= 0 && idx >= consumer {
+				*notes = append(*notes, fmt.Sprintf("reference %s points at a request that runs later", m))
+			}
+			varName, expr, ok := refVar(req, part, sel)
+			if !ok {
+				*notes = append(*notes, fmt.Sprintf("reference %s uses an unsupported selector and was left as is", m))
+				return m
+			}
+			if _, done := seen[varName]; !done {
+				seen[varName] = struct{}{}
+				hf.Requests[idx].PostVars = append(hf.Requests[idx].PostVars, HTTPPair{Key: varName, Value: expr})
+			}
+			return "{{" + varName + "}}"
+		})
+	}
+	for i := range hf.Vars {
+		hf.Vars[i].Value = rewrite(hf.Vars[i].Value, -1, &hf.Notes)
+	}
+	for i := range hf.Requests {
+		r := &hf.Requests[i]
+		r.URL = rewrite(r.URL, i, &r.Notes)
+		for j := range r.Headers {
+			r.Headers[j].Value = rewrite(r.Headers[j].Value, i, &r.Notes)
+		}
+		r.Body = rewrite(r.Body, i, &r.Notes)
+	}
+}
+
+// refVar names the variable for a response reference and builds the res.*
+// expression that yields it.
+func refVar(req, part, sel string) (name, expr string, ok bool) {
+	switch part {
+	case "headers":
+		if sel == "" {
+			return "", "", false
+		}
+		return req + ".response.headers." + sel, fmt.Sprintf("res.headers[%q]", strings.ToLower(sel)), true
+	case "body":
+		if sel == "*" || sel == "" {
+			return req + ".response.body", "res.body", true
+		}
+		path, ok := strings.CutPrefix(sel, "$")
+		if !ok || strings.Contains(path, "..") || strings.ContainsAny(path, "*?@()") || (path != "" && path[0] != '.' && path[0] != '[') {
+			return "", "", false
+		}
+		name = req + ".response.body"
+		for _, id := range httpPathIdent.FindAllString(path, -1) {
+			name += "." + id
+		}
+		return name, "res.body" + path, true
+	}
+	return "", "", false
+}
+
+// Cases converts the file into runnable cases, one per request, in file
+// order. File variables become vars:pre-request and "< file" bodies are read.
+func (hf HTTPFile) Cases() ([]ParsedFile, error) {
+	vars := map[string]string{}
+	for _, v := range hf.Vars {
+		vars[v.Key] = v.Value
+	}
+	out := make([]ParsedFile, 0, len(hf.Requests))
+	for i, r := range hf.Requests {
+		if i == 0 {
+			r.Notes = append(slices.Clone(hf.Notes), r.Notes...)
+		}
+		pf := ParsedFile{
+			FilePath: hf.Path,
+			Format:   "http",
+			Meta:     MetaBlock{Name: r.DisplayName(), Type: "http", Seq: float64(i + 1)},
+			Request: RequestBlock{
+				Verb:    r.Method,
+				URL:     r.URL,
+				Headers: map[string]string{},
+			},
+			Docs: strings.Join(r.Notes, "\n"),
+		}
+		if len(vars) > 0 {
+			pf.VarsPre = maps.Clone(vars)
+		}
+		if len(r.PostVars) > 0 {
+			pf.VarsPost = map[string]string{}
+			for _, pv := range r.PostVars {
+				pf.VarsPost[pv.Key] = pv.Value
+			}
+		}
+		contentType := ""
+		for _, h := range r.Headers {
+			pf.Request.Headers[h.Key] = h.Value
+			if strings.EqualFold(h.Key, "Content-Type") {
+				contentType = h.Value
+			}
+		}
+		body := r.Body
+		if r.BodyFile != "" {
+			p := r.BodyFile
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(filepath.Dir(hf.Path), p)
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: body file: %w", hf.Path, r.Line, err)
+			}
+			body = string(b)
+		}
+		if body != "" {
+			if strings.HasPrefix(strings.ToLower(contentType), "application/x-www-form-urlencoded") {
+				// REST Client joins multi-line form bodies.
+				lines := strings.Split(body, "\n")
+				for j := range lines {
+					lines[j] = strings.TrimSpace(lines[j])
+				}
+				body = strings.Join(lines, "")
+			}
+			// Sent verbatim with the file's own headers.
+			pf.Request.Body = BodyBlock{Raw: body, Type: "raw", Present: true}
+		}
+		out = append(out, pf)
+	}
+	return out, nil
+}
+
+// HTTPClientEnvs loads http-client.env.json from dir, overlaid with
+// http-client.private.env.json, and returns each named environment merged
+// over "$shared". It returns nil when dir has no env file.
+func HTTPClientEnvs(dir string) (map[string]map[string]string, error) {
+	var found bool
+	raw := map[string]map[string]any{}
+	for _, name := range []string{"http-client.env.json", "http-client.private.env.json"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		var doc map[string]map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for env, vals := range doc {
+			if raw[env] == nil {
+				raw[env] = map[string]any{}
+			}
+			maps.Copy(raw[env], vals)
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	shared := flattenEnv(raw["$shared"])
+	envs := map[string]map[string]string{}
+	for env, vals := range raw {
+		if env == "$shared" {
+			continue
+		}
+		merged := maps.Clone(shared)
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		maps.Copy(merged, flattenEnv(vals))
+		envs[env] = merged
+	}
+	return envs, nil
+}
+
+// FindHTTPClientEnv looks for http-client.env.json in dir and its parents and
+// returns the named environment.
+func FindHTTPClientEnv(dir, name string) (map[string]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		envs, err := HTTPClientEnvs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if envs != nil {
+			vars, ok := envs[name]
+			if !ok {
+				names := make([]string, 0, len(envs))
+				for k := range envs {
+					names = append(names, k)
+				}
+				sort.Strings(names)
+				return nil, fmt.Errorf("environment %q not found in %s (have %s)", name, filepath.Join(dir, "http-client.env.json"), strings.Join(names, ", "))
+			}
+			return vars, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("no http-client.env.json found for environment %q", name)
+		}
+		dir = parent
+	}
+}
+
+// flattenEnv turns env JSON values into strings; nested objects use dotted keys.
+func flattenEnv(vals map[string]any) map[string]string {
+	if vals == nil {
+		return nil
+	}
+	out := map[string]string{}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			for k, sub := range t {
+				walk(prefix+"."+k, sub)
+			}
+		case string:
+			out[prefix] = t
+		case nil:
+			out[prefix] = ""
+		default:
+			b, _ := json.Marshal(t)
+			out[prefix] = string(b)
+		}
+	}
+	for k, v := range vals {
+		walk(k, v)
+	}
+	return out
+}
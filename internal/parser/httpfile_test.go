@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseHTTPFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "payload.json"), []byte(`{"from":"file"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `@base = {{host}}/v1
+@user = ada
+
+### Log in
+# @name login
+POST {{base}}/login HTTP/1.1
+Content-Type: application/json
+
+{"user": "{{user}}"}
+
+> {%
+  client.global.set("token", response.body.token);
+%}
+
+###
+GET {{base}}/items
+    ?page=2
+    &size=10
+Authorization: Bearer {{login.response.body.$.data.token}}
+X-Session: {{login.response.headers.X-Session}}
+
+###
+// plain comment
+PUT {{base}}/items/1
+Content-Type: application/json
+
+< ./payload.json
+
+###
+https://example.test/health
+`
+	path := filepath.Join(dir, "api.http")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hf, err := ParseHTTPFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(hf.Vars) != 2 || hf.Vars[0].Value != "{{host}}/v1" {
+		t.Fatalf("vars: %+v", hf.Vars)
+	}
+	if len(hf.Requests) != 4 {
+		t.Fatalf("requests: %+v", hf.Requests)
+	}
+	login := hf.Requests[0]
+	if login.Name != "login" || login.Title != "Log in" || login.Method != "POST" || login.URL != "{{base}}/login" {
+		t.Fatalf("login: %+v", login)
+	}
+	if login.Body != `{"user": "{{user}}"}` || len(login.Notes) != 1 || !strings.Contains(login.Notes[0], "response handler") {
+		t.Fatalf("login body/notes: %q %q", login.Body, login.Notes)
+	}
+	wantPost := []HTTPPair{
+		{Key: "login.response.body.data.token", Value: "res.body.data.token"},
+		{Key: "login.response.headers.X-Session", Value: `res.headers["x-session"]`},
+	}
+	if len(login.PostVars) != 2 || login.PostVars[0] != wantPost[0] || login.PostVars[1] != wantPost[1] {
+		t.Fatalf("post vars: %+v", login.PostVars)
+	}
+	items := hf.Requests[1]
+	if items.URL != "{{base}}/items?page=2&size=10" || items.DisplayName() != "GET /items" {
+		t.Fatalf("items: %+v (%s)", items, items.DisplayName())
+	}
+	if items.Headers[0].Value != "Bearer {{login.response.body.data.token}}" {
+		t.Fatalf("ref not rewritten: %+v", items.Headers)
+	}
+	if hf.Requests[2].BodyFile != "./payload.json" || hf.Requests[3].Method != "GET" || hf.Requests[3].URL != "https://example.test/health" {
+		t.Fatalf("put/health: %+v %+v", hf.Requests[2], hf.Requests[3])
+	}
+
+	cases, err := hf.Cases()
+	if err != nil {
+		t.Fatalf("cases: %v", err)
+	}
+	if len(cases) != 4 || cases[0].Format != "http" || cases[1].Meta.Seq != 2 || cases[0].VarsPre["user"] != "ada" {
+		t.Fatalf("cases: %+v", cases)
+	}
+	if cases[2].Request.Body.Raw != `{"from":"file"}` || cases[2].Request.Body.Type != "raw" {
+		t.Fatalf("body file: %+v", cases[2].Request.Body)
+	}
+	if cases[0].VarsPost["login.response.body.data.token"] != "res.body.data.token" || cases[1].VarsPost != nil || cases[0].Request.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("login case: %+v", cases[0])
+	}
+}
+
+func TestHTTPClientEnvs(t *testing.T) {
+	dir := t.TempDir()
+	pub := `{"$shared": {"host": "https://shared.test", "retries": 2}, "dev": {"host": "https://dev.test", "auth": {"user": "u"}}}`
+	priv := `{"dev": {"password": "p"}}`
+	if err := os.WriteFile(filepath.Join(dir, "http-client.env.json"), []byte(pub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "http-client.private.env.json"), []byte(priv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dev, err := FindHTTPClientEnv(sub, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev["host"] != "https://dev.test" || dev["retries"] != "2" || dev["auth.user"] != "u" || dev["password"] != "p" {
+		t.Fatalf("dev env: %v", dev)
+	}
+	if _, err := FindHTTPClientEnv(sub, "prod"); err == nil || !strings.Contains(err.Error(), "have dev") {
+		t.Fatalf("expected missing env error, got %v", err)
+	}
+}
+
+func TestDiscoverCasesFSRejectsHTTPFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ok.bru":        {Data: []byte("get {\n  url: https://example.com\n}\n")},
+		"requests.http": {Data: []byte("GET https://example.com\n")},
+	}
+	if _, _, err := DiscoverCasesFS(fsys, ".", DiscoverOptions{}); err == nil || !strings.Contains(err.Error(), "requests.http") {
+		t.Fatalf("expected an error naming the .http file, got %v", err)
+	}
+	cases, diags, err := DiscoverCasesFS(fsys, ".", DiscoverOptions{CollectErrors: true})
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(cases) != 1 || len(diags) != 1 || diags[0].File != "requests.http" {
+		t.Fatalf("cases=%+v diags=%v", cases, diags)
+	}
+}
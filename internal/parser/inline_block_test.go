@@ -91,3 +91,68 @@ post { url: https://api.test/body headers { X-One: 1 } body:json { { "k": "v", "
 		t.Fatalf("body type mismatch: %s", pf.Request.Body.Type)
 	}
 }
+
+func TestParseSkipsDisabledHeaders(t *testing.T) {
+	tmp := t.TempDir()
+	bru := `meta {
+  name: Disabled Header
+}
+
+get {
+  url: https://api.test/h
+}
+
+headers {
+  X-On: 1
+  ~X-Off: 2
+}
+`
+	path := filepath.Join(tmp, "disabled.bru")
+	if err := os.WriteFile(path, []byte(bru), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pf, err := ParseFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pf.Request.Headers["X-On"] != "1" {
+		t.Fatalf("enabled header missing: %+v", pf.Request.Headers)
+	}
+	if _, ok := pf.Request.Headers["X-Off"]; ok || len(pf.Request.Headers) != 1 {
+		t.Fatalf("disabled header should be skipped: %+v", pf.Request.Headers)
+	}
+}
+
+func TestParseHeadAndOptionsVerbs(t *testing.T) {
+	tmp := t.TempDir()
+	for verb, want := range map[string]string{"head": "HEAD", "options": "OPTIONS"} {
+		path := filepath.Join(tmp, verb+".bru")
+		bru := "meta { name: " + verb + " }\n\n" + verb + " {\n  url: https://api.test/x\n}\n"
+		if err := os.WriteFile(path, []byte(bru), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		pf, err := ParseFile(context.Background(), path)
+		if err != nil {
+			t.Fatalf("parse %s: %v", verb, err)
+		}
+		if pf.Request.Verb != want || pf.Request.URL != "https://api.test/x" {
+			t.Fatalf("%s: got %+v", verb, pf.Request)
+		}
+	}
+}
+
+func TestParseVarsPostResponseOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vars.bru")
+	bru := "meta {\n  name: Vars\n}\n\nget {\n  url: https://example.com\n}\n\nvars:post-response {\n  zeta: res.body.id\n  // note: ignored\n  ~off: 1\n  alpha: bru.getVar(\"zeta\") + 1\n  zeta: res.body.other\n}\n"
+	if err := os.WriteFile(path, []byte(bru), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pf, err := ParseFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := strings.Join(pf.VarsPostOrder, ","); got != "zeta,alpha" || pf.VarsPost["zeta"] != "res.body.other" {
+		t.Fatalf("order %q vars %v", got, pf.VarsPost)
+	}
+}
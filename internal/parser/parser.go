@@ -1,12 +1,12 @@
 package parser
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
 	"os"
 	"path/filepath"
@@ -16,24 +16,44 @@ import (
 )
 
 var verbSet = map[string]struct{}{
-	"get":    {},
-	"post":   {},
-	"put":    {},
-	"patch":  {},
-	"delete": {},
+	"get":     {},
+	"post":    {},
+	"put":     {},
+	"patch":   {},
+	"delete":  {},
+	"head":    {},
+	"options": {},
 }
 
-// ParsedFile captures a parsed .bru file.
+// IsVerb reports whether method (any case) has a request block in the .bru
+// grammar.
+func IsVerb(method string) bool {
+	_, ok := verbSet[strings.ToLower(method)]
+	return ok
+}
+
+// ParsedFile is the format-agnostic case model the runner executes: a parsed
+// .bru file or one request of a .http file.
 type ParsedFile struct {
 	FilePath string
+	// Format is "http" for cases read from .http/.rest files, empty for .bru.
+	Format   string
 	Meta     MetaBlock
 	Request  RequestBlock
 	TestsRaw string
-	Docs     string
-	Assert   []AssertRule
-	Scripts  ScriptBlock
-	VarsPre  map[string]string
-	VarsPost map[string]string
+	// TestsLine is the 1-based line of the first TestsRaw line in the file,
+	// 0 when unknown.
+	TestsLine int
+	Docs      string
+	Assert    []AssertRule
+	// AssertLines holds the 1-based line of each Assert rule in the file,
+	// index for index; nil when unknown.
+	AssertLines []int
+	Scripts     ScriptBlock
+	VarsPre     map[string]string
+	VarsPost    map[string]string
+	// VarsPostOrder lists the VarsPost keys in file order; nil when unknown.
+	VarsPostOrder []string
 }
 
 // MetaBlock stores top-level meta attributes of a case.
@@ -68,12 +88,21 @@ type RequestBlock struct {
 
 // BodyBlock represents the body block (json/xml/text/form/etc.).
 type BodyBlock struct {
-	Raw     string
-	Type    string // json, xml, text, graphql, form-urlencoded, multipart-form, raw
-	Fields  map[string]string
+	Raw    string
+	Type   string // json, xml, text, graphql, form-urlencoded, multipart-form, raw
+	Fields map[string]string
+	// Form holds ordered form fields of cases built in Go rather than
+	// parsed; it takes precedence over Raw and Fields when set.
+	Form    []FormField
 	Present bool
 }
 
+// FormField is one form-urlencoded or multipart-form field.
+type FormField struct {
+	Name  string
+	Value string
+}
+
 // ScriptBlock contains pre/post response scripts.
 type ScriptBlock struct {
 	PreRequest     string
@@ -88,40 +117,141 @@ type AssertRule struct {
 	Right string
 }
 
-// DiscoverBruFiles walks a folder and parses .bru files (skipping environments).
+// DiscoverOptions control DiscoverCasesWith.
+type DiscoverOptions struct {
+	// Recursive descends into subfolders.
+	Recursive bool
+	// CollectErrors keeps walking when a file does not parse and returns
+	// its diagnostics instead of failing on the first one.
+	CollectErrors bool
+}
+
+// DiscoverCases walks a folder and parses .bru files (skipping environments)
+// and .http/.rest files, which yield one case per request.
+func DiscoverCases(folder string, recursive bool) ([]ParsedFile, error) {
+	files, _, err := DiscoverCasesWith(folder, DiscoverOptions{Recursive: recursive})
+	return files, err
+}
+
+// DiscoverBruFiles walks a folder and parses its cases.
+//
+// Deprecated: use DiscoverCases, which this calls; it also reads .http files.
 func DiscoverBruFiles(folder string, recursive bool) ([]ParsedFile, error) {
+	return DiscoverCases(folder, recursive)
+}
+
+// DiscoverCasesWith is DiscoverCases with options. With CollectErrors set,
+// files that fail to parse are left out and their diagnostics returned in
+// the ErrorList; the error is then only set when the walk itself fails.
+func DiscoverCasesWith(folder string, opts DiscoverOptions) ([]ParsedFile, ErrorList, error) {
+	return discover(nil, folder, opts)
+}
+
+// DiscoverCasesFS is DiscoverCasesWith for a collection inside fsys (an
+// embed.FS, zip archive, ...), starting at root ("." for all of fsys). Each
+// FilePath is the slash-separated path within fsys. Only .bru files are
+// supported; a .http file is reported as an error (a diagnostic with
+// CollectErrors) rather than dropped.
+func DiscoverCasesFS(fsys fs.FS, root string, opts DiscoverOptions) ([]ParsedFile, ErrorList, error) {
+	return discover(fsys, root, opts)
+}
+
+// discover walks folder on disk, or in fsys when it is not nil.
+func discover(fsys fs.FS, folder string, opts DiscoverOptions) ([]ParsedFile, ErrorList, error) {
 	var files []ParsedFile
-	rootDepth := strings.Count(filepath.Clean(folder), string(os.PathSeparator))
-	err := filepath.WalkDir(folder, func(path string, d os.DirEntry, err error) error {
+	var diags ErrorList
+	fail := func(path string, perr error) error {
+		if !opts.CollectErrors {
+			return perr
+		}
+		var list ErrorList
+		var one *Error
+		switch {
+		case errors.As(perr, &list):
+			diags = append(diags, list...)
+		case errors.As(perr, &one):
+			diags = append(diags, one)
+		default:
+			diags = append(diags, &Error{File: path, Msg: perr.Error(), Err: perr})
+		}
+		return nil
+	}
+	visit := func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() {
 			if strings.EqualFold(d.Name(), "environments") {
-				return filepath.SkipDir
+				return fs.SkipDir
 			}
-			if !recursive && strings.Count(filepath.Clean(path), string(os.PathSeparator)) > rootDepth {
-				return filepath.SkipDir
+			if !opts.Recursive && path != folder {
+				return fs.SkipDir
 			}
 			return nil
 		}
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".bru") {
-			pf, perr := ParseFile(context.Background(), path)
+		switch {
+		case strings.HasSuffix(strings.ToLower(d.Name()), ".bru"):
+			var pf ParsedFile
+			var perr error
+			if fsys == nil {
+				pf, perr = ParseFile(context.Background(), path)
+			} else {
+				pf, perr = parseFS(fsys, path)
+			}
 			if perr != nil {
 				// Skip env-style .bru files that lack a request block.
 				if errors.Is(perr, errMissingRequest) {
 					return nil
 				}
-				return perr
+				return fail(path, perr)
 			}
 			files = append(files, pf)
+		case IsHTTPFile(d.Name()) && fsys != nil:
+			return fail(path, fmt.Errorf("%s: .http files cannot be run from an fs.FS collection", path))
+		case IsHTTPFile(d.Name()):
+			cases, perr := ParseCases(context.Background(), path)
+			if perr != nil {
+				return fail(path, perr)
+			}
+			files = append(files, cases...)
 		}
 		return nil
-	})
+	}
+	var err error
+	if fsys == nil {
+		err = filepath.WalkDir(folder, visit)
+	} else {
+		err = fs.WalkDir(fsys, folder, visit)
+	}
+	if err != nil {
+		return nil, diags, err
+	}
+	return files, diags, nil
+}
+
+func parseFS(fsys fs.FS, path string) (ParsedFile, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return ParsedFile{}, err
+	}
+	defer f.Close()
+	return parse(context.Background(), path, f)
+}
+
+// ParseCases parses a single .bru file, or every request of a .http/.rest file.
+func ParseCases(ctx context.Context, path string) ([]ParsedFile, error) {
+	if !IsHTTPFile(path) {
+		pf, err := ParseFile(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		return []ParsedFile{pf}, nil
+	}
+	hf, err := ParseHTTPFile(ctx, path)
 	if err != nil {
 		return nil, err
 	}
-	return files, nil
+	return hf.Cases()
 }
 
 // ParseFile reads and parses a single .bru file.
@@ -134,102 +264,70 @@ func ParseFile(ctx context.Context, path string) (ParsedFile, error) {
 	return parse(ctx, path, f)
 }
 
+// Parse parses .bru content read from r; path is recorded as FilePath.
+func Parse(ctx context.Context, path string, r io.Reader) (ParsedFile, error) {
+	return parse(ctx, path, r)
+}
+
 func parse(ctx context.Context, path string, r io.Reader) (ParsedFile, error) {
-	scanner := bufio.NewScanner(r)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ParsedFile{}, err
+	}
+	p := newFileParser(string(data))
 	pf := ParsedFile{FilePath: path}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	for ; p.i < len(p.lines); p.i++ {
+		line := strings.TrimSpace(p.lines[p.i])
 		if line == "" || strings.HasPrefix(line, "//") {
 			continue
 		}
 		lower := strings.ToLower(line)
+		p.header = p.i
 		switch {
 		case strings.HasPrefix(lower, "meta"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("meta: %w", err)
-			}
-			meta, err := parseMeta(block)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("meta: %w", err)
-			}
-			pf.Meta = meta
+			block, first := p.readBlock()
+			pf.Meta = p.parseMeta(block, first)
 		case strings.HasPrefix(lower, "tests"):
-			tests, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("tests: %w", err)
+			pf.TestsRaw = p.readBlockWithBraces()
+			pf.TestsLine = p.header + 2
+			if p.i == p.header {
+				pf.TestsLine = p.header + 1 // single-line block
 			}
-			pf.TestsRaw = tests
 		case strings.HasPrefix(lower, "docs"):
-			docs, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("docs: %w", err)
-			}
-			pf.Docs = docs
+			pf.Docs = p.readBlockWithBraces()
 		case strings.HasPrefix(lower, "assert"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("assert: %w", err)
-			}
-			pf.Assert = parseAssert(block)
+			block, first := p.readBlock()
+			pf.Assert, pf.AssertLines = parseAssert(block, first)
 		case strings.HasPrefix(lower, "script:pre-request"):
-			block, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("script pre: %w", err)
-			}
-			pf.Scripts.PreRequest = block
+			pf.Scripts.PreRequest = p.readBlockWithBraces()
 		case strings.HasPrefix(lower, "script:post-response"):
-			block, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("script post: %w", err)
-			}
-			pf.Scripts.PostResponse = block
+			pf.Scripts.PostResponse = p.readBlockWithBraces()
 		case strings.HasPrefix(lower, "vars:pre-request"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("vars pre: %w", err)
-			}
+			block, _ := p.readBlock()
 			pf.VarsPre = parseKVBlock(block)
 		case strings.HasPrefix(lower, "vars:post-response"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("vars post: %w", err)
-			}
+			block, _ := p.readBlock()
 			pf.VarsPost = parseKVBlock(block)
+			pf.VarsPostOrder = kvBlockKeys(block)
 		case strings.HasPrefix(lower, "headers"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("headers: %w", err)
-			}
+			block, _ := p.readBlock()
 			hdrs := parseHeaders(block)
 			if pf.Request.Headers == nil {
 				pf.Request.Headers = map[string]string{}
 			}
 			maps.Copy(pf.Request.Headers, hdrs)
 		case strings.HasPrefix(lower, "query"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("query: %w", err)
-			}
+			block, _ := p.readBlock()
 			pf.Request.Query = parseKVBlock(block)
 		case strings.HasPrefix(lower, "params:query"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("params:query: %w", err)
-			}
+			block, _ := p.readBlock()
 			pf.Request.Query = parseKVBlock(block)
 		case strings.HasPrefix(lower, "params:path"):
-			block, err := readBlock(scanner, line)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("params:path: %w", err)
-			}
+			block, _ := p.readBlock()
 			pf.Request.PathParams = parseKVBlock(block)
 		case strings.HasPrefix(lower, "body:graphql:vars"):
-			block, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("graphql vars: %w", err)
-			}
+			block := p.readBlockWithBraces()
 			if varsMap, err := parseJSONMap(block); err == nil {
 				pf.Request.GraphqlVars = varsMap
 			} else {
@@ -238,10 +336,7 @@ func parse(ctx context.Context, path string, r io.Reader) (ParsedFile, error) {
 		case strings.HasPrefix(lower, "body:"):
 			bType := strings.TrimSpace(strings.TrimPrefix(lower, "body:"))
 			bType = strings.TrimSpace(strings.TrimSuffix(bType, "{"))
-			block, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("body: %w", err)
-			}
+			block := p.readBlockWithBraces()
 			pf.Request.Body.Present = true
 			if bType == "" {
 				bType = "json"
@@ -253,31 +348,28 @@ func parse(ctx context.Context, path string, r io.Reader) (ParsedFile, error) {
 			}
 		case strings.HasPrefix(lower, "body"):
 			// plain body treated as JSON
-			block, err := readBlockWithBraces(line, scanner)
-			if err != nil {
-				return ParsedFile{}, fmt.Errorf("body: %w", err)
-			}
+			block := p.readBlockWithBraces()
 			pf.Request.Body.Present = true
 			pf.Request.Body.Type = "json"
 			pf.Request.Body.Raw = block
 		default:
 			for verb := range verbSet {
-				if strings.HasPrefix(lower, verb) {
-					block, err := readBlock(scanner, line)
-					if err != nil {
-						return ParsedFile{}, fmt.Errorf("request: %w", err)
-					}
+				if rest, ok := strings.CutPrefix(lower, verb); ok && (rest == "" || rest[0] == ' ' || rest[0] == '{') {
+					block, _ := p.readBlock()
 					req, err := parseRequest(verb, block)
 					if err != nil {
-						return ParsedFile{}, fmt.Errorf("request: %w", err)
+						p.errorf(p.header, "", "%v", err).Err = err
 					}
 					pf.Request = req
 				}
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return ParsedFile{}, err
+	if len(p.errs) > 0 {
+		for _, e := range p.errs {
+			e.File = path
+		}
+		return ParsedFile{}, p.errs
 	}
 	if pf.Request.Verb == "" {
 		return ParsedFile{}, errMissingRequest
@@ -287,9 +379,10 @@ func parse(ctx context.Context, path string, r io.Reader) (ParsedFile, error) {
 
 var errMissingRequest = errors.New("missing request block")
 
-func parseMeta(lines []string) (MetaBlock, error) {
+// parseMeta reads the meta entries; first is the line index of lines[0].
+func (p *fileParser) parseMeta(lines []string, first int) MetaBlock {
 	m := MetaBlock{}
-	for _, l := range lines {
+	for i, l := range lines {
 		l = strings.TrimSpace(l)
 		if l == "" || strings.HasPrefix(l, "//") {
 			continue
@@ -309,7 +402,12 @@ func parseMeta(lines []string) (MetaBlock, error) {
 		case "seq":
 			f, err := strconv.ParseFloat(val, 64)
 			if err != nil {
-				return MetaBlock{}, err
+				e := p.errorf(first+i, "use a number such as seq: 1", "seq %q is not a number", val)
+				e.Err = err
+				if raw := p.lines[first+i]; strings.Contains(raw, val) {
+					e.Col = strings.Index(raw, val) + 1
+				}
+				continue
 			}
 			m.Seq = f
 		case "description":
@@ -352,7 +450,7 @@ func parseMeta(lines []string) (MetaBlock, error) {
 			m.Skip = strings.EqualFold(val, "false")
 		}
 	}
-	return m, nil
+	return m
 }
 
 func parseRequest(verb string, lines []string) (RequestBlock, error) {
@@ -457,7 +555,7 @@ func parseHeaders(lines []string) map[string]string {
 	h := map[string]string{}
 	for _, l := range lines {
 		trimmed := strings.TrimSpace(l)
-		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "~") {
 			continue
 		}
 		kv := strings.SplitN(trimmed, ":", 2)
@@ -491,6 +589,29 @@ func parseKVBlock(lines []string) map[string]string {
 	return m
 }
 
+// kvBlockKeys returns the keys of a block parsed by parseKVBlock in file
+// order, each once.
+func kvBlockKeys(lines []string) []string {
+	var keys []string
+	seen := map[string]struct{}{}
+	for _, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "~") {
+			continue
+		}
+		k, _, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		k = strings.Trim(strings.TrimSpace(k), "\"")
+		if _, dup := seen[k]; !dup {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
 func parseFormBlock(lines []string) map[string]string {
 	m := map[string]string{}
 	for _, l := range lines {
@@ -571,9 +692,12 @@ func findBalancedInline(s string) (start int, end int, ok bool) {
 	return idx + 1, endIdx, true
 }
 
-func parseAssert(lines []string) []AssertRule {
+// parseAssert reads assert rules and their 1-based lines; first is the line
+// index of lines[0].
+func parseAssert(lines []string, first int) ([]AssertRule, []int) {
 	var rules []AssertRule
-	for _, l := range lines {
+	var ruleLines []int
+	for i, l := range lines {
 		trimmed := strings.TrimSpace(l)
 		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
 			continue
@@ -588,13 +712,75 @@ func parseAssert(lines []string) []AssertRule {
 			continue
 		}
 		rules = append(rules, AssertRule{Left: left, Op: rightParts[0], Right: strings.Join(rightParts[1:], " ")})
+		ruleLines = append(ruleLines, first+i+1)
+	}
+	return rules, ruleLines
+}
+
+// fileParser walks the lines of a .bru file. i is the current line and
+// header the header line of the block being read; errs collects diagnostics
+// so parsing can continue after a problem.
+type fileParser struct {
+	lines  []string
+	i      int
+	header int
+	errs   ErrorList
+}
+
+func newFileParser(src string) *fileParser {
+	lines := strings.Split(src, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return &fileParser{lines: lines}
+}
+
+// errorf records a diagnostic for line index idx in the current block.
+func (p *fileParser) errorf(idx int, hint, format string, args ...any) *Error {
+	e := &Error{Line: idx + 1, Col: 1, Block: p.blockName(), Msg: fmt.Sprintf(format, args...), Hint: hint}
+	if idx < len(p.lines) {
+		e.Col = len(p.lines[idx]) - len(strings.TrimLeft(p.lines[idx], " \t")) + 1
 	}
-	return rules
+	p.errs = append(p.errs, e)
+	return e
 }
 
-func readBlock(scanner *bufio.Scanner, firstLine string) ([]string, error) {
+// blockName is the name on the current header line, e.g. "body:json".
+func (p *fileParser) blockName() string {
+	name := strings.TrimSpace(p.lines[p.header])
+	if i := strings.IndexAny(name, " {["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// topLevelHeader matches the header of a known block in column one. A block
+// that runs into one was not closed.
+var topLevelHeader = regexp.MustCompile(`^(?i)(meta|get|post|put|patch|delete|head|options|connect|trace|headers|query|params:[\w-]+|body(:[\w:-]+)?|auth(:[\w-]+)?|vars(:[\w-]+)?|assert|script(:[\w-]+)?|tests|docs|settings)\s*[{\[]`)
+
+// endsBlock reports whether the next non-blank line from index i is a
+// top-level header or the end of the file.
+func (p *fileParser) endsBlock(i int) bool {
+	for ; i < len(p.lines); i++ {
+		if strings.TrimSpace(p.lines[i]) != "" {
+			return topLevelHeader.MatchString(p.lines[i])
+		}
+	}
+	return true
+}
+
+// readBlock reads the dictionary block whose header is the current line and
+// returns its lines and the index of the first one. It stops at the closing
+// brace; an unclosed block ends before the next top-level header.
+func (p *fileParser) readBlock() ([]string, int) {
+	header := p.i
+	firstLine := p.lines[header]
 	if !strings.Contains(firstLine, "{") {
-		return nil, errors.New("missing opening brace")
+		p.errorf(header, fmt.Sprintf("write it as %s { ... }", p.blockName()), "missing opening brace")
+		return nil, header
 	}
 	var lines []string
 
@@ -602,48 +788,62 @@ func readBlock(scanner *bufio.Scanner, firstLine string) ([]string, error) {
 		if trimmed := strings.TrimSpace(firstLine[first:last]); trimmed != "" {
 			lines = append(lines, trimmed)
 		}
-		return lines, nil
+		return lines, header
 	}
 
 	depth := strings.Count(firstLine, "{") - strings.Count(firstLine, "}")
-	for scanner.Scan() {
-		line := scanner.Text()
+	for p.i+1 < len(p.lines) {
+		line := p.lines[p.i+1]
+		if topLevelHeader.MatchString(line) {
+			p.errorf(header, "add the missing }", "block is not closed before line %d", p.i+2)
+			return lines, header + 1
+		}
+		p.i++
 		depth += strings.Count(line, "{")
 		depth -= strings.Count(line, "}")
 		if depth <= 0 {
-			break
+			return lines, header + 1
 		}
 		lines = append(lines, line)
 	}
-	if depth != 0 {
-		return nil, errors.New("unbalanced braces")
-	}
-	return lines, nil
+	p.errorf(header, "add the missing }", "block is not closed at end of file")
+	return lines, header + 1
 }
 
-func readBlockWithBraces(firstLine string, scanner *bufio.Scanner) (string, error) {
+// readBlockWithBraces reads the text block (body, script, tests, docs) whose
+// header is the current line. The block ends at a "}" line that balances
+// its braces; like Bruno, a "}" in column one followed by another block or
+// the end of the file also ends it, so unbalanced braces in strings or
+// comments cannot swallow the rest of the file.
+func (p *fileParser) readBlockWithBraces() string {
+	header := p.i
+	firstLine := p.lines[header]
 	if first, last, ok := findBalancedInline(firstLine); ok {
-		content := strings.TrimSpace(firstLine[first:last])
-		return content, nil
+		return strings.TrimSpace(firstLine[first:last])
 	}
 
 	depth := strings.Count(firstLine, "{") - strings.Count(firstLine, "}")
 	var sb strings.Builder
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		depth += strings.Count(line, "{")
-		depth -= strings.Count(line, "}")
-		if depth <= 0 {
-			break
+	for p.i+1 < len(p.lines) {
+		line := p.lines[p.i+1]
+		next := depth + strings.Count(line, "{") - strings.Count(line, "}")
+		if strings.TrimSpace(line) == "}" {
+			if next <= 0 && (line == "}" || p.endsBlock(p.i+2)) || line == "}" && p.endsBlock(p.i+2) {
+				p.i++
+				return sb.String()
+			}
+		}
+		if topLevelHeader.MatchString(line) {
+			p.errorf(header, `close it with "}" in column one`, "block is not closed before line %d", p.i+2)
+			return sb.String()
 		}
+		p.i++
+		depth = next
 		sb.WriteString(line)
 		sb.WriteString("\n")
 	}
-	if depth != 0 {
-		return "", errors.New("unbalanced braces")
-	}
-	return sb.String(), nil
+	p.errorf(header, `close it with "}" in column one`, "block is not closed at end of file")
+	return sb.String()
 }
 
 // VarPattern matches {{var}} placeholders inside requests.
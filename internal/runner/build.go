@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"sort"
+
+	"pkt.systems/gruno/internal/parser"
+)
+
+// PreparedRequest is an HTTP request built exactly as the runner would send
+// it, before hooks and pre-request scripts run.
+type PreparedRequest struct {
+	Name     string
+	FilePath string
+	Seq      float64
+	Request  *http.Request
+}
+
+// LoadCases parses the .bru/.http file or folder at path in run order (folders
+// are walked like RunFolder) and keeps the cases passing the tag filters. It
+// also returns the variables a run would start with: opts.EnvPath overlaid
+// with opts.Vars.
+func LoadCases(ctx context.Context, path string, opts RunOptions) ([]parser.ParsedFile, map[string]string, error) {
+	var info fs.FileInfo
+	var err error
+	if opts.FS != nil {
+		info, err = fs.Stat(opts.FS, fsPath(path))
+	} else {
+		info, err = os.Stat(path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []parser.ParsedFile
+	if info.IsDir() || opts.FS != nil {
+		if files, err = discoverCases(path, opts); err != nil {
+			return nil, nil, err
+		}
+	} else if files, err = parser.ParseCases(ctx, path); err != nil {
+		return nil, nil, err
+	}
+
+	envVars, err := loadEnv(ctx, opts.FS, opts.EnvPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load env: %w", err)
+	}
+	if envVars == nil {
+		envVars = map[string]string{}
+	}
+	maps.Copy(envVars, opts.Vars)
+
+	var out []parser.ParsedFile
+	for _, f := range files {
+		if passesTagFilter(f.Meta.Tags, opts.Tags, opts.ExcludeTags) {
+			out = append(out, f)
+		}
+	}
+	return out, envVars, nil
+}
+
+// BuildRequests parses the .bru/.http file or folder at path and builds its
+// requests with opts.EnvPath, opts.Vars and vars:pre-request interpolated.
+// Folders are walked and ordered like RunFolder and honour the tag filters.
+// Scripts are not executed and nothing is sent.
+func BuildRequests(ctx context.Context, path string, opts RunOptions) ([]PreparedRequest, error) {
+	files, envVars, err := LoadCases(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []PreparedRequest
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		exp := newExpander(envVars)
+		if err := applyHTTPClientEnv(exp, f, opts.HTTPClientEnv); err != nil {
+			return nil, err
+		}
+		maps.Copy(exp.vars, f.VarsPre)
+		req, err := buildHTTPRequest(f, exp, opts.FS)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.FilePath, err)
+		}
+		out = append(out, PreparedRequest{Name: f.Meta.Name, FilePath: f.FilePath, Seq: f.Meta.Seq, Request: req.WithContext(ctx)})
+	}
+	return out, nil
+}
+
+// sortBySeq orders cases by meta seq, then path, matching folder runs.
+func sortBySeq(files []parser.ParsedFile) {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Meta.Seq == files[j].Meta.Seq {
+			return files[i].FilePath < files[j].FilePath
+		}
+		return files[i].Meta.Seq < files[j].Meta.Seq
+	})
+}
@@ -182,6 +182,150 @@ headers {
 	}
 }
 
+// The bruno reporter must describe a run like gru's own JSON report does,
+// in the layout parseBruStatuses reads from bru reports. Needs no bru.
+func TestCLIBrunoReporterMatchesJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short mode: skipping gru binary build")
+	}
+
+	srv := suiteServer()
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	setupMiniCollection(t, tmp, srv.URL)
+	gruBin := buildGruBinary(t, tmp)
+
+	gruJSON := filepath.Join(tmp, "gru.json.report")
+	brunoJSON := filepath.Join(tmp, "gru.bruno.report")
+	runGru := exec.Command(gruBin, "run", "cases", "-r", "--env", filepath.Join("environments", "local.bru"),
+		"--reporter-json", gruJSON, "--reporter", "bruno="+brunoJSON)
+	runGru.Dir = tmp
+	if out, err := runGru.CombinedOutput(); err != nil {
+		if _, statErr := os.Stat(brunoJSON); statErr != nil {
+			t.Fatalf("gru run failed and no report: %v output=%s", err, out)
+		}
+	}
+
+	brunoStatuses, brunoCounts, brunoSnap := parseBruStatuses(t, brunoJSON)
+	gruStatuses, gruCounts, gruSnap := parseGruStatuses(t, gruJSON)
+	if !equalStatusMaps(brunoStatuses, gruStatuses) {
+		t.Fatalf("status map mismatch\nbruno=%v\ngru=%v", brunoStatuses, gruStatuses)
+	}
+	if brunoCounts != gruCounts {
+		t.Fatalf("status counts mismatch bruno=%v gru=%v", brunoCounts, gruCounts)
+	}
+	assertSnapshotsClose(t, brunoSnap, gruSnap)
+
+	keys := brunoReportKeys(t, brunoJSON)
+	for _, want := range []string{
+		"summary.totalRequests", "summary.passedRequests", "summary.failedRequests", "summary.totalTests", "summary.failedTests",
+		"result.request", "result.response", "result.assertionResults", "result.testResults", "result.status",
+		"request.method", "request.url", "request.headers",
+		"response.status", "response.headers", "response.data", "response.responseTime",
+	} {
+		if !keys[want] {
+			t.Fatalf("bruno report lacks %s (has %v)", want, keys)
+		}
+	}
+}
+
+// Same collection through bru and through gru's bruno reporter: the reports
+// must agree on statuses and share bru's keys.
+func TestCLIReporterParityBrunoJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short mode: skipping bru parity")
+	}
+	if _, err := exec.LookPath("bru"); err != nil {
+		t.Skip("bru CLI not installed")
+	}
+
+	srv := suiteServer()
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	setupMiniCollection(t, tmp, srv.URL)
+	gruBin := buildGruBinary(t, tmp)
+	envPath := filepath.Join("environments", "local.bru")
+
+	bruJSON := filepath.Join(tmp, "bru.json.report")
+	brunoJSON := filepath.Join(tmp, "gru.bruno.report")
+	runBru := exec.Command("bru", "run", "cases", "-r", "--env-file", envPath, "--reporter-json", bruJSON)
+	runBru.Dir = tmp
+	if out, err := runBru.CombinedOutput(); err != nil {
+		if _, statErr := os.Stat(bruJSON); statErr != nil {
+			t.Fatalf("bru run failed and no report: %v output=%s", err, out)
+		}
+	}
+	runGru := exec.Command(gruBin, "run", "cases", "-r", "--env", envPath, "--reporter", "bruno="+brunoJSON)
+	runGru.Dir = tmp
+	if out, err := runGru.CombinedOutput(); err != nil {
+		if _, statErr := os.Stat(brunoJSON); statErr != nil {
+			t.Fatalf("gru run failed and no report: %v output=%s", err, out)
+		}
+	}
+
+	bruStatuses, bruCounts, bruSnap := parseBruStatuses(t, bruJSON)
+	gruStatuses, gruCounts, gruSnap := parseBruStatuses(t, brunoJSON)
+	if !equalStatusMaps(bruStatuses, gruStatuses) {
+		t.Fatalf("status map mismatch\nbru=%v\ngru=%v", bruStatuses, gruStatuses)
+	}
+	if bruCounts != gruCounts {
+		t.Fatalf("status counts mismatch bru=%v gru=%v", bruCounts, gruCounts)
+	}
+	sortSnapshots(bruSnap)
+	sortSnapshots(gruSnap)
+	assertSnapshotsClose(t, bruSnap, gruSnap)
+
+	bruKeys := brunoReportKeys(t, bruJSON)
+	gruKeys := brunoReportKeys(t, brunoJSON)
+	for k := range bruKeys {
+		switch {
+		case strings.HasPrefix(k, "summary."), strings.HasPrefix(k, "request."), strings.HasPrefix(k, "response."),
+			k == "result.assertionResults", k == "result.testResults", k == "result.status", k == "result.name":
+			if !gruKeys[k] {
+				t.Errorf("gru bruno report lacks %s", k)
+			}
+		}
+	}
+}
+
+// brunoReportKeys lists the keys of the first iteration's summary and of its
+// results and their request and response, as "summary.x", "result.x",
+// "request.x" and "response.x".
+func brunoReportKeys(t *testing.T, path string) map[string]bool {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var payload []map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	if len(payload) == 0 {
+		t.Fatalf("empty report %s", path)
+	}
+	keys := map[string]bool{}
+	add := func(prefix string, v any) {
+		m, _ := v.(map[string]any)
+		for k := range m {
+			keys[prefix+"."+k] = true
+		}
+	}
+	add("summary", payload[0]["summary"])
+	results, _ := payload[0]["results"].([]any)
+	for _, r := range results {
+		rm, _ := r.(map[string]any)
+		add("result", rm)
+		if rm != nil {
+			add("request", rm["request"])
+			add("response", rm["response"])
+		}
+	}
+	return keys
+}
+
 func TestCLIParityControlFlags(t *testing.T) {
 	if testing.Short() {
 		t.Skip("short mode: skipping bru parity")
@@ -3,19 +3,28 @@ package runner
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"fmt"
+	"io/fs"
 	"maps"
+	"math/big"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"pkt.systems/gruno/internal/parser"
 )
 
-// loadEnv parses an env .bru file containing vars { key: value }.
-func loadEnv(ctx context.Context, path string) (map[string]string, error) {
+// loadEnv parses an env .bru file containing vars { key: value }, read
+// from fsys when it is not nil.
+func loadEnv(ctx context.Context, fsys fs.FS, path string) (map[string]string, error) {
 	if path == "" {
 		return nil, nil
 	}
-	f, err := os.Open(path)
+	f, err := openFile(fsys, path)
 	if err != nil {
 		return nil, err
 	}
@@ -52,9 +61,35 @@ func loadEnv(ctx context.Context, path string) (map[string]string, error) {
 	return vars, nil
 }
 
+// applyHTTPClientEnv switches exp to .http semantics for cases read from
+// .http files and fills it with the named http-client.env.json environment.
+// Values already present (--env .bru file, --var overrides) win.
+func applyHTTPClientEnv(exp *expander, pf parser.ParsedFile, name string) error {
+	if pf.Format != "http" {
+		return nil
+	}
+	exp.httpFile = true
+	if name == "" {
+		return nil
+	}
+	vars, err := parser.FindHTTPClientEnv(filepath.Dir(pf.FilePath), name)
+	if err != nil {
+		return err
+	}
+	for k, v := range vars {
+		if _, ok := exp.vars[k]; !ok {
+			exp.set(k, v)
+		}
+	}
+	return nil
+}
+
 // expander replaces {{var}} tokens using the provided map or environment variables.
 type expander struct {
 	vars map[string]string
+	// httpFile enables .http semantics: {{$...}} dynamic variables and
+	// values that reference other variables. .bru cases expand one level.
+	httpFile bool
 }
 
 func newExpander(vars map[string]string) *expander {
@@ -77,6 +112,9 @@ func (e *expander) get(key string) (string, bool) {
 	if v, ok := e.vars[key]; ok {
 		return v, true
 	}
+	if e.httpFile && strings.HasPrefix(key, "$") {
+		return dynamicVar(key)
+	}
 	if v, ok := os.LookupEnv(key); ok {
 		return v, true
 	}
@@ -93,12 +131,72 @@ func (e *expander) set(key, val string) {
 	e.vars[key] = val
 }
 
+// maxExpandDepth bounds nested .http expansion ({{a}} -> "{{b}}/x" -> ...).
+const maxExpandDepth = 8
+
 func (e *expander) expand(s string) string {
+	return e.expandDepth(s, 0)
+}
+
+func (e *expander) expandDepth(s string, depth int) string {
 	return parser.VarPattern.ReplaceAllStringFunc(s, func(match string) string {
 		inner := strings.TrimSpace(match[2 : len(match)-2])
 		if v, ok := e.get(inner); ok {
+			if e.httpFile && depth < maxExpandDepth && strings.Contains(v, "{{") {
+				return e.expandDepth(v, depth+1)
+			}
 			return v
 		}
 		return match
 	})
 }
+
+// dynamicVar resolves the dynamic variables shared by Bruno, VS Code REST
+// Client and JetBrains HTTP Client: $guid/$uuid/$random.uuid, $timestamp,
+// $isoTimestamp, $randomInt [min max], $datetime iso8601|rfc1123 and
+// $processEnv NAME ($dotenv NAME falls back to the process environment).
+func dynamicVar(key string) (string, bool) {
+	fields := strings.Fields(key)
+	switch fields[0] {
+	case "$guid", "$uuid", "$random.uuid", "$randomUUID":
+		var b [16]byte
+		_, _ = rand.Read(b[:])
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), true
+	case "$isoTimestamp":
+		return time.Now().UTC().Format(time.RFC3339), true
+	case "$randomInt", "$random.integer":
+		lo, hi := int64(0), int64(1000)
+		if len(fields) == 3 {
+			a, errA := strconv.ParseInt(fields[1], 10, 64)
+			b, errB := strconv.ParseInt(fields[2], 10, 64)
+			if errA != nil || errB != nil || b <= a {
+				return "", false
+			}
+			lo, hi = a, b
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(hi-lo))
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(lo+n.Int64(), 10), true
+	case "$datetime":
+		now := time.Now().UTC()
+		if len(fields) == 2 && fields[1] == "rfc1123" {
+			return now.Format(http.TimeFormat), true
+		}
+		if len(fields) == 2 && fields[1] == "iso8601" {
+			return now.Format(time.RFC3339), true
+		}
+		return "", false
+	case "$processEnv", "$dotenv":
+		if len(fields) != 2 {
+			return "", false
+		}
+		return os.LookupEnv(strings.TrimPrefix(fields[1], "%"))
+	}
+	return "", false
+}
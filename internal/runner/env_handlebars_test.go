@@ -1,7 +1,11 @@
 package runner
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -22,3 +26,73 @@ func TestHandlebarProcessEnvResolution(t *testing.T) {
 		t.Fatalf("expected file var, got %q", v)
 	}
 }
+
+func TestExpanderBruSemanticsStayOneLevel(t *testing.T) {
+	vars := map[string]string{"tmpl": "{{inner}}", "inner": "x", "$price": "9"}
+
+	bru := newExpander(vars)
+	for in, want := range map[string]string{
+		"{{tmpl}}":       "{{inner}}",
+		"{{$price}}":     "9",
+		"{{$uuid}}":      "{{$uuid}}",
+		"{{$timestamp}}": "{{$timestamp}}",
+	} {
+		if got := bru.expand(in); got != want {
+			t.Fatalf(".bru expand(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	httpExp := newExpander(vars)
+	httpExp.httpFile = true
+	if got := httpExp.expand("{{tmpl}}"); got != "x" {
+		t.Fatalf(".http nested expand = %q, want x", got)
+	}
+	if got := httpExp.expand("{{$uuid}}"); got == "{{$uuid}}" || len(got) != 36 {
+		t.Fatalf(".http dynamic var not resolved: %q", got)
+	}
+}
+
+func TestRunBruKeepsTemplateLikeValuesLiteral(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"template":"` + r.Header.Get("X-Template") + `","dynamic":"` + r.Header.Get("X-Dynamic") + `"}`))
+	}))
+	defer srv.Close()
+
+	bruFile := `meta {
+  name: Literal
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/echo
+}
+
+headers {
+  X-Template: {{template}}
+  X-Dynamic: {{$uuid}}
+}
+
+tests {
+  test("values stay literal", function() {
+    expect(res.body.template).to.equal("{{inner}}");
+    expect(res.body.dynamic).to.equal("{{$uuid}}");
+  });
+}
+`
+	path := filepath.Join(t.TempDir(), "literal.bru")
+	if err := os.WriteFile(path, []byte(bruFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.RunFile(context.Background(), path, RunOptions{Vars: map[string]string{"baseUrl": srv.URL, "template": "{{inner}}", "inner": "expanded"}})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !res.Passed {
+		t.Fatalf("expected .bru values to stay literal, got %+v", res.Failures)
+	}
+}
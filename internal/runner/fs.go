@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// openFile opens name from fsys, or from disk when fsys is nil. Absolute
+// paths always come from disk, so a run inside an fs.FS can still use an
+// environment or data file that is not part of it.
+func openFile(fsys fs.FS, name string) (fs.File, error) {
+	if fsys == nil || filepath.IsAbs(name) {
+		return os.Open(name)
+	}
+	return fsys.Open(fsPath(name))
+}
+
+// readFile is os.ReadFile resolved like openFile.
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil || filepath.IsAbs(name) {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys, fsPath(name))
+}
+
+// fsPath turns an OS-style relative path into an fs.FS path.
+func fsPath(name string) string {
+	return path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "./"))
+}
+
+// openFormFile opens a multipart @file body. On disk the path is used as
+// written; inside fsys it is tried next to the .bru file, then from the
+// root of fsys.
+func openFormFile(fsys fs.FS, bruPath, name string) (fs.File, error) {
+	if fsys == nil || filepath.IsAbs(name) {
+		return os.Open(name)
+	}
+	f, err := fsys.Open(path.Join(path.Dir(bruPath), fsPath(name)))
+	if err == nil {
+		return f, nil
+	}
+	return fsys.Open(fsPath(name))
+}
@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// handlerTransport is an http.RoundTripper that serves every request from an
+// http.Handler in memory instead of a network connection. The http.Client
+// around it still follows redirects and keeps cookies in its Jar.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// The handler sees the request as an http.Server would.
+	sreq := req.Clone(ctx)
+	sreq.RequestURI = req.URL.RequestURI()
+	sreq.RemoteAddr = "192.0.2.1:1234"
+	sreq.Proto, sreq.ProtoMajor, sreq.ProtoMinor = "HTTP/1.1", 1, 1
+	if sreq.Host == "" {
+		sreq.Host = req.URL.Host
+	}
+	if req.URL.Scheme == "https" {
+		sreq.TLS = &tls.ConnectionState{Version: tls.VersionTLS13, HandshakeComplete: true, ServerName: req.URL.Hostname()}
+	}
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+
+	rec := httptest.NewRecorder()
+	done := make(chan any, 1)
+	go func() {
+		defer func() { done <- recover() }()
+		t.handler.ServeHTTP(rec, sreq)
+	}()
+	select {
+	case p := <-done:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		if p != nil {
+			return nil, fmt.Errorf("handler panic: %v", p)
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	resp := rec.Result()
+	resp.Request = req
+	if req.Method == http.MethodHead {
+		resp.Body = http.NoBody
+	}
+	return resp, nil
+}
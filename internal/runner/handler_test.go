@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithHandlerRunsInMemory(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		http.Redirect(w, r, "/me", http.StatusSeeOther)
+	})
+	mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil || r.TLS == nil || r.Host != "api.internal" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"session": "` + c.Value + `"}`))
+	})
+	mux.HandleFunc("GET /boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"1_login.bru": `meta {
+  name: Login
+  seq: 1
+}
+
+post {
+  url: https://api.internal/login
+}
+
+tests {
+  test("followed the redirect with the cookie", function() {
+    expect(res.status).to.equal(200);
+    expect(res.body.session).to.equal("s1");
+  });
+}
+`,
+		"2_me.bru": `meta {
+  name: Me
+  seq: 2
+}
+
+get {
+  url: https://api.internal/me
+}
+
+assert {
+  res.status: eq 200
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(context.Background(), WithHandler(mux))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFolder(context.Background(), dir, RunOptions{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Passed != 2 {
+		for _, c := range sum.Cases {
+			t.Logf("%s: status=%d err=%s failures=%v", c.Name, c.Status, c.ErrorText, c.Failures)
+		}
+		t.Fatalf("expected 2 passed, got %+v", sum)
+	}
+
+	boom := filepath.Join(dir, "boom.bru")
+	if err := os.WriteFile(boom, []byte("get {\n  url: http://api.internal/boom\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.RunFile(context.Background(), boom, RunOptions{})
+	if err != nil {
+		t.Fatalf("run boom: %v", err)
+	}
+	if res.Passed || res.ErrorText == "" {
+		t.Fatalf("expected the handler panic to fail the case: %+v", res)
+	}
+}
@@ -45,7 +45,7 @@ tests {
 		t.Fatalf("parsed URL empty")
 	}
 	exp := newExpander(map[string]string{"baseUrl": srv.URL})
-	if req, err := buildHTTPRequest(parsed, exp); err != nil || req.URL.String() == "" {
+	if req, err := buildHTTPRequest(parsed, exp, nil); err != nil || req.URL.String() == "" {
 		t.Fatalf("build req: %v url=%v", err, req)
 	}
 
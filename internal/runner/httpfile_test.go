@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestRunFolderHTTPFileWithEnvAndResponseRefs(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		seen  []string
+		posts []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.RequestURI()+" auth="+r.Header.Get("Authorization")+" session="+r.Header.Get("X-Session"))
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/login":
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			posts = append(posts, string(body))
+			mu.Unlock()
+			w.Header().Set("X-Session", "sess-9")
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"token": "tok-1"}})
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	env := `{"$shared": {"user": "ada"}, "dev": {"host": "` + srv.URL + `"}}`
+	if err := os.WriteFile(filepath.Join(dir, "http-client.env.json"), []byte(env), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `@base = {{host}}/v1
+
+### Log in
+# @name login
+POST {{base}}/login
+Content-Type: application/x-www-form-urlencoded
+
+user={{user}}
+&id={{$randomInt 5 6}}
+
+###
+GET {{base}}/items?page=2
+Authorization: Bearer {{login.response.body.$.data.token}}
+X-Session: {{login.response.headers.X-Session}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "api.http"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := New(context.Background(), WithLogger(pslog.NewWithOptions(io.Discard, pslog.Options{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFolder(context.Background(), filepath.Join(dir, "api.http"), RunOptions{HTTPClientEnv: "dev"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Total != 2 || sum.Passed != 2 {
+		t.Fatalf("summary: %+v", sum)
+	}
+	if sum.Cases[0].Name != "login" || sum.Cases[1].Name != "GET /items" {
+		t.Fatalf("case names: %s, %s", sum.Cases[0].Name, sum.Cases[1].Name)
+	}
+	want := []string{
+		"POST /v1/login auth= session=",
+		"GET /v1/items?page=2 auth=Bearer tok-1 session=sess-9",
+	}
+	if strings.Join(seen, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("requests:\n%s", strings.Join(seen, "\n"))
+	}
+	if len(posts) != 1 || posts[0] != "user=ada&id=5" {
+		t.Fatalf("form body: %q", posts)
+	}
+
+	// A multi-request .http file is not a single case.
+	if _, err := g.RunFile(context.Background(), filepath.Join(dir, "api.http"), RunOptions{HTTPClientEnv: "dev"}); err == nil {
+		t.Fatal("expected RunFile to reject a multi-request .http file")
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
-	"os"
 	"strings"
 )
 
@@ -24,10 +24,10 @@ func buildIterations(opts RunOptions) ([]iterationSpec, error) {
 	}
 
 	if opts.CSVFilePath != "" {
-		return readCSVIterations(opts.CSVFilePath)
+		return readCSVIterations(opts.FS, opts.CSVFilePath)
 	}
 	if opts.JSONFilePath != "" {
-		return readJSONIterations(opts.JSONFilePath)
+		return readJSONIterations(opts.FS, opts.JSONFilePath)
 	}
 
 	count := opts.IterationCount
@@ -41,8 +41,8 @@ func buildIterations(opts RunOptions) ([]iterationSpec, error) {
 	return its, nil
 }
 
-func readCSVIterations(path string) ([]iterationSpec, error) {
-	f, err := os.Open(path)
+func readCSVIterations(fsys fs.FS, path string) ([]iterationSpec, error) {
+	f, err := openFile(fsys, path)
 	if err != nil {
 		return nil, fmt.Errorf("csv-file-path: %w", err)
 	}
@@ -87,8 +87,8 @@ func readCSVIterations(path string) ([]iterationSpec, error) {
 	return out, nil
 }
 
-func readJSONIterations(path string) ([]iterationSpec, error) {
-	f, err := os.Open(path)
+func readJSONIterations(fsys fs.FS, path string) ([]iterationSpec, error) {
+	f, err := openFile(fsys, path)
 	if err != nil {
 		return nil, fmt.Errorf("json-file-path: %w", err)
 	}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +18,7 @@ import (
 	"pkt.systems/pslog"
 )
 
-func executeTests(ctx context.Context, p parsedFile, resp *http.Response, duration time.Duration, exp *expander, logger pslog.Base, prelude string, iter iterationInfo) (CaseResult, error) {
+func executeTests(ctx context.Context, p parsedFile, resp *http.Response, duration time.Duration, exp *expander, logger pslog.Base, prelude string, iter iterationInfo, pm *postmanShim) (CaseResult, error) {
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return CaseResult{}, err
@@ -24,7 +26,7 @@ func executeTests(ctx context.Context, p parsedFile, resp *http.Response, durati
 
 	vm := goja.New()
 	var consoleLogs []string
-	registerConsole(vm, &consoleLogs, logger)
+	registerConsole(ctx, vm, &consoleLogs, logger)
 
 	registerEnv(vm, exp)
 	registerProcessEnv(vm, exp)
@@ -32,6 +34,23 @@ func executeTests(ctx context.Context, p parsedFile, resp *http.Response, durati
 	resObj := newResponseObject(vm, resp, duration, bodyBytes)
 	vm.Set("res", resObj)
 	vm.Set("expect", expectFactory(vm))
+	tests := make([]jsTest, 0)
+	if pm != nil {
+		var header http.Header
+		method, rawURL := p.Request.Verb, p.Request.URL
+		if resp.Request != nil {
+			method, rawURL, header = resp.Request.Method, resp.Request.URL.String(), resp.Request.Header
+		}
+		pm.register(vm, postmanScope{
+			event:   "test",
+			exp:     exp,
+			iter:    iter,
+			request: newPostmanRequest(vm, method, rawURL, header, nil),
+			res:     newPostmanResponse(vm, resp.StatusCode, resp.Header, bodyBytes, duration),
+			tests:   &tests,
+			logs:    &consoleLogs,
+		})
+	}
 	runPrelude(vm, prelude)
 	// Normalize common fields so JS string helpers (match, etc.) are present.
 	_, _ = vm.RunString(`
@@ -47,12 +66,12 @@ func executeTests(ctx context.Context, p parsedFile, resp *http.Response, durati
 			res.body.message.match = function(re) { return String(str).match(re); };
 		}
 	`)
+	applyPostResponseVars(vm, p, exp)
 	if p.Scripts.PostResponse != "" {
 		runPostScript(vm, p.Scripts.PostResponse, resObj)
 	}
 	runScript(vm, p.Scripts.PreRequest)
 
-	tests := make([]jsTest, 0)
 	vm.Set("test", func(call goja.FunctionCall) goja.Value {
 		if len(call.Arguments) < 2 {
 			panic(vm.NewGoError(fmt.Errorf("test(name, fn) requires 2 args")))
@@ -62,7 +81,16 @@ func executeTests(ctx context.Context, p parsedFile, resp *http.Response, durati
 		if !ok {
 			panic(vm.NewGoError(fmt.Errorf("second arg must be function")))
 		}
-		tests = append(tests, jsTest{name: name, fn: fn})
+		line := 0
+		for _, f := range vm.CaptureCallStack(0, nil) {
+			if pos := f.Position(); f.SrcName() == "" && pos.Line > 0 {
+				if p.TestsLine > 0 {
+					line = p.TestsLine + pos.Line - 1
+				}
+				break
+			}
+		}
+		tests = append(tests, jsTest{name: name, fn: fn, line: line})
 		return goja.Undefined()
 	})
 
@@ -71,32 +99,49 @@ func executeTests(ctx context.Context, p parsedFile, resp *http.Response, durati
 	}
 
 	// run assert block first
-	result := CaseResult{Passed: true, Console: consoleLogs}
-	for _, ar := range p.Assert {
-		if err := evalAssert(vm, resObj, ar); err != nil {
+	result := CaseResult{Passed: true, Console: consoleLogs, ResponseBody: string(bodyBytes)}
+	for i, ar := range p.Assert {
+		tr := TestResult{Name: ar.Left + ": " + ar.Op + " " + ar.Right, Kind: TestKindAssert, Passed: true}
+		if i < len(p.AssertLines) {
+			tr.Line = p.AssertLines[i]
+		}
+		start := time.Now()
+		err := evalAssert(vm, resObj, ar)
+		tr.Duration = time.Since(start)
+		if err != nil {
 			result.Passed = false
+			tr.Passed, tr.Message = false, withHTTPContext(err.Error(), resp.StatusCode, bodyBytes)
 			result.Failures = append(result.Failures, AssertionFailure{
 				Name:    ar.Left,
-				Message: withHTTPContext(err.Error(), resp.StatusCode, bodyBytes),
+				Message: tr.Message,
 			})
 		}
+		result.Tests = append(result.Tests, tr)
 	}
 	for _, t := range tests {
+		tr := TestResult{Name: t.name, Kind: TestKindTest, Passed: true, Line: t.line}
+		start := time.Now()
 		_, err := t.fn(goja.Undefined())
+		tr.Duration = time.Since(start)
 		if err != nil {
 			result.Passed = false
+			tr.Passed, tr.Message = false, withHTTPContext(err.Error(), resp.StatusCode, bodyBytes)
 			result.Failures = append(result.Failures, AssertionFailure{
 				Name:    t.name,
-				Message: withHTTPContext(err.Error(), resp.StatusCode, bodyBytes),
+				Message: tr.Message,
 			})
 		}
+		result.Tests = append(result.Tests, tr)
 	}
+	// test bodies may log (or trigger pm warnings) after result was seeded.
+	result.Console = consoleLogs
 	return result, nil
 }
 
 type jsTest struct {
 	name string
 	fn   goja.Callable
+	line int // line in the case file, 0 when unknown
 }
 
 type iterationInfo struct {
@@ -106,7 +151,7 @@ type iterationInfo struct {
 	exp   *expander
 }
 
-func registerConsole(vm *goja.Runtime, logs *[]string, logger pslog.Base) {
+func registerConsole(ctx context.Context, vm *goja.Runtime, logs *[]string, logger pslog.Base) {
 	console := vm.NewObject()
 	logFn := func(call goja.FunctionCall) goja.Value {
 		parts := make([]string, len(call.Arguments))
@@ -118,6 +163,7 @@ func registerConsole(vm *goja.Runtime, logs *[]string, logger pslog.Base) {
 		if logger != nil {
 			logger.Debug("js", "msg", line)
 		}
+		emitCase(ctx, Event{Type: EventConsole, Console: line})
 		return goja.Undefined()
 	}
 	console.Set("log", logFn)
@@ -220,6 +266,17 @@ func registerBru(vm *goja.Runtime, exp *expander, iter iterationInfo) {
 		}
 		return goja.Undefined()
 	})
+	// environment variables share the expander with runtime vars, as in
+	// setEnvVar; iteration data is not consulted.
+	bru.Set("getEnvVar", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 || exp == nil {
+			return goja.Undefined()
+		}
+		if v, ok := exp.get(call.Arguments[0].String()); ok {
+			return vm.ToValue(v)
+		}
+		return goja.Undefined()
+	})
 
 	// runner metadata (iteration info)
 	total := iter.total
@@ -385,6 +442,52 @@ func runPostScript(vm *goja.Runtime, code string, resObj *goja.Object) {
 	_, _ = vm.RunString(code)
 }
 
+// applyPostResponseVars evaluates every vars:post-response value as a JS
+// expression, like Bruno: res, bru and the case's environment and runtime
+// variables (as identifiers) are in scope, and entries run in file order so
+// later ones see earlier results through bru.getVar. Results are stored in
+// exp, objects as JSON; values that fail to evaluate (bare words included)
+// or yield undefined/null leave the variable untouched.
+func applyPostResponseVars(vm *goja.Runtime, p parsedFile, exp *expander) {
+	for _, k := range postVarKeys(p) {
+		scope := vm.NewObject()
+		for name, v := range exp.vars {
+			_ = scope.Set(name, v)
+		}
+		for _, name := range []string{"res", "bru", "req"} {
+			_ = scope.Delete(name)
+		}
+		fn, err := vm.RunString("(function(__scope) { with (__scope) { return (\n" + p.VarsPost[k] + "\n); } })")
+		if err != nil {
+			continue
+		}
+		call, ok := goja.AssertFunction(fn)
+		if !ok {
+			continue
+		}
+		val, err := call(goja.Undefined(), scope)
+		if err != nil || val == nil || goja.IsUndefined(val) || goja.IsNull(val) {
+			continue
+		}
+		out := val.String()
+		if obj, ok := val.(*goja.Object); ok && obj.ClassName() != "String" {
+			if b, err := json.Marshal(obj.Export()); err == nil {
+				out = string(b)
+			}
+		}
+		exp.set(k, out)
+	}
+}
+
+// postVarKeys returns the vars:post-response keys in file order, sorted when
+// the order is unknown (cases built in Go).
+func postVarKeys(p parsedFile) []string {
+	if len(p.VarsPostOrder) == len(p.VarsPost) {
+		return p.VarsPostOrder
+	}
+	return slices.Sorted(maps.Keys(p.VarsPost))
+}
+
 // withHTTPContext appends status/body snippets to aid debugging when tests fail.
 func withHTTPContext(msg string, status int, body []byte) string {
 	const maxBody = 256
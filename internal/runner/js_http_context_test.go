@@ -26,7 +26,7 @@ func TestWithHTTPContextOnJSError(t *testing.T) {
 	}
 
 	vmExp := newExpander(nil)
-	res, err := executeTests(context.Background(), bru, resp, 0, vmExp, nil, "", iterationInfo{total: 1, data: map[string]any{}, exp: vmExp})
+	res, err := executeTests(context.Background(), bru, resp, 0, vmExp, nil, "", iterationInfo{total: 1, data: map[string]any{}, exp: vmExp}, nil)
 	if err != nil {
 		t.Fatalf("executeTests returned error: %v", err)
 	}
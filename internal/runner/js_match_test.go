@@ -29,7 +29,7 @@ func TestBodyMessageMatch(t *testing.T) {
 	g, _ := New(context.Background())
 	r := g.(*runner)
 	exp := newExpander(nil)
-	res, err := executeTests(context.Background(), p, resp, 0, exp, r.logger, "", iterationInfo{total: 1, data: map[string]any{}, exp: exp})
+	res, err := executeTests(context.Background(), p, resp, 0, exp, r.logger, "", iterationInfo{total: 1, data: map[string]any{}, exp: exp}, nil)
 	if err != nil {
 		t.Fatalf("executeTests error: %v", err)
 	}
@@ -61,7 +61,7 @@ func TestBodyMessageMatchRegexRegression(t *testing.T) {
 	g, _ := New(context.Background())
 	r := g.(*runner)
 	exp := newExpander(nil)
-	res, err := executeTests(context.Background(), p, resp, 0, exp, r.logger, "", iterationInfo{total: 1, data: map[string]any{}, exp: exp})
+	res, err := executeTests(context.Background(), p, resp, 0, exp, r.logger, "", iterationInfo{total: 1, data: map[string]any{}, exp: exp}, nil)
 	if err != nil {
 		t.Fatalf("executeTests error: %v", err)
 	}
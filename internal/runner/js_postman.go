@@ -0,0 +1,421 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"pkt.systems/pslog"
+)
+
+// postmanShim provides an opt-in `pm` global (RunOptions.PostmanCompat) that
+// maps the Postman sandbox API onto bru/res/expect. Members without a mapping
+// resolve to undefined and are reported once per case as a warning.
+type postmanShim struct {
+	ctx     context.Context
+	client  *http.Client
+	timeout time.Duration
+	logger  pslog.Base
+	name    string
+	warned  map[string]struct{}
+}
+
+func newPostmanShim(ctx context.Context, client *http.Client, timeout time.Duration, logger pslog.Base, name string) *postmanShim {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &postmanShim{ctx: ctx, client: client, timeout: timeout, logger: logger, name: name, warned: map[string]struct{}{}}
+}
+
+// postmanScope carries what one script invocation exposes through pm. res and
+// tests are nil for pre-request scripts.
+type postmanScope struct {
+	event   string // prerequest|test
+	exp     *expander
+	iter    iterationInfo
+	request *goja.Object
+	res     *goja.Object
+	tests   *[]jsTest
+	logs    *[]string
+}
+
+// register installs pm on vm. It relies on bru already being registered.
+func (s *postmanShim) register(vm *goja.Runtime, sc postmanScope) {
+	pm := vm.NewObject()
+	bru := vm.Get("bru").ToObject(vm)
+
+	pm.Set("test", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) < 2 {
+			panic(vm.NewGoError(fmt.Errorf("pm.test(name, fn) requires 2 args")))
+		}
+		if sc.tests == nil {
+			s.warn(sc, "test", "pm.test is ignored in pre-request scripts")
+			return goja.Undefined()
+		}
+		fn, ok := goja.AssertFunction(call.Arguments[1])
+		if !ok {
+			panic(vm.NewGoError(fmt.Errorf("second arg must be function")))
+		}
+		*sc.tests = append(*sc.tests, jsTest{name: call.Arguments[0].String(), fn: fn})
+		return goja.Undefined()
+	})
+	pm.Set("expect", expectFactory(vm))
+
+	pm.Set("environment", s.guard(vm, sc, "environment", s.variableScope(vm, sc, bru, "setEnvVar")))
+	pm.Set("collectionVariables", s.guard(vm, sc, "collectionVariables", s.variableScope(vm, sc, bru, "setVar")))
+	pm.Set("variables", s.guard(vm, sc, "variables", s.variableScope(vm, sc, bru, "setVar")))
+
+	iterData := bru.Get("runner").ToObject(vm).Get("iterationData").ToObject(vm)
+	iterData.Set("toObject", iterData.Get("getAll"))
+	pm.Set("iterationData", s.guard(vm, sc, "iterationData", iterData))
+
+	total := sc.iter.total
+	if total == 0 {
+		total = 1
+	}
+	info := vm.NewObject()
+	info.Set("eventName", sc.event)
+	info.Set("iteration", sc.iter.index)
+	info.Set("iterationCount", total)
+	info.Set("requestName", s.name)
+	pm.Set("info", s.guard(vm, sc, "info", info))
+
+	if sc.request != nil {
+		pm.Set("request", s.guard(vm, sc, "request", sc.request))
+	}
+	if sc.res != nil {
+		if to, ok := sc.res.Get("to").(*goja.Object); ok {
+			for _, name := range []string{"have", "be"} {
+				if sub, ok := to.Get(name).(*goja.Object); ok {
+					to.Set(name, s.guard(vm, sc, "response.to."+name, sub))
+				}
+			}
+			sc.res.Set("to", s.guard(vm, sc, "response.to", to))
+		}
+		pm.Set("response", s.guard(vm, sc, "response", sc.res))
+	}
+	pm.Set("sendRequest", func(call goja.FunctionCall) goja.Value {
+		return s.sendRequest(vm, sc, call)
+	})
+	vm.Set("pm", s.guard(vm, sc, "", pm))
+}
+
+// guard wraps obj so reads of missing members warn instead of failing silently.
+func (s *postmanShim) guard(vm *goja.Runtime, sc postmanScope, path string, obj *goja.Object) *goja.Object {
+	return vm.ToValue(vm.NewProxy(obj, &goja.ProxyTrapConfig{
+		Get: func(target *goja.Object, prop string, _ goja.Value) goja.Value {
+			if v := target.Get(prop); v != nil {
+				return v
+			}
+			if prop == "toJSON" {
+				return goja.Undefined()
+			}
+			member := prop
+			if path != "" {
+				member = path + "." + prop
+			}
+			s.warn(sc, member, "pm."+member+" is not supported by gru")
+			return goja.Undefined()
+		},
+	})).ToObject(vm)
+}
+
+func (s *postmanShim) warn(sc postmanScope, member, msg string) {
+	if _, done := s.warned[member]; done {
+		return
+	}
+	s.warned[member] = struct{}{}
+	if sc.logs != nil {
+		*sc.logs = append(*sc.logs, "warning: "+msg)
+	}
+	if s.logger != nil {
+		s.logger.Warn("js.pm.unsupported", "member", "pm."+member, "case", s.name)
+	}
+}
+
+// variableScope maps pm.environment/collectionVariables/variables onto the
+// run's variables; setter names the bru function used by set().
+func (s *postmanShim) variableScope(vm *goja.Runtime, sc postmanScope, bru *goja.Object, setter string) *goja.Object {
+	callBru := func(name string, args ...goja.Value) goja.Value {
+		fn, ok := goja.AssertFunction(bru.Get(name))
+		if !ok {
+			return goja.Undefined()
+		}
+		v, err := fn(bru, args...)
+		if err != nil {
+			panic(err)
+		}
+		return v
+	}
+	obj := vm.NewObject()
+	obj.Set("get", func(call goja.FunctionCall) goja.Value {
+		return callBru("getVar", call.Arguments...)
+	})
+	obj.Set("set", func(call goja.FunctionCall) goja.Value {
+		return callBru(setter, call.Arguments...)
+	})
+	obj.Set("has", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(!goja.IsUndefined(callBru("getVar", call.Arguments...)))
+	})
+	obj.Set("unset", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) > 0 && sc.exp != nil {
+			delete(sc.exp.vars, call.Arguments[0].String())
+		}
+		return goja.Undefined()
+	})
+	obj.Set("toObject", func(goja.FunctionCall) goja.Value {
+		out := map[string]string{}
+		if sc.exp != nil {
+			maps.Copy(out, sc.exp.vars)
+		}
+		return vm.ToValue(out)
+	})
+	obj.Set("replaceIn", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		if sc.exp == nil {
+			return call.Arguments[0]
+		}
+		return vm.ToValue(sc.exp.expand(call.Arguments[0].String()))
+	})
+	return obj
+}
+
+// newPostmanResponse builds a pm.response-style object.
+func newPostmanResponse(vm *goja.Runtime, status int, header http.Header, body []byte, duration time.Duration) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("code", status)
+	obj.Set("status", http.StatusText(status))
+	obj.Set("responseTime", duration.Milliseconds())
+	obj.Set("responseSize", len(body))
+	obj.Set("headers", newPostmanHeaders(vm, header, nil))
+	obj.Set("text", func(goja.FunctionCall) goja.Value {
+		return vm.ToValue(string(body))
+	})
+	obj.Set("json", func(goja.FunctionCall) goja.Value {
+		var target any
+		if err := json.Unmarshal(body, &target); err != nil {
+			panic(vm.NewGoError(err))
+		}
+		v, err := toJSValue(vm, target)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return v
+	})
+
+	fail := func(format string, args ...any) {
+		panic(vm.NewGoError(fmt.Errorf(format, args...)))
+	}
+	have := vm.NewObject()
+	have.Set("status", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		if want := call.Arguments[0].ToInteger(); int64(status) != want {
+			fail("expected response to have status code %d but got %d", want, status)
+		}
+		return goja.Undefined()
+	})
+	have.Set("header", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		name := call.Arguments[0].String()
+		if _, ok := header[http.CanonicalHeaderKey(name)]; !ok {
+			fail("expected response to have header %s", name)
+		}
+		return goja.Undefined()
+	})
+	be := vm.NewObject()
+	_ = be.DefineAccessorProperty("ok", vm.ToValue(func(goja.FunctionCall) goja.Value {
+		if status < 200 || status > 299 {
+			fail("expected response code to be 2XX but found %d", status)
+		}
+		return goja.Undefined()
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	to := vm.NewObject()
+	to.Set("have", have)
+	to.Set("be", be)
+	obj.Set("to", to)
+	return obj
+}
+
+// newPostmanHeaders exposes a header list with Postman's get/has/toObject
+// helpers. When set is nil the list is read-only.
+func newPostmanHeaders(vm *goja.Runtime, header http.Header, set func(name, value string, remove bool)) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("get", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		vals := header.Values(call.Arguments[0].String())
+		if len(vals) == 0 {
+			return goja.Undefined()
+		}
+		return vm.ToValue(vals[0])
+	})
+	obj.Set("has", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return vm.ToValue(false)
+		}
+		return vm.ToValue(len(header.Values(call.Arguments[0].String())) > 0)
+	})
+	obj.Set("toObject", func(goja.FunctionCall) goja.Value {
+		return vm.ToValue(headerMap(header))
+	})
+	if set == nil {
+		return obj
+	}
+	upsert := func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		var name, value string
+		if kv, ok := call.Arguments[0].Export().(map[string]any); ok {
+			name, value = fmt.Sprint(kv["key"]), fmt.Sprint(kv["value"])
+		} else {
+			name = call.Arguments[0].String()
+			if len(call.Arguments) > 1 {
+				value = call.Arguments[1].String()
+			}
+		}
+		set(name, value, false)
+		return goja.Undefined()
+	}
+	obj.Set("add", upsert)
+	obj.Set("upsert", upsert)
+	obj.Set("remove", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) > 0 {
+			set(call.Arguments[0].String(), "", true)
+		}
+		return goja.Undefined()
+	})
+	return obj
+}
+
+// newPostmanRequest builds pm.request. set is nil once the request was sent.
+func newPostmanRequest(vm *goja.Runtime, method, rawURL string, header http.Header, set func(name, value string, remove bool)) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("method", method)
+	obj.Set("url", rawURL)
+	obj.Set("headers", newPostmanHeaders(vm, header, set))
+	return obj
+}
+
+// sendRequest implements pm.sendRequest(req, callback) synchronously with the
+// runner's HTTP client. req is a URL string or {url, method, header, body}.
+func (s *postmanShim) sendRequest(vm *goja.Runtime, sc postmanScope, call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) == 0 {
+		panic(vm.NewGoError(fmt.Errorf("pm.sendRequest(request, callback) requires a request")))
+	}
+	var cb goja.Callable
+	if len(call.Arguments) > 1 {
+		cb, _ = goja.AssertFunction(call.Arguments[1])
+	}
+	res, err := s.doRequest(vm, sc, call.Arguments[0].Export())
+	if cb == nil {
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return res
+	}
+	errVal := goja.Null()
+	if err != nil {
+		errVal = vm.NewGoError(err)
+		res = goja.Null()
+	}
+	if _, cbErr := cb(goja.Undefined(), errVal, res); cbErr != nil {
+		panic(cbErr)
+	}
+	return goja.Undefined()
+}
+
+func (s *postmanShim) doRequest(vm *goja.Runtime, sc postmanScope, spec any) (goja.Value, error) {
+	method, rawURL := http.MethodGet, ""
+	header := http.Header{}
+	var body io.Reader
+	switch v := spec.(type) {
+	case string:
+		rawURL = v
+	case map[string]any:
+		switch u := v["url"].(type) {
+		case string:
+			rawURL = u
+		case map[string]any:
+			rawURL = fmt.Sprint(u["raw"])
+		}
+		if m, ok := v["method"].(string); ok && m != "" {
+			method = strings.ToUpper(m)
+		}
+		switch h := v["header"].(type) {
+		case map[string]any:
+			for k, val := range h {
+				header.Set(k, fmt.Sprint(val))
+			}
+		case []any:
+			for _, item := range h {
+				if kv, ok := item.(map[string]any); ok {
+					header.Add(fmt.Sprint(kv["key"]), fmt.Sprint(kv["value"]))
+				}
+			}
+		}
+		if b, ok := v["body"].(map[string]any); ok {
+			switch b["mode"] {
+			case "raw":
+				body = strings.NewReader(fmt.Sprint(b["raw"]))
+			case "urlencoded":
+				form := url.Values{}
+				if items, ok := b["urlencoded"].([]any); ok {
+					for _, item := range items {
+						if kv, ok := item.(map[string]any); ok {
+							form.Add(fmt.Sprint(kv["key"]), fmt.Sprint(kv["value"]))
+						}
+					}
+				}
+				body = strings.NewReader(form.Encode())
+				if header.Get("Content-Type") == "" {
+					header.Set("Content-Type", "application/x-www-form-urlencoded")
+				}
+			default:
+				s.warn(sc, "sendRequest.body."+fmt.Sprint(b["mode"]), fmt.Sprintf("pm.sendRequest body mode %v is not supported by gru", b["mode"]))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("pm.sendRequest: unsupported request %T", spec)
+	}
+	if sc.exp != nil {
+		rawURL = sc.exp.expand(rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("pm.sendRequest: %w", err)
+	}
+	req.Header = header
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return newPostmanResponse(vm, resp.StatusCode, resp.Header, respBody, time.Since(start)), nil
+}
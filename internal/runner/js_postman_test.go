@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPostmanCompatShim(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+		case "/items":
+			if r.Header.Get("Authorization") != "Bearer abc" || r.Header.Get("X-Trace") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":7,"name":"widget"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	bru := `meta {
+  name: Postman Style
+}
+
+post {
+  url: {{baseUrl}}/items
+}
+
+headers {
+  X-Trace: drop-me
+}
+
+script:pre-request {
+  pm.sendRequest(pm.variables.get("baseUrl") + "/token", function (err, res) {
+    pm.environment.set("token", res.json().token);
+  });
+  pm.request.headers.upsert({ key: "Authorization", value: "Bearer " + pm.environment.get("token") });
+  pm.request.headers.remove("X-Trace");
+}
+
+script:post-response {
+  pm.test("created in post-response", function () {
+    pm.response.to.have.status(201);
+    pm.response.to.be.ok;
+  });
+}
+
+tests {
+  pm.test("body and headers", function () {
+    const body = pm.response.json();
+    pm.expect(body.name).to.equal("widget");
+    pm.expect(pm.response.code).to.equal(201);
+    pm.expect(pm.response.headers.get("content-type")).to.contain("json");
+    pm.expect(pm.response.responseTime).to.be.at.least(0);
+    pm.expect(pm.request.method).to.equal("POST");
+    pm.expect(pm.iterationData.get("row")).to.equal("r1");
+    pm.expect(pm.info.requestName).to.equal("Postman Style");
+    pm.collectionVariables.set("itemId", body.id);
+    pm.expect(pm.variables.get("itemId")).to.equal("7");
+  });
+  pm.test("unsupported", function () {
+    pm.cookies.get("session");
+  });
+  pm.test("unsupported chain", function () {
+    pm.response.to.have.jsonBody("id");
+  });
+}
+`
+	path := filepath.Join(tmp, "pm.bru")
+	if err := os.WriteFile(path, []byte(bru), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := filepath.Join(tmp, "data.json")
+	if err := os.WriteFile(data, []byte(`[{"row":"r1"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.RunFile(context.Background(), path, RunOptions{
+		Vars:          map[string]string{"baseUrl": srv.URL},
+		JSONFilePath:  data,
+		PostmanCompat: true,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.ErrorText != "" || res.Status != http.StatusCreated {
+		t.Fatalf("unexpected result: status=%d err=%s", res.Status, res.ErrorText)
+	}
+	if len(res.Failures) != 2 || res.Failures[0].Name != "unsupported" || res.Failures[1].Name != "unsupported chain" {
+		t.Fatalf("expected only the unsupported tests to fail, got %+v", res.Failures)
+	}
+	for _, want := range []string{"pm.cookies is not supported", "pm.response.to.have.jsonBody is not supported"} {
+		if !strings.Contains(strings.Join(res.Console, "\n"), want) {
+			t.Fatalf("expected %q warning in console, got %v", want, res.Console)
+		}
+	}
+
+	// Without the opt-in pm stays undefined.
+	_, err = g.RunFile(context.Background(), path, RunOptions{Vars: map[string]string{"baseUrl": srv.URL}})
+	if err == nil || !strings.Contains(err.Error(), "pm is not defined") {
+		t.Fatalf("expected pm to be undefined without PostmanCompat, got %v", err)
+	}
+}
@@ -36,7 +36,7 @@ test("process env os", function(){
 
 	resp := &http.Response{StatusCode: 200, Body: ioNopCloser(bytes.NewBufferString("{}")), Header: http.Header{"Content-Type": []string{"application/json"}}}
 
-	res, err := executeTests(context.Background(), bru, resp, 0, vmExp, nil, "", iterationInfo{total: 1, data: map[string]any{}, exp: vmExp})
+	res, err := executeTests(context.Background(), bru, resp, 0, vmExp, nil, "", iterationInfo{total: 1, data: map[string]any{}, exp: vmExp}, nil)
 	if err != nil {
 		t.Fatalf("executeTests error: %v", err)
 	}
@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// EventType names the kind of an Event.
+type EventType string
+
+// Event types, in the order a run emits them.
+const (
+	EventRunStart       EventType = "run.start"
+	EventIterationStart EventType = "iteration.start"
+	EventCaseStart      EventType = "case.start"
+	EventHTTP           EventType = "http"
+	EventConsole        EventType = "console"
+	EventTest           EventType = "test"
+	EventCaseEnd        EventType = "case.end"
+	EventIterationEnd   EventType = "iteration.end"
+	EventRunEnd         EventType = "run.end"
+)
+
+// Event is one step of a run as it happens. Only the fields of its Type are
+// set; pointers are valid for the duration of the Observe call.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Iteration is the zero-based iteration, Iterations how many will run.
+	Iteration  int
+	Iterations int
+	// Total is the number of cases the run will execute (run.start).
+	Total int
+	// Case is the running case (case, http, console and test events).
+	Case *HookInfo
+	// Result is the outcome of the case (case.end).
+	Result *CaseResult
+	// Test is one assert rule or JS test() result (test).
+	Test *TestResult
+	// Console is a console.log line of a test script (console).
+	Console string
+	// Exchange is one HTTP round trip, each redirect included (http).
+	Exchange *Exchange
+	// Summary aggregates the results so far (run.end).
+	Summary *RunSummary
+	// Err is set when a case or the run stopped with an error (case.end,
+	// run.end).
+	Err error
+}
+
+// Exchange is an HTTP request with its response. The bodies are read into
+// RequestBody and ResponseBody; Request.Body and Response.Body are not
+// readable.
+type Exchange struct {
+	Request      *http.Request
+	RequestBody  []byte
+	Response     *http.Response // nil when the round trip failed
+	ResponseBody []byte
+	Err          error
+	Duration     time.Duration
+}
+
+// RunObserver receives the events of every run of a Gruno, see WithObserver.
+// Events are delivered synchronously from the goroutine running the case, so
+// a slow observer slows the run down. With RunOptions.Parallel the events of
+// concurrent cases interleave and Observe is called concurrently.
+type RunObserver interface {
+	Observe(ctx context.Context, ev Event)
+}
+
+// HTTPObserver is a RunObserver that also wants EventHTTP. Capturing an
+// exchange reads both bodies into memory, so the runner only does it, and
+// only delivers EventHTTP, to observers whose ObserveHTTP reports true.
+type HTTPObserver interface {
+	RunObserver
+	ObserveHTTP() bool
+}
+
+// ObserverFunc adapts a function to a RunObserver. It receives every event,
+// EventHTTP included.
+type ObserverFunc func(ctx context.Context, ev Event)
+
+// Observe calls f.
+func (f ObserverFunc) Observe(ctx context.Context, ev Event) { f(ctx, ev) }
+
+// ObserveHTTP reports true: f is sent HTTP exchanges.
+func (f ObserverFunc) ObserveHTTP() bool { return true }
+
+// observesHTTP reports whether o asked for EventHTTP.
+func observesHTTP(o RunObserver) bool {
+	h, ok := o.(HTTPObserver)
+	return ok && h.ObserveHTTP()
+}
+
+// emit delivers ev to every observer.
+func (r *runner) emit(ctx context.Context, ev Event) {
+	if len(r.observers) == 0 {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	for _, o := range r.observers {
+		if ev.Type == EventHTTP && !observesHTTP(o) {
+			continue
+		}
+		o.Observe(ctx, ev)
+	}
+}
+
+type caseScopeKey struct{}
+
+// caseScope ties the events raised while a case runs (console lines, HTTP
+// exchanges) to that case.
+type caseScope struct {
+	r          *runner
+	info       HookInfo
+	iteration  int
+	iterations int
+}
+
+// emitCase sends ev on behalf of the case running in ctx, if any.
+func emitCase(ctx context.Context, ev Event) {
+	sc, _ := ctx.Value(caseScopeKey{}).(*caseScope)
+	if sc == nil {
+		return
+	}
+	ev.Case = &sc.info
+	ev.Iteration = sc.iteration
+	ev.Iterations = sc.iterations
+	sc.r.emit(ctx, ev)
+}
+
+// observerTransport reports every round trip as an EventHTTP; it is only
+// installed when an HTTPObserver is registered.
+type observerTransport struct {
+	next http.RoundTripper
+}
+
+func (t observerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	ex := &Exchange{Request: req}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		ex.RequestBody = body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	if err == nil {
+		ex.ResponseBody, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(ex.ResponseBody))
+		if err != nil {
+			resp = nil
+		}
+	}
+	ex.Duration = time.Since(start)
+	ex.Response, ex.Err = resp, err
+	emitCase(req.Context(), Event{Type: EventHTTP, Exchange: ex})
+	return resp, err
+}
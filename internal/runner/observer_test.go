@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestObserverReceivesRunEvents(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(bytes.TrimSpace(body))
+	})
+	mux.HandleFunc("GET /missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"1_echo.bru": `meta {
+  name: Echo
+  seq: 1
+}
+
+post {
+  url: http://api.internal/echo
+  body: text
+}
+
+body:text {
+  hello
+}
+
+tests {
+  console.log("echoed", res.body);
+  test("echoes", function() {
+    expect(res.body).to.equal("hello");
+  });
+}
+`,
+		"2_missing.bru": `meta {
+  name: Missing
+  seq: 2
+}
+
+get {
+  url: http://api.internal/missing
+}
+
+assert {
+  res.status: eq 200
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	var events []Event
+	obs := ObserverFunc(func(_ context.Context, ev Event) {
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	})
+	g, err := New(t.Context(), WithLogger(pslog.New(io.Discard)), WithHandler(mux), WithObserver(obs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFolder(t.Context(), dir, RunOptions{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Passed != 1 || sum.Failed != 1 {
+		t.Fatalf("summary: %+v", sum)
+	}
+
+	var types []string
+	for _, ev := range events {
+		types = append(types, string(ev.Type))
+	}
+	want := "run.start iteration.start case.start http console test case.end case.start http test case.end iteration.end run.end"
+	if got := strings.Join(types, " "); got != want {
+		t.Fatalf("events:\n%s\nwant:\n%s", got, want)
+	}
+
+	if events[0].Total != 2 || events[0].Iterations != 1 {
+		t.Fatalf("run.start: %+v", events[0])
+	}
+	ex := events[3].Exchange
+	if events[3].Case.Name != "Echo" || strings.TrimSpace(string(ex.RequestBody)) != "hello" || string(ex.ResponseBody) != "hello" || ex.Response.StatusCode != 200 {
+		t.Fatalf("http event: %+v", events[3])
+	}
+	if events[4].Console != "echoed hello" {
+		t.Fatalf("console event: %q", events[4].Console)
+	}
+	if tr := events[5].Test; !tr.Passed || tr.Kind != TestKindTest || tr.Line != 17 {
+		t.Fatalf("test event: %+v", tr)
+	}
+	if tr := events[9].Test; tr.Passed || tr.Name != "res.status: eq 200" || tr.Kind != TestKindAssert || events[9].Case.Name != "Missing" {
+		t.Fatalf("test event: %+v", tr)
+	}
+	if res := events[10].Result; res.Passed || res.Status != 404 {
+		t.Fatalf("case.end: %+v", res)
+	}
+	if last := events[len(events)-1]; last.Summary.Failed != 1 || last.Err != nil {
+		t.Fatalf("run.end: %+v", last)
+	}
+}
+
+// caseObserver records event types and does not ask for HTTP exchanges.
+type caseObserver struct {
+	types []EventType
+}
+
+func (o *caseObserver) Observe(_ context.Context, ev Event) { o.types = append(o.types, ev.Type) }
+
+type httpCaseObserver struct {
+	caseObserver
+	http bool
+}
+
+func (o *httpCaseObserver) ObserveHTTP() bool { return o.http }
+
+func TestObserverHTTPEventsAreOptIn(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ping.bru"), []byte("meta {\n  name: Ping\n}\n\nget {\n  url: http://svc/ping\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("pong")) })
+
+	run := func(obs ...RunObserver) *runner {
+		opts := []Option{WithLogger(pslog.New(io.Discard)), WithHandler(handler)}
+		for _, o := range obs {
+			opts = append(opts, WithObserver(o))
+		}
+		g, err := New(t.Context(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.RunFolder(t.Context(), dir, RunOptions{}); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		return g.(*runner)
+	}
+
+	plain, declined := &caseObserver{}, &httpCaseObserver{}
+	if r := run(plain, declined); r.observeHTTP {
+		t.Fatal("exchanges captured although no observer asked for them")
+	}
+	wanting := &httpCaseObserver{http: true}
+	if r := run(plain, wanting); !r.observeHTTP {
+		t.Fatal("exchanges not captured for an HTTPObserver")
+	}
+	if slices.Contains(plain.types, EventHTTP) || slices.Contains(declined.types, EventHTTP) {
+		t.Fatalf("http event sent to an observer that did not ask: %v %v", plain.types, declined.types)
+	}
+	if !slices.Contains(wanting.types, EventHTTP) || !slices.Contains(plain.types, EventCaseEnd) {
+		t.Fatalf("events: %v %v", wanting.types, plain.types)
+	}
+}
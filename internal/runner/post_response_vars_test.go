@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPostResponseVarsEvaluateResponseExpressions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-42")
+		_, _ = w.Write([]byte(`{"token":"abc","user":{"id":7,"roles":["admin"]}}`))
+	}))
+	defer srv.Close()
+
+	bru := `meta {
+  name: Login
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/login
+}
+
+vars:post-response {
+  token: res.body.token
+  userId: res.body.user.id
+  user: res.body.user
+  requestId: res.headers["x-request-id"]
+  missing: res.body.nope.deeper
+  broken: res.body.(
+  bare: response token
+  literal: "response " + "token"
+  next: bru.getVar("userId") * 2
+  fallback: res.body.nope || "x"
+  url: baseUrl + "/users/" + res.body.user.id
+  roles: res.body.user.roles.length
+}
+`
+	check := `meta {
+  name: Check
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/check
+}
+
+tests {
+  test("post-response vars", function() {
+    expect(bru.getVar("token")).to.equal("abc");
+    expect(bru.getVar("userId")).to.equal("7");
+    expect(bru.getVar("user")).to.equal('{"id":7,"roles":["admin"]}');
+    expect(bru.getVar("requestId")).to.equal("req-42");
+    expect(bru.getVar("literal")).to.equal("response token");
+  });
+  test("any expression, in file order", function() {
+    expect(bru.getVar("next")).to.equal("14");
+    expect(bru.getVar("fallback")).to.equal("x");
+    expect(bru.getVar("url")).to.equal(bru.getVar("baseUrl") + "/users/7");
+    expect(bru.getVar("roles")).to.equal("1");
+  });
+  // Expressions that throw or fail to parse leave the variable untouched.
+  test("failed expressions", function() {
+    expect(bru.getVar("missing")).to.equal("kept");
+    expect(bru.getVar("broken")).to.equal("kept");
+    expect(bru.getVar("bare")).to.equal("kept");
+  });
+}
+`
+	tmp := t.TempDir()
+	for name, content := range map[string]string{"1-login.bru": bru, "2-check.bru": check} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	vars := map[string]string{"baseUrl": srv.URL, "missing": "kept", "broken": "kept", "bare": "kept"}
+	sum, err := g.RunFolder(context.Background(), tmp, RunOptions{Vars: vars})
+	if err != nil {
+		t.Fatalf("runfolder: %v", err)
+	}
+	if sum.Total != 2 || sum.Failed != 0 {
+		for _, c := range sum.Cases {
+			t.Logf("case %s passed=%v err=%s failures=%v", c.Name, c.Passed, c.ErrorText, c.Failures)
+		}
+		t.Fatalf("expected post-response vars to be evaluated, got %+v", sum)
+	}
+}
+
+func TestPostResponseVarsChainToLaterRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/login" {
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	files := map[string]string{
+		"1-login.bru": `meta {
+  name: Login
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/login
+}
+
+vars:post-response {
+  token: res.body.token
+}
+`,
+		"2-me.bru": `meta {
+  name: Me
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/me
+}
+
+headers {
+  Authorization: Bearer {{token}}
+}
+
+assert {
+  res.status: eq 200
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	sum, err := g.RunFolder(context.Background(), tmp, RunOptions{Vars: map[string]string{"baseUrl": srv.URL}})
+	if err != nil {
+		t.Fatalf("runfolder: %v", err)
+	}
+	if sum.Total != 2 || sum.Failed != 0 {
+		for _, c := range sum.Cases {
+			t.Logf("case %s passed=%v err=%s failures=%v", c.Name, c.Passed, c.ErrorText, c.Failures)
+		}
+		t.Fatalf("expected token to chain into the second request, got %+v", sum)
+	}
+}
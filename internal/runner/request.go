@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"pkt.systems/gruno/bru"
+	"pkt.systems/gruno/internal/parser"
+)
+
+// Request is a case composed in Go rather than read from a .bru file.
+// RunRequest runs it through the same pipeline as a file (vars, scripts,
+// asserts and tests), and Bru renders it as an equivalent .bru file.
+type Request struct {
+	Name string
+	Seq  float64
+	Tags []string
+	// Method is the HTTP verb; GET when empty.
+	Method     string
+	URL        string
+	Headers    map[string]string
+	Query      map[string]string
+	PathParams map[string]string
+	Body       RequestBody
+	// VarsPreRequest and VarsPostResponse are the vars:pre-request and
+	// vars:post-response blocks.
+	VarsPreRequest     map[string]string
+	VarsPostResponse   map[string]string
+	PreRequestScript   string
+	PostResponseScript string
+	// Tests is the JavaScript of the tests block.
+	Tests   string
+	Asserts []Assert
+	Docs    string
+}
+
+// RequestBody is the body of a Request.
+type RequestBody struct {
+	// Type is json, xml, text, sparql, graphql, form-urlencoded or
+	// multipart-form; no body is sent when empty.
+	Type string
+	// Raw is the body of text types (JSON, XML, GraphQL query, ...).
+	Raw string
+	// Form holds the fields of form-urlencoded and multipart-form bodies;
+	// multipart files are written as "@file(path)".
+	Form []FormField
+	// GraphQLVars is the JSON of body:graphql:vars.
+	GraphQLVars string
+}
+
+// FormField is one form body field.
+type FormField struct {
+	Name  string
+	Value string
+}
+
+// Assert is one assert block rule, e.g. {"res.status", "eq", "200"}.
+type Assert struct {
+	Expr  string
+	Op    string
+	Value string
+}
+
+// Bru returns the request as a .bru file in Bruno's canonical layout. The
+// .bru grammar only has blocks for GET, POST, PUT, PATCH, DELETE, HEAD and
+// OPTIONS; other methods are written as-is and WriteBru rejects them.
+func (req Request) Bru() *bru.File {
+	f := &bru.File{}
+	meta := f.AddBlock("meta")
+	meta.Set("name", req.Name)
+	meta.Set("type", "http")
+	meta.Set("seq", strconv.FormatFloat(req.Seq, 'f', -1, 64))
+	if len(req.Tags) > 0 {
+		meta.Set("tags", "["+strings.Join(req.Tags, ", ")+"]")
+	}
+
+	method := strings.ToLower(req.Method)
+	if method == "" {
+		method = "get"
+	}
+	verb := f.AddBlock(method)
+	verb.Set("url", req.URL)
+	if req.Body.Type != "" {
+		verb.Set("body", req.Body.Type)
+	}
+	verb.Set("auth", "none")
+
+	dict(f, "params:query", req.Query)
+	dict(f, "params:path", req.PathParams)
+	dict(f, "headers", req.Headers)
+	switch req.Body.Type {
+	case "":
+	case "form-urlencoded", "multipart-form":
+		b := f.AddBlock("body:" + req.Body.Type)
+		for _, field := range req.Body.Form {
+			b.Entries = append(b.Entries, &bru.Entry{Key: field.Name, Value: field.Value})
+		}
+	default:
+		text(f, "body:"+req.Body.Type, req.Body.Raw)
+		if req.Body.Type == "graphql" {
+			text(f, "body:graphql:vars", req.Body.GraphQLVars)
+		}
+	}
+	dict(f, "vars:pre-request", req.VarsPreRequest)
+	dict(f, "vars:post-response", req.VarsPostResponse)
+	if len(req.Asserts) > 0 {
+		b := f.AddBlock("assert")
+		for _, a := range req.Asserts {
+			b.Entries = append(b.Entries, &bru.Entry{Key: a.Expr, Value: a.Op + " " + a.Value})
+		}
+	}
+	text(f, "script:pre-request", req.PreRequestScript)
+	text(f, "script:post-response", req.PostResponseScript)
+	text(f, "tests", req.Tests)
+	text(f, "docs", req.Docs)
+	return f
+}
+
+// WriteBru writes the request as a .bru file to w. It fails for methods the
+// .bru grammar cannot express, such as PURGE, which RunRequest still runs.
+func (req Request) WriteBru(w io.Writer) error {
+	if req.Method != "" && !parser.IsVerb(req.Method) {
+		return fmt.Errorf("request %q: method %s has no .bru request block", req.Name, req.Method)
+	}
+	_, err := req.Bru().WriteTo(w)
+	return err
+}
+
+// dict adds a dictionary block with m's entries in key order, unless m is
+// empty.
+func dict(f *bru.File, name string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	b := f.AddBlock(name)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		b.Set(k, m[k])
+	}
+}
+
+// text adds a text block holding s, unless s is blank.
+func text(f *bru.File, name, s string) {
+	if strings.TrimSpace(s) != "" {
+		f.AddBlock(name).SetContent(s)
+	}
+}
+
+// RunRequest runs req like RunFile runs a .bru file: with opts' environment,
+// vars and iterations, returning the last iteration's result.
+func (r *runner) RunRequest(ctx context.Context, req Request, opts RunOptions) (CaseResult, error) {
+	parsed, err := req.parsedFile()
+	if err != nil {
+		return CaseResult{}, fmt.Errorf("request %q: %w", req.Name, err)
+	}
+	return r.runParsed(ctx, parsed, opts)
+}
+
+// parsedFile builds the case RunRequest runs directly from req, so values
+// reach the runner exactly as given rather than through .bru text.
+func (req Request) parsedFile() (parser.ParsedFile, error) {
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !validMethod(method) {
+		return parser.ParsedFile{}, fmt.Errorf("invalid method %q", req.Method)
+	}
+	pf := parser.ParsedFile{
+		Meta: parser.MetaBlock{Name: req.Name, Type: "http", Seq: req.Seq, Tags: slices.Clone(req.Tags)},
+		Request: parser.RequestBlock{
+			Verb:       method,
+			URL:        req.URL,
+			Headers:    maps.Clone(req.Headers),
+			Query:      maps.Clone(req.Query),
+			PathParams: maps.Clone(req.PathParams),
+		},
+		TestsRaw: req.Tests,
+		Docs:     req.Docs,
+		Scripts:  parser.ScriptBlock{PreRequest: req.PreRequestScript, PostResponse: req.PostResponseScript},
+		VarsPre:  maps.Clone(req.VarsPreRequest),
+		VarsPost: maps.Clone(req.VarsPostResponse),
+	}
+	if pf.Request.Headers == nil {
+		pf.Request.Headers = map[string]string{}
+	}
+	if req.Body.Type != "" {
+		pf.Request.Body = parser.BodyBlock{Type: req.Body.Type, Raw: req.Body.Raw, Present: true}
+		for _, field := range req.Body.Form {
+			pf.Request.Body.Form = append(pf.Request.Body.Form, parser.FormField{Name: field.Name, Value: field.Value})
+		}
+		if req.Body.Type == "graphql" && strings.TrimSpace(req.Body.GraphQLVars) != "" {
+			if err := json.Unmarshal([]byte(req.Body.GraphQLVars), &pf.Request.GraphqlVars); err != nil {
+				return parser.ParsedFile{}, fmt.Errorf("graphql vars: %w", err)
+			}
+		}
+	}
+	for _, a := range req.Asserts {
+		pf.Assert = append(pf.Assert, parser.AssertRule{Left: a.Expr, Op: a.Op, Right: a.Value})
+	}
+	return pf, nil
+}
+
+// validMethod reports whether m is a valid HTTP method token (RFC 9110), so
+// custom methods such as PURGE are accepted.
+func validMethod(m string) bool {
+	for _, c := range m {
+		if c > unicode.MaxASCII || !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return m != ""
+}
@@ -0,0 +1,255 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/gruno/bru"
+	"pkt.systems/gruno/internal/parser"
+)
+
+func TestRunRequestAndWriteBru(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":    r.URL.Query().Get("id"),
+			"trace": r.Header.Get("X-Trace"),
+			"name":  in["name"],
+		})
+	}))
+	defer srv.Close()
+
+	req := Request{
+		Name:             "Create user",
+		Seq:              3,
+		Tags:             []string{"smoke"},
+		Method:           "POST",
+		URL:              "{{baseUrl}}/users",
+		Query:            map[string]string{"id": "{{id}}"},
+		Headers:          map[string]string{"X-Trace": "pending"},
+		Body:             RequestBody{Type: "json", Raw: "{\n  \"name\": \"{{name}}\"\n}"},
+		VarsPreRequest:   map[string]string{"name": "ada"},
+		PreRequestScript: `req.setHeader("X-Trace", "t-" + bru.getVar("id"));`,
+		Asserts:          []Assert{{Expr: "res.status", Op: "eq", Value: "200"}},
+		Tests: `test("echoes the request", function() {
+  expect(res.body.name).to.equal("ada");
+  expect(res.body.trace).to.equal("t-42");
+});`,
+	}
+
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.RunRequest(context.Background(), req, RunOptions{Vars: map[string]string{"baseUrl": srv.URL, "id": "42"}})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !res.Passed || len(res.Tests) != 2 || res.Name != "Create user" || res.Started.IsZero() {
+		t.Fatalf("result: %+v", res)
+	}
+
+	var buf bytes.Buffer
+	if err := req.WriteBru(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `meta {
+  name: Create user
+  type: http
+  seq: 3
+  tags: [smoke]
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+params:query {
+  id: {{id}}
+}
+
+headers {
+  X-Trace: pending
+}
+
+body:json {
+  {
+    "name": "{{name}}"
+  }
+}
+
+vars:pre-request {
+  name: ada
+}
+
+assert {
+  res.status: eq 200
+}
+
+script:pre-request {
+  req.setHeader("X-Trace", "t-" + bru.getVar("id"));
+}
+
+tests {
+  test("echoes the request", function() {
+    expect(res.body.name).to.equal("ada");
+    expect(res.body.trace).to.equal("t-42");
+  });
+}
+`
+	if buf.String() != want {
+		t.Fatalf("bru:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if formatted, err := bru.FormatBytes(buf.Bytes(), bru.FormatOptions{}); err != nil || string(formatted) != want {
+		t.Fatalf("WriteBru output is not canonical (%v):\n%s", err, formatted)
+	}
+}
+
+func TestRunRequestKeepsValuesAndCustomMethods(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"method": r.Method, "body": string(body), "note": form.Get("note")})
+	}))
+	defer srv.Close()
+
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		req  Request
+		test string
+	}{
+		{
+			name: "multi-line var in text body",
+			req: Request{
+				Method:         "POST",
+				URL:            srv.URL,
+				Body:           RequestBody{Type: "text", Raw: "{{payload}}"},
+				VarsPreRequest: map[string]string{"payload": "a\nb"},
+			},
+			test: `expect(res.body.body).to.equal("a\nb");`,
+		},
+		{
+			name: "multi-line form field",
+			req: Request{
+				Method: "POST",
+				URL:    srv.URL,
+				Body:   RequestBody{Type: "form-urlencoded", Form: []FormField{{Name: "note", Value: "line 1\nline 2: more"}}},
+			},
+			test: `expect(res.body.note).to.equal("line 1\nline 2: more");`,
+		},
+		{
+			name: "custom method",
+			req:  Request{Method: "purge", URL: srv.URL},
+			test: `expect(res.body.method).to.equal("PURGE");`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Name = tc.name
+			tc.req.Tests = `test("server saw the request", function() { ` + tc.test + ` });`
+			res, err := g.RunRequest(context.Background(), tc.req, RunOptions{})
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if !res.Passed {
+				t.Fatalf("result: %+v", res.Failures)
+			}
+		})
+	}
+
+	_, err = g.RunRequest(context.Background(), Request{Name: "bad", Method: "NOT VALID", URL: srv.URL}, RunOptions{})
+	if err == nil || !strings.Contains(err.Error(), `invalid method "NOT VALID"`) {
+		t.Fatalf("expected invalid method error, got %v", err)
+	}
+}
+
+func TestRunRequestKeepsBodiesOnlyWhenReported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	req := Request{
+		Name:    "Echo",
+		Method:  "POST",
+		URL:     srv.URL,
+		Body:    RequestBody{Type: "text", Raw: "secret"},
+		Asserts: []Assert{{Expr: "res.body", Op: "eq", Value: "secret"}},
+	}
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.RunRequest(context.Background(), req, RunOptions{})
+	if err != nil || !res.Passed {
+		t.Fatalf("run: %+v %v", res, err)
+	}
+	if res.RequestBody != "" || res.ResponseBody != "" {
+		t.Fatalf("bodies kept without ReportBodies: %q %q", res.RequestBody, res.ResponseBody)
+	}
+	data, err := json.Marshal(res)
+	if err != nil || strings.Contains(string(data), "Body") {
+		t.Fatalf("json: %s %v", data, err)
+	}
+
+	res, err = g.RunRequest(context.Background(), req, RunOptions{ReportBodies: true})
+	if err != nil || !res.Passed {
+		t.Fatalf("run: %+v %v", res, err)
+	}
+	if res.RequestBody != "secret" || res.ResponseBody != "secret" {
+		t.Fatalf("bodies: %q %q", res.RequestBody, res.ResponseBody)
+	}
+}
+
+func TestWriteBruParsesBack(t *testing.T) {
+	req := Request{
+		Name:             "Submit",
+		Method:           "PATCH",
+		URL:              "{{baseUrl}}/forms",
+		Headers:          map[string]string{"X-Trace": "t"},
+		Body:             RequestBody{Type: "form-urlencoded", Form: []FormField{{Name: "a", Value: "1"}}},
+		VarsPostResponse: map[string]string{"id": "res.body.id"},
+		Asserts:          []Assert{{Expr: "res.status", Op: "eq", Value: "200"}},
+	}
+	path := filepath.Join(t.TempDir(), "submit.bru")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.WriteBru(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	pf, err := parser.ParseFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("parse written file: %v", err)
+	}
+	if pf.Request.Verb != "PATCH" || pf.Request.URL != req.URL || pf.Request.Headers["X-Trace"] != "t" ||
+		pf.VarsPost["id"] != "res.body.id" || len(pf.Assert) != 1 || pf.Meta.Name != "Submit" {
+		t.Fatalf("round trip: %+v", pf)
+	}
+
+	for _, m := range []string{"PURGE", "connect"} {
+		if err := (Request{Name: "x", Method: m, URL: "http://x"}).WriteBru(io.Discard); err == nil {
+			t.Fatalf("%s: expected WriteBru to fail", m)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRunFSResolvesFilesInsideFS(t *testing.T) {
+	var uploads []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("doc")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b, _ := io.ReadAll(f)
+		uploads = append(uploads, r.URL.Path+":"+string(b))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	fsys := fstest.MapFS{
+		"api/environments/local.bru": {Data: []byte("vars {\n  baseUrl: " + srv.URL + "\n}\n")},
+		"api/prelude.js":             {Data: []byte("function created() { return 201; }\n")},
+		"api/data.json":              {Data: []byte(`[{"id": "1"}, {"id": "2"}]`)},
+		"api/docs/upload.bru": {Data: []byte(`meta {
+  name: Upload
+  seq: 1
+  settings: { script: "../prelude.js" }
+}
+
+post {
+  url: {{baseUrl}}/docs/{{id}}
+  body: multipart-form
+}
+
+body:multipart-form {
+  doc: @file(payload.txt)
+}
+
+tests {
+  test("created", function() {
+    expect(res.status).to.equal(created());
+  });
+}
+`)},
+		"api/docs/payload.txt": {Data: []byte("hello")},
+		"other/skip.bru":       {Data: []byte("get {\n  url: http://127.0.0.1:0/\n}\n")},
+	}
+
+	g, err := New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.RunFS(context.Background(), fsys, "api", RunOptions{EnvPath: "api/environments/local.bru", JSONFilePath: "api/data.json"})
+	if err != nil {
+		t.Fatalf("runfs: %v", err)
+	}
+	if sum.Total != 2 || sum.Passed != 2 {
+		t.Fatalf("summary: %+v", sum)
+	}
+	if sum.Cases[0].FilePath != "api/docs/upload.bru" {
+		t.Fatalf("file path: %q", sum.Cases[0].FilePath)
+	}
+	if len(uploads) != 2 || uploads[0] != "/docs/1:hello" || uploads[1] != "/docs/2:hello" {
+		t.Fatalf("uploads: %v", uploads)
+	}
+}
@@ -2,22 +2,25 @@ package runner
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
 	"net/http"
+	"net/http/cookiejar"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/dop251/goja"
+	"pkt.systems/gruno/internal/har"
 	"pkt.systems/gruno/internal/parser"
 	"pkt.systems/pslog"
 )
@@ -31,6 +34,12 @@ type runner struct {
 	timeout    time.Duration
 	preHook    PreRequestHook
 	postHook   PostRequestHook
+	har        *har.Recorder
+	transports []func(http.RoundTripper) http.RoundTripper
+	handler    http.Handler
+	observers  []RunObserver
+	// observeHTTP is set when an observer wants EventHTTP.
+	observeHTTP bool
 }
 
 type runnerConfig struct {
@@ -39,6 +48,10 @@ type runnerConfig struct {
 	timeout    time.Duration
 	preHook    PreRequestHook
 	postHook   PostRequestHook
+	har        *har.Recorder
+	transports []func(http.RoundTripper) http.RoundTripper
+	handler    http.Handler
+	observers  []RunObserver
 }
 
 // New constructs a Gruno instance with optional configuration.
@@ -57,77 +70,55 @@ func New(ctx context.Context, opts ...Option) (Gruno, error) {
 	}
 	if cfg.httpClient == nil {
 		cfg.httpClient = &http.Client{Timeout: defaultTimeout}
+		if cfg.handler != nil {
+			// Keep cookies between requests like a browser session would.
+			cfg.httpClient.Jar, _ = cookiejar.New(nil)
+		}
 	}
 	if cfg.timeout == 0 {
 		cfg.timeout = defaultTimeout
 	}
 	r := &runner{
-		logger:     cfg.logger,
-		httpClient: cfg.httpClient,
-		timeout:    cfg.timeout,
-		preHook:    cfg.preHook,
-		postHook:   cfg.postHook,
+		logger:      cfg.logger,
+		httpClient:  cfg.httpClient,
+		timeout:     cfg.timeout,
+		preHook:     cfg.preHook,
+		postHook:    cfg.postHook,
+		har:         cfg.har,
+		transports:  cfg.transports,
+		handler:     cfg.handler,
+		observers:   cfg.observers,
+		observeHTTP: slices.ContainsFunc(cfg.observers, observesHTTP),
 	}
 	return r, nil
 }
 
-// RunFile executes a single .bru file.
+// RunFile executes a single .bru file (or a .http file holding one request).
 func (r *runner) RunFile(ctx context.Context, path string, opts RunOptions) (CaseResult, error) {
 	return r.runSingle(ctx, path, opts)
 }
 
-// RunFolder discovers, sorts, and executes all .bru files in the folder.
-func (r *runner) RunFolder(ctx context.Context, path string, opts RunOptions) (RunSummary, error) {
+// RunFolder discovers, sorts, and executes all .bru and .http cases in the
+// folder. A .http file path runs every request in that file.
+func (r *runner) RunFolder(ctx context.Context, path string, opts RunOptions) (sum RunSummary, err error) {
 	start := time.Now()
 
-	recursive := true
-	if opts.RecursiveSet {
-		recursive = opts.Recursive
-	}
-
-	files, err := parser.DiscoverBruFiles(path, recursive)
-	if err != nil {
-		return RunSummary{}, err
-	}
-	sort.Slice(files, func(i, j int) bool {
-		if files[i].Meta.Seq == files[j].Meta.Seq {
-			return files[i].FilePath < files[j].FilePath
-		}
-		return files[i].Meta.Seq < files[j].Meta.Seq
-	})
-
-	envVars, err := loadEnv(ctx, opts.EnvPath)
-	if err != nil {
-		return RunSummary{}, fmt.Errorf("load env: %w", err)
-	}
-	if len(opts.Vars) > 0 {
-		if envVars == nil {
-			envVars = map[string]string{}
-		}
-		maps.Copy(envVars, opts.Vars)
-	}
-
-	iterations, err := buildIterations(opts)
+	runnable, envVars, iterations, err := prepareFolder(ctx, path, opts)
 	if err != nil {
 		return RunSummary{}, err
 	}
-	if len(iterations) == 0 {
-		iterations = []iterationSpec{{vars: map[string]string{}, data: map[string]any{}}}
-	}
-
-	// Filter upfront by tag include/exclude to match bru behaviour (requests count only executed)
-	var runnable []parser.ParsedFile
-	for _, f := range files {
-		if passesTagFilter(f.Meta.Tags, opts.Tags, opts.ExcludeTags) {
-			runnable = append(runnable, f)
-		}
-	}
 
 	totalIterations := len(iterations)
 	summary := RunSummary{Total: len(runnable) * totalIterations}
 	caseCount := 0
 
+	r.emit(ctx, Event{Type: EventRunStart, Iterations: totalIterations, Total: summary.Total})
+	defer func() {
+		r.emit(ctx, Event{Type: EventRunEnd, Iterations: totalIterations, Total: summary.Total, Summary: &sum, Err: err})
+	}()
+
 	for iterIdx, iter := range iterations {
+		r.emit(ctx, Event{Type: EventIterationStart, Iteration: iterIdx, Iterations: totalIterations})
 		// start with env/vars fresh for each iteration so post-response vars do not leak.
 		iterVars := cloneStringMap(envVars)
 		maps.Copy(iterVars, iter.vars)
@@ -177,6 +168,7 @@ func (r *runner) RunFolder(ctx context.Context, path string, opts RunOptions) (R
 				cancel()
 				return RunSummary{}, iterErr
 			}
+			r.emit(ctx, Event{Type: EventIterationEnd, Iteration: iterIdx, Iterations: totalIterations})
 			if bailTriggered {
 				summary.TotalElapsed = time.Since(start)
 				cancel()
@@ -217,20 +209,101 @@ func (r *runner) RunFolder(ctx context.Context, path string, opts RunOptions) (R
 			caseCount++
 			if opts.Bail && !res.Passed && !res.Skipped {
 				summary.TotalElapsed = time.Since(start)
+				r.emit(ctx, Event{Type: EventIterationEnd, Iteration: iterIdx, Iterations: totalIterations})
 				return summary, nil
 			}
 		}
+		r.emit(ctx, Event{Type: EventIterationEnd, Iteration: iterIdx, Iterations: totalIterations})
 	}
 	summary.TotalElapsed = time.Since(start)
 	return summary, nil
 }
 
+// RunFS runs the .bru collection at root ("." for all of fsys) inside fsys
+// like RunFolder. Case paths, opts.EnvPath, data files, prelude scripts and
+// @file bodies are resolved in fsys.
+func (r *runner) RunFS(ctx context.Context, fsys fs.FS, root string, opts RunOptions) (RunSummary, error) {
+	if fsys == nil {
+		return RunSummary{}, errors.New("nil fs.FS")
+	}
+	opts.FS = fsys
+	return r.RunFolder(ctx, root, opts)
+}
+
+// prepareFolder loads what a folder run needs: the cases passing the tag
+// filters in run order, the env file overlaid with opts.Vars, and the
+// iterations (at least one).
+func prepareFolder(ctx context.Context, path string, opts RunOptions) ([]parser.ParsedFile, map[string]string, []iterationSpec, error) {
+	files, err := discoverCases(path, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	envVars, err := loadEnv(ctx, opts.FS, opts.EnvPath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("load env: %w", err)
+	}
+	if len(opts.Vars) > 0 {
+		if envVars == nil {
+			envVars = map[string]string{}
+		}
+		maps.Copy(envVars, opts.Vars)
+	}
+
+	iterations, err := buildIterations(opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(iterations) == 0 {
+		iterations = []iterationSpec{{vars: map[string]string{}, data: map[string]any{}}}
+	}
+
+	// Filter upfront by tag include/exclude to match bru behaviour (requests count only executed)
+	var runnable []parser.ParsedFile
+	for _, f := range files {
+		if passesTagFilter(f.Meta.Tags, opts.Tags, opts.ExcludeTags) {
+			runnable = append(runnable, f)
+		}
+	}
+	return runnable, envVars, iterations, nil
+}
+
+// discoverCases returns the cases of a folder run in run order, walking
+// opts.FS when it is set.
+func discoverCases(path string, opts RunOptions) ([]parser.ParsedFile, error) {
+	dopts := parser.DiscoverOptions{Recursive: true}
+	if opts.RecursiveSet {
+		dopts.Recursive = opts.Recursive
+	}
+	var files []parser.ParsedFile
+	var err error
+	if opts.FS != nil {
+		files, _, err = parser.DiscoverCasesFS(opts.FS, fsPath(path), dopts)
+	} else {
+		files, _, err = parser.DiscoverCasesWith(path, dopts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sortBySeq(files)
+	return files, nil
+}
+
 func (r *runner) runSingle(ctx context.Context, path string, opts RunOptions) (CaseResult, error) {
-	parsed, err := parser.ParseFile(ctx, path)
+	cases, err := parser.ParseCases(ctx, path)
 	if err != nil {
 		return CaseResult{}, err
 	}
-	envVars, err := loadEnv(ctx, opts.EnvPath)
+	if len(cases) != 1 {
+		return CaseResult{}, fmt.Errorf("%s holds %d requests; run it with RunFolder", path, len(cases))
+	}
+	return r.runParsed(ctx, cases[0], opts)
+}
+
+// runParsed runs one case for every iteration of opts and returns the last
+// result.
+func (r *runner) runParsed(ctx context.Context, parsed parser.ParsedFile, opts RunOptions) (last CaseResult, err error) {
+	envVars, err := loadEnv(ctx, nil, opts.EnvPath)
 	if err != nil {
 		return CaseResult{}, fmt.Errorf("load env: %w", err)
 	}
@@ -249,8 +322,21 @@ func (r *runner) runSingle(ctx context.Context, path string, opts RunOptions) (C
 		iterations = []iterationSpec{{vars: map[string]string{}, data: map[string]any{}}}
 	}
 
-	var last CaseResult
+	start := time.Now()
+	var summary RunSummary
+	if passesTagFilter(parsed.Meta.Tags, opts.Tags, opts.ExcludeTags) {
+		summary.Total = len(iterations)
+	}
+	r.emit(ctx, Event{Type: EventRunStart, Iterations: len(iterations), Total: summary.Total})
+	defer func() {
+		if err != nil {
+			summary = RunSummary{}
+		}
+		r.emit(ctx, Event{Type: EventRunEnd, Iterations: len(iterations), Total: summary.Total, Summary: &summary, Err: err})
+	}()
+
 	for iterIdx, iter := range iterations {
+		r.emit(ctx, Event{Type: EventIterationStart, Iteration: iterIdx, Iterations: len(iterations)})
 		iterVars := cloneStringMap(envVars)
 		maps.Copy(iterVars, iter.vars)
 		caseOpts := RunOptions{
@@ -264,6 +350,9 @@ func (r *runner) runSingle(ctx context.Context, path string, opts RunOptions) (C
 			Delay:           opts.Delay,
 			PreHookCmd:      opts.PreHookCmd,
 			PostHookCmd:     opts.PostHookCmd,
+			PostmanCompat:   opts.PostmanCompat,
+			ReportBodies:    opts.ReportBodies,
+			HTTPClientEnv:   opts.HTTPClientEnv,
 			IterationIndex:  iterIdx,
 			TotalIterations: len(iterations),
 			IterationData:   iter.data,
@@ -273,6 +362,16 @@ func (r *runner) runSingle(ctx context.Context, path string, opts RunOptions) (C
 			return CaseResult{}, err
 		}
 		last = res
+		summary.Cases = append(summary.Cases, res)
+		if res.Skipped {
+			summary.Skipped++
+		} else if res.Passed {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+		summary.TotalElapsed = time.Since(start)
+		r.emit(ctx, Event{Type: EventIterationEnd, Iteration: iterIdx, Iterations: len(iterations)})
 		if !res.Passed && !res.Skipped && opts.Bail {
 			return res, nil
 		}
@@ -284,7 +383,21 @@ func (r *runner) runSingle(ctx context.Context, path string, opts RunOptions) (C
 	return last, nil
 }
 
+// executeParsed runs one case, raising its case, test, console and HTTP
+// events.
 func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, opts RunOptions) (CaseResult, error) {
+	if len(r.observers) == 0 {
+		return r.executeCase(ctx, parsed, opts)
+	}
+	sc := &caseScope{r: r, info: hookInfoFromParsed(parsed, nil), iteration: opts.IterationIndex, iterations: opts.TotalIterations}
+	ctx = context.WithValue(ctx, caseScopeKey{}, sc)
+	emitCase(ctx, Event{Type: EventCaseStart})
+	res, err := r.executeCase(ctx, parsed, opts)
+	emitCase(ctx, Event{Type: EventCaseEnd, Result: &res, Err: err})
+	return res, err
+}
+
+func (r *runner) executeCase(ctx context.Context, parsed parser.ParsedFile, opts RunOptions) (CaseResult, error) {
 	logger := r.logger
 	if opts.Logger != nil {
 		logger = opts.Logger
@@ -293,6 +406,23 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 	if opts.HTTPClient != nil {
 		client = opts.HTTPClient
 	}
+	if len(r.transports) > 0 || r.handler != nil || r.observeHTTP {
+		wrapped := *client
+		if r.handler != nil {
+			wrapped.Transport = handlerTransport{handler: r.handler}
+		}
+		for _, wrap := range r.transports {
+			wrapped.Transport = wrap(wrapped.Transport)
+		}
+		if r.observeHTTP {
+			wrapped.Transport = observerTransport{next: wrapped.Transport}
+		}
+		client = &wrapped
+	}
+	if r.har != nil {
+		client = r.har.Client(client)
+		ctx = har.WithComment(ctx, parsed.FilePath)
+	}
 	timeout := r.timeout
 	if opts.Timeout > 0 {
 		timeout = opts.Timeout
@@ -302,17 +432,20 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 	}
 
 	if !passesTagFilter(parsed.Meta.Tags, opts.Tags, opts.ExcludeTags) {
-		return CaseResult{FilePath: parsed.FilePath, Name: parsed.Meta.Name, Seq: parsed.Meta.Seq, Tags: parsed.Meta.Tags, Passed: true, Skipped: true}, nil
+		return CaseResult{FilePath: parsed.FilePath, Name: parsed.Meta.Name, Seq: parsed.Meta.Seq, Tags: parsed.Meta.Tags, Iteration: opts.IterationIndex, Passed: true, Skipped: true}, nil
 	}
 
 	if parsed.Meta.Skip {
-		return CaseResult{FilePath: parsed.FilePath, Name: parsed.Meta.Name, Seq: parsed.Meta.Seq, Tags: parsed.Meta.Tags, Passed: true, Skipped: true}, nil
+		return CaseResult{FilePath: parsed.FilePath, Name: parsed.Meta.Name, Seq: parsed.Meta.Seq, Tags: parsed.Meta.Tags, Iteration: opts.IterationIndex, Passed: true, Skipped: true}, nil
 	}
 	if opts.TestsOnly && parsed.TestsRaw == "" && len(parsed.Assert) == 0 {
-		return CaseResult{FilePath: parsed.FilePath, Name: parsed.Meta.Name, Seq: parsed.Meta.Seq, Tags: parsed.Meta.Tags, Passed: true, Skipped: true}, nil
+		return CaseResult{FilePath: parsed.FilePath, Name: parsed.Meta.Name, Seq: parsed.Meta.Seq, Tags: parsed.Meta.Tags, Iteration: opts.IterationIndex, Passed: true, Skipped: true}, nil
 	}
 
 	expander := newExpander(opts.Vars)
+	if err := applyHTTPClientEnv(expander, parsed, opts.HTTPClientEnv); err != nil {
+		return CaseResult{}, err
+	}
 	iterInfo := iterationInfo{
 		index: opts.IterationIndex,
 		total: opts.TotalIterations,
@@ -333,13 +466,18 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 		} else if !filepath.IsAbs(scriptPath) {
 			scriptPath = filepath.Join(filepath.Dir(parsed.FilePath), scriptPath)
 		}
-		if b, err := os.ReadFile(scriptPath); err == nil {
+		if b, err := readFile(opts.FS, scriptPath); err == nil {
 			prelude = string(b)
 		} else {
 			return CaseResult{}, fmt.Errorf("load prelude %s: %w", scriptPath, err)
 		}
 	}
 
+	var pm *postmanShim
+	if opts.PostmanCompat {
+		pm = newPostmanShim(ctx, client, timeout, logger, parsed.Meta.Name)
+	}
+
 	repeat := parsed.Meta.Repeat
 	if repeat <= 0 {
 		repeat = 1
@@ -355,7 +493,7 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 			}
 		}
 
-		req, err := buildHTTPRequest(parsed, expander)
+		req, err := buildHTTPRequest(parsed, expander, opts.FS)
 		if err != nil {
 			return CaseResult{}, err
 		}
@@ -375,12 +513,18 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 
 		// run JS pre-request script to allow header/query/body tweaks
 		if parsed.Scripts.PreRequest != "" {
-			if err := runPreRequestScript(parsed.Scripts.PreRequest, req, expander, iterInfo); err != nil {
+			if err := runPreRequestScript(parsed.Scripts.PreRequest, req, expander, iterInfo, pm); err != nil {
 				return CaseResult{}, fmt.Errorf("pre script: %w", err)
 			}
 		}
 
 		reqHeaders := headerMap(req.Header)
+		var reqBody []byte
+		if opts.ReportBodies {
+			if reqBody, err = snapshotBody(req); err != nil {
+				return CaseResult{}, fmt.Errorf("read request body: %w", err)
+			}
+		}
 
 		if timeout <= 0 {
 			timeout = defaultTimeout
@@ -394,26 +538,40 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 		if err != nil {
 			// Surface connection/refused/etc as a case-level failure instead of aborting the run.
 			return CaseResult{
-				FilePath:   parsed.FilePath,
-				Name:       parsed.Meta.Name,
-				RequestURL: req.URL.String(),
-				Seq:        parsed.Meta.Seq,
-				Tags:       parsed.Meta.Tags,
-				Duration:   duration,
-				Passed:     false,
-				ErrorText:  fmt.Sprintf("http request failed: %v", err),
+				FilePath:       parsed.FilePath,
+				Name:           parsed.Meta.Name,
+				Method:         req.Method,
+				RequestURL:     req.URL.String(),
+				RequestHeaders: reqHeaders,
+				RequestBody:    string(reqBody),
+				Seq:            parsed.Meta.Seq,
+				Tags:           parsed.Meta.Tags,
+				Iteration:      opts.IterationIndex,
+				Started:        start,
+				Duration:       duration,
+				Passed:         false,
+				ErrorText:      fmt.Sprintf("http request failed: %v", err),
 			}, nil
 		}
 		defer resp.Body.Close()
 
 		// post-response script and assertions
-		result, err = executeTests(ctx, parsed, resp, duration, expander, logger, prelude, iterInfo)
+		result, err = executeTests(ctx, parsed, resp, duration, expander, logger, prelude, iterInfo, pm)
 		if err != nil {
 			result.Passed = false
 			result.ErrorText = err.Error()
 		}
+		for i := range result.Tests {
+			emitCase(ctx, Event{Type: EventTest, Test: &result.Tests[i]})
+		}
+		if !opts.ReportBodies {
+			result.ResponseBody = ""
+		}
 		result.Status = resp.StatusCode
+		result.Started = start
+		result.Method = req.Method
 		result.RequestHeaders = reqHeaders
+		result.RequestBody = string(reqBody)
 		result.ResponseHeaders = headerMap(resp.Header)
 
 		// vars:post-response merge back
@@ -421,9 +579,11 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 			if opts.Vars == nil {
 				opts.Vars = map[string]string{}
 			}
-			for k, v := range parsed.VarsPost {
-				expander.vars[k] = v
-				opts.Vars[k] = v
+			// executeTests already stored the evaluated values.
+			for k := range parsed.VarsPost {
+				if v, ok := expander.vars[k]; ok {
+					opts.Vars[k] = v
+				}
 			}
 		}
 
@@ -432,6 +592,7 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 		result.RequestURL = req.URL.String()
 		result.Seq = parsed.Meta.Seq
 		result.Tags = parsed.Meta.Tags
+		result.Iteration = opts.IterationIndex
 		result.Duration = duration
 
 		if r.postHook != nil {
@@ -453,6 +614,22 @@ func (r *runner) executeParsed(ctx context.Context, parsed parser.ParsedFile, op
 	return result, nil
 }
 
+// snapshotBody reads the body of req for the case result and puts it back,
+// so it can still be sent (and resent on redirects).
+func snapshotBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	return b, nil
+}
+
 func passesTagFilter(tags []string, include []string, exclude []string) bool {
 	if len(include) > 0 {
 		match := false
@@ -565,7 +742,7 @@ func hookEnv(phase string, file parser.ParsedFile, res *CaseResult) []string {
 }
 
 // runPreRequestScript executes Bruno-style pre-request JS that can mutate headers/query/body.
-func runPreRequestScript(code string, req *http.Request, exp *expander, iter iterationInfo) error {
+func runPreRequestScript(code string, req *http.Request, exp *expander, iter iterationInfo, pm *postmanShim) error {
 	if strings.TrimSpace(code) == "" {
 		return nil
 	}
@@ -597,6 +774,22 @@ func runPreRequestScript(code string, req *http.Request, exp *expander, iter ite
 	registerEnv(vm, exp)
 	registerProcessEnv(vm, exp)
 	registerBru(vm, exp, iter)
+	if pm != nil {
+		pm.register(vm, postmanScope{
+			event: "prerequest",
+			exp:   exp,
+			iter:  iter,
+			request: newPostmanRequest(vm, req.Method, req.URL.String(), req.Header, func(name, value string, remove bool) {
+				if remove {
+					req.Header.Del(name)
+					_ = hdrObj.Delete(strings.ToLower(name))
+					return
+				}
+				req.Header.Set(name, value)
+				_ = hdrObj.Set(strings.ToLower(name), value)
+			}),
+		})
+	}
 
 	if _, err := vm.RunString(code); err != nil {
 		return err
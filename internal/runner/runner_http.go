@@ -5,11 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"net/url"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -19,7 +19,7 @@ import (
 	"pkt.systems/gruno/internal/parser"
 )
 
-func buildHTTPRequest(p parsedFile, exp *expander) (*http.Request, error) {
+func buildHTTPRequest(p parsedFile, exp *expander, fsys fs.FS) (*http.Request, error) {
 	url := exp.expand(p.Request.URL)
 	// substitute path params like :id
 	for k, v := range p.Request.PathParams {
@@ -74,7 +74,7 @@ func buildHTTPRequest(p parsedFile, exp *expander) (*http.Request, error) {
 				p.Request.Headers["Content-Type"] = "application/json"
 			}
 		case "form-urlencoded":
-			ordered := orderedFormFields(p.Request.Body.Raw)
+			ordered := formFields(p.Request.Body)
 			if len(ordered) > 0 {
 				bodyReader = strings.NewReader(encodeFormFields(ordered, exp))
 			} else {
@@ -88,7 +88,7 @@ func buildHTTPRequest(p parsedFile, exp *expander) (*http.Request, error) {
 		case "multipart-form":
 			var buf bytes.Buffer
 			w := multipart.NewWriter(&buf)
-			ordered := orderedFormFields(p.Request.Body.Raw)
+			ordered := formFields(p.Request.Body)
 			if len(ordered) == 0 {
 				for k, v := range p.Request.Body.Fields {
 					ordered = append(ordered, formField{key: k, value: v})
@@ -98,7 +98,7 @@ func buildHTTPRequest(p parsedFile, exp *expander) (*http.Request, error) {
 				k, v := field.key, field.value
 				part := parseMultipartValue(v)
 				if part.isFile {
-					f, err := os.Open(exp.expand(part.value))
+					f, err := openFormFile(fsys, p.FilePath, exp.expand(part.value))
 					if err != nil {
 						return nil, err
 					}
@@ -201,6 +201,19 @@ type formField struct {
 	value string
 }
 
+// formFields returns the ordered fields of a form body: Form when the case
+// was built in Go, else the lines of Raw.
+func formFields(b parser.BodyBlock) []formField {
+	if len(b.Form) > 0 {
+		fields := make([]formField, 0, len(b.Form))
+		for _, f := range b.Form {
+			fields = append(fields, formField{key: f.Name, value: f.Value})
+		}
+		return fields
+	}
+	return orderedFormFields(b.Raw)
+}
+
 func orderedFormFields(raw string) []formField {
 	lines := strings.Split(raw, "\n")
 	fields := make([]formField, 0, len(lines))
@@ -244,6 +257,7 @@ type multipartPart struct {
 // parseMultipartValue supports syntaxes:
 //
 //	@/path/to/file;type=application/octet-stream;cid=<attach1>
+//	@file(path/to/file)
 //	raw text;type=application/xop+xml;cid=<rootpart>
 func parseMultipartValue(raw string) multipartPart {
 	p := multipartPart{value: raw}
@@ -255,6 +269,10 @@ func parseMultipartValue(raw string) multipartPart {
 	if strings.HasPrefix(first, "@") {
 		p.isFile = true
 		p.value = strings.TrimPrefix(first, "@")
+		// Bruno writes file parts as @file(path).
+		if inner, ok := strings.CutPrefix(p.value, "file("); ok && strings.HasSuffix(inner, ")") {
+			p.value = strings.TrimSuffix(inner, ")")
+		}
 	} else {
 		p.value = first
 	}
@@ -21,7 +21,7 @@ func TestBuildHTTPRequestFormURLEncodedOrder(t *testing.T) {
 		},
 	}
 
-	req, err := buildHTTPRequest(p, newExpander(nil))
+	req, err := buildHTTPRequest(p, newExpander(nil), nil)
 	if err != nil {
 		t.Fatalf("build req: %v", err)
 	}
@@ -14,7 +14,7 @@ func TestBuildHTTPRequestUnresolvedVars(t *testing.T) {
 			URL:  "{{baseUrl}}/foo",
 		},
 	}
-	_, err := buildHTTPRequest(p, newExpander(nil))
+	_, err := buildHTTPRequest(p, newExpander(nil), nil)
 	if err == nil {
 		t.Fatal("expected error for unresolved vars")
 	}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+
+	"pkt.systems/gruno/internal/parser"
+)
+
+// Session runs the cases of a folder one at a time, so callers such as
+// grunotest can drive a run case by case. Cases of one iteration share
+// variables like a sequential RunFolder; with RunOptions.Parallel each case
+// starts from a copy of the iteration variables instead and RunCase may be
+// called concurrently.
+type Session struct {
+	r          *runner
+	opts       RunOptions
+	cases      []parser.ParsedFile
+	envVars    map[string]string
+	iterations []iterationSpec
+	vars       []map[string]string // per iteration, created on first use
+	ran        bool
+}
+
+// NewSession prepares a folder run of g, which must come from New.
+func NewSession(ctx context.Context, g Gruno, path string, opts RunOptions) (*Session, error) {
+	r, ok := g.(*runner)
+	if !ok {
+		return nil, errors.New("NewSession needs a Gruno from New")
+	}
+	cases, envVars, iterations, err := prepareFolder(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{r: r, opts: opts, cases: cases, envVars: envVars, iterations: iterations, vars: make([]map[string]string, len(iterations))}, nil
+}
+
+// Iterations returns the number of iterations of the run.
+func (s *Session) Iterations() int { return len(s.iterations) }
+
+// Cases describes the cases of each iteration, in run order.
+func (s *Session) Cases() []HookInfo {
+	out := make([]HookInfo, len(s.cases))
+	for i, c := range s.cases {
+		out[i] = HookInfo{Name: c.Meta.Name, FilePath: c.FilePath, Seq: c.Meta.Seq, Tags: c.Meta.Tags, Method: c.Request.Verb, URL: c.Request.URL}
+	}
+	return out
+}
+
+// RunCase executes case i of iteration iter. Sequential sessions apply the
+// delays of RunOptions.Delay and meta delay between cases.
+func (s *Session) RunCase(ctx context.Context, iter, i int) (CaseResult, error) {
+	if iter < 0 || iter >= len(s.iterations) || i < 0 || i >= len(s.cases) {
+		return CaseResult{}, errors.New("case index out of range")
+	}
+	spec := s.iterations[iter]
+	f := s.cases[i]
+	caseOpts := s.opts
+	caseOpts.IterationIndex = iter
+	caseOpts.TotalIterations = len(s.iterations)
+	if s.opts.Parallel {
+		caseOpts.Vars = cloneStringMap(s.envVars)
+		maps.Copy(caseOpts.Vars, spec.vars)
+		caseOpts.IterationData = cloneAnyMap(spec.data)
+		return s.r.executeParsed(ctx, f, caseOpts)
+	}
+
+	if s.vars[iter] == nil {
+		// start with env/vars fresh for each iteration so post-response vars do not leak.
+		s.vars[iter] = cloneStringMap(s.envVars)
+		maps.Copy(s.vars[iter], spec.vars)
+	}
+	delay := s.opts.Delay
+	if f.Meta.DelayMS > 0 {
+		delay += time.Duration(f.Meta.DelayMS) * time.Millisecond
+	}
+	if delay > 0 && s.ran {
+		time.Sleep(delay)
+	}
+	s.ran = true
+	caseOpts.Vars = s.vars[iter]
+	caseOpts.IterationData = spec.data
+	return s.r.executeParsed(ctx, f, caseOpts)
+}
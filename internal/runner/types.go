@@ -2,9 +2,11 @@ package runner
 
 import (
 	"context"
+	"io/fs"
 	"net/http"
 	"time"
 
+	"pkt.systems/gruno/internal/har"
 	"pkt.systems/pslog"
 )
 
@@ -13,6 +15,11 @@ import (
 type Gruno interface {
 	RunFile(ctx context.Context, path string, opts RunOptions) (CaseResult, error)
 	RunFolder(ctx context.Context, path string, opts RunOptions) (RunSummary, error)
+	// RunFS runs the collection at root inside fsys (an embed.FS, zip
+	// archive, ...) like RunFolder; see RunOptions.FS.
+	RunFS(ctx context.Context, fsys fs.FS, root string, opts RunOptions) (RunSummary, error)
+	// RunRequest runs a request composed in Go like RunFile runs a file.
+	RunRequest(ctx context.Context, req Request, opts RunOptions) (CaseResult, error)
 }
 
 // RunOptions controls execution of one or more .bru cases.
@@ -50,12 +57,30 @@ type RunOptions struct {
 	ReporterJSON  string
 	ReporterJUnit string
 	ReporterHTML  string
+	// Reporters lists extra outputs as "name=path", name being a registered
+	// reporter (see gruno.RegisterReporter).
+	Reporters []string
 	// ReporterSkipAllHeaders omits all request/response headers from reporter outputs.
 	ReporterSkipAllHeaders bool
 	// ReporterSkipHeaders removes specific headers (case-insensitive) from reporter outputs.
 	ReporterSkipHeaders []string
-	PreHookCmd          []string
-	PostHookCmd         []string
+	// ReportBodies keeps each case's request and response body in
+	// CaseResult for reporters that show them (bruno, html). Off by default:
+	// bodies are held until the run ends and may carry credentials.
+	ReportBodies bool
+	PreHookCmd   []string
+	PostHookCmd  []string
+	// PostmanCompat exposes a `pm` global in pre-request, post-response and
+	// test scripts, mapped onto bru/res/expect. Unsupported members log a warning.
+	PostmanCompat bool
+	// HTTPClientEnv selects an environment from the http-client.env.json
+	// (plus http-client.private.env.json) nearest to each .http file.
+	HTTPClientEnv string
+	// FS, when set, is the file system cases, EnvPath, data files, prelude
+	// scripts and multipart @file bodies are read from (RunFS sets it).
+	// Relative paths are resolved inside it; absolute paths still come from
+	// disk.
+	FS fs.FS
 }
 
 // HookInfo provides the minimal request metadata exposed to user hooks without
@@ -83,20 +108,34 @@ type PostRequestHook func(ctx context.Context, info HookInfo, res CaseResult, lo
 type CaseResult struct {
 	Name       string
 	FilePath   string
+	Method     string
 	RequestURL string
 	// RequestHeaders captures the request headers sent for this case.
 	RequestHeaders map[string]string
+	// RequestBody is the request body as sent; only set with
+	// RunOptions.ReportBodies.
+	RequestBody string `json:",omitempty"`
 	// ResponseHeaders captures the response headers returned for this case.
 	ResponseHeaders map[string]string
-	Status          int
-	Seq             float64
-	Tags            []string
-	Duration        time.Duration
-	Passed          bool
-	Skipped         bool
-	Failures        []AssertionFailure
-	Console         []string
-	ErrorText       string // set when execution/setup failed before assertions
+	// ResponseBody is the response body as received; only set with
+	// RunOptions.ReportBodies.
+	ResponseBody string `json:",omitempty"`
+	Status       int
+	Seq          float64
+	Tags         []string
+	// Iteration is the zero-based iteration the case ran in.
+	Iteration int
+	// Started is when the request was sent; zero for skipped cases.
+	Started  time.Time `json:",omitzero"`
+	Duration time.Duration
+	Passed   bool
+	Skipped  bool
+	Failures []AssertionFailure
+	// Tests holds every assert rule and test() of the case, in the order
+	// they ran, passed or not.
+	Tests     []TestResult
+	Console   []string
+	ErrorText string // set when execution/setup failed before assertions
 }
 
 // RunSummary aggregates multiple case results.
@@ -109,6 +148,24 @@ type RunSummary struct {
 	TotalElapsed time.Duration
 }
 
+// TestResult is the outcome of one assert rule or JS test().
+type TestResult struct {
+	Name string
+	// Kind is TestKindAssert or TestKindTest.
+	Kind     string
+	Passed   bool
+	Message  string // why it failed
+	Duration time.Duration
+	// Line is the 1-based line in the case file, 0 when unknown.
+	Line int
+}
+
+// TestResult kinds.
+const (
+	TestKindAssert = "assert" // a rule of the assert block
+	TestKindTest   = "test"   // a test() call of the tests block
+)
+
 // AssertionFailure mirrors a failed JS assertion.
 type AssertionFailure struct {
 	Name    string
@@ -138,6 +195,40 @@ func WithHTTPClient(client *http.Client) Option {
 	return func(rc *runnerConfig) { rc.httpClient = client }
 }
 
+// WithHARRecorder records every HTTP exchange made by the runner into rec.
+func WithHARRecorder(rec *har.Recorder) Option {
+	return func(rc *runnerConfig) { rc.har = rec }
+}
+
+// WithTransport wraps the HTTP client's transport for every request. Wrappers
+// apply in registration order; the innermost receives the client transport,
+// which may be nil (meaning http.DefaultTransport).
+func WithTransport(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(rc *runnerConfig) {
+		if wrap != nil {
+			rc.transports = append(rc.transports, wrap)
+		}
+	}
+}
+
+// WithHandler sends every request to h in memory instead of over the
+// network, e.g. a service's mux in a unit test. The client still follows
+// redirects and, when no client is set with WithHTTPClient, keeps cookies
+// between requests. Transports added with WithTransport wrap it.
+func WithHandler(h http.Handler) Option {
+	return func(rc *runnerConfig) { rc.handler = h }
+}
+
+// WithObserver registers o to receive the events of every run as they
+// happen. Observers are called in registration order.
+func WithObserver(o RunObserver) Option {
+	return func(rc *runnerConfig) {
+		if o != nil {
+			rc.observers = append(rc.observers, o)
+		}
+	}
+}
+
 // WithTimeout sets the default per-request timeout.
 func WithTimeout(timeout time.Duration) Option {
 	return func(rc *runnerConfig) { rc.timeout = timeout }
@@ -0,0 +1,37 @@
+package gruno
+
+import (
+	"context"
+
+	"pkt.systems/gruno/internal/lint"
+)
+
+type (
+	// LintOptions select the environment, variables and rules of a lint run.
+	LintOptions = lint.Options
+	// LintConfig enables, disables and re-levels rules (.grulint.json).
+	LintConfig = lint.Config
+	// LintRule is one check; add your own to LintOptions.Rules.
+	LintRule = lint.Rule
+	// LintFinding is one problem, with file, line and column.
+	LintFinding = lint.Finding
+	// LintSeverity is error, warning or note (SARIF levels), or off.
+	LintSeverity = lint.Severity
+	// LintCollection is the set of files a rule checks.
+	LintCollection = lint.Collection
+	// LintFile is one parsed .bru file of a LintCollection.
+	LintFile = lint.File
+)
+
+// Lint checks the .bru file or folder at path with the rules opts selects
+// (DefaultLintRules unless opts.Rules is set) and returns the findings
+// ordered by file and position.
+func Lint(ctx context.Context, path string, opts LintOptions) ([]LintFinding, error) {
+	return lint.Run(ctx, path, opts)
+}
+
+// DefaultLintRules returns the built-in lint rules, for extending
+// LintOptions.Rules.
+func DefaultLintRules() []LintRule {
+	return lint.DefaultRules()
+}
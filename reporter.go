@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
-	"html/template"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 // FilterReportHeaders applies reporter skip/redaction options to a summary before writing outputs.
@@ -54,15 +58,20 @@ func filterHeaderMap(hdrs map[string]string, skipSet map[string]struct{}) map[st
 	return out
 }
 
+// sensitiveHeaders carry credentials or sessions; they are masked (not
+// removed) in every report, HAR file and recorded cassette.
+var sensitiveHeaders = []string{"authorization", "proxy-authorization", "cookie", "set-cookie", "x-api-key", "api-key", "x-auth-token"}
+
+const maskedHeaderValue = "********"
+
 func maskSensitiveHeaders(hdrs map[string]string) {
 	if hdrs == nil {
 		return
 	}
-	if _, ok := hdrs["authorization"]; ok {
-		hdrs["authorization"] = "********"
-	}
-	if _, ok := hdrs["proxy-authorization"]; ok {
-		hdrs["proxy-authorization"] = "********"
+	for _, name := range sensitiveHeaders {
+		if _, ok := hdrs[name]; ok {
+			hdrs[name] = maskedHeaderValue
+		}
 	}
 }
 
@@ -75,22 +84,77 @@ func WriteReportJSON(path string, sum RunSummary) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
-// Minimal JUnit reporter for CI compatibility.
+// runStart is when the first case of sum was sent, or for summaries without
+// start times (built by hand, or every case skipped) TotalElapsed before now.
+func runStart(sum RunSummary) time.Time {
+	var start time.Time
+	for _, c := range sum.Cases {
+		if !c.Started.IsZero() && (start.IsZero() || c.Started.Before(start)) {
+			start = c.Started
+		}
+	}
+	if start.IsZero() {
+		start = time.Now().Add(-sum.TotalElapsed)
+	}
+	return start
+}
+
+// caseStatus reports a case as passed, failed or skipped.
+func caseStatus(c CaseResult) string {
+	switch {
+	case c.Skipped:
+		return "skipped"
+	case c.Passed:
+		return "passed"
+	default:
+		return "failed"
+	}
+}
+
+// caseMessage explains why a case failed: its first assertion failure, else
+// its error text.
+func caseMessage(c CaseResult) string {
+	if len(c.Failures) > 0 && c.Failures[0].Message != "" {
+		return c.Failures[0].Message
+	}
+	return c.ErrorText
+}
+
+// JUnit reporter for CI consumers: a testsuite per folder, a testcase per
+// assert rule and test().
+type junitTestsuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestsuite `xml:"testsuite"`
+}
+
 type junitTestsuite struct {
-	XMLName  xml.Name        `xml:"testsuite"`
-	Name     string          `xml:"name,attr"`
-	Tests    int             `xml:"tests,attr"`
-	Failures int             `xml:"failures,attr"`
-	Skipped  int             `xml:"skipped,attr"`
-	Time     string          `xml:"time,attr"`
-	Cases    []junitTestcase `xml:"testcase"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Cases     []junitTestcase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+
+	elapsed time.Duration
+	console []string
 }
 
 type junitTestcase struct {
 	Name      string        `xml:"name,attr"`
 	Classname string        `xml:"classname,attr"`
 	Time      string        `xml:"time,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
 	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
 	Skipped   *junitSkipped `xml:"skipped,omitempty"`
 }
 
@@ -104,37 +168,51 @@ type junitSkipped struct {
 	Message string `xml:"message,attr,omitempty"`
 }
 
-// WriteReportJUnit writes a RunSummary to JUnit XML for CI consumers.
+// WriteReportJUnit writes a RunSummary to JUnit XML for CI consumers. Cases
+// are grouped into a testsuite per folder; every assert rule and test() is a
+// testcase (cases without any, skipped or failed before their checks ran are
+// one testcase) and the console output of a suite's cases is its system-out.
 func WriteReportJUnit(path string, sum RunSummary) error {
-	ts := junitTestsuite{
-		Name:     "gru",
-		Tests:    len(sum.Cases),
-		Failures: sum.Failed,
-		Skipped:  sum.Skipped,
-		Time:     fmt.Sprintf("%.3f", sum.TotalElapsed.Seconds()),
-	}
+	root := junitTestsuites{Name: "gru", Time: junitTime(sum.TotalElapsed)}
+	index := map[string]int{}
 	for _, c := range sum.Cases {
-		tc := junitTestcase{
-			Name:      c.Name,
-			Classname: c.FilePath,
-			Time:      fmt.Sprintf("%.3f", c.Duration.Seconds()),
+		dir := filepath.Dir(c.FilePath)
+		i, ok := index[dir]
+		if !ok {
+			i = len(root.Suites)
+			index[dir] = i
+			root.Suites = append(root.Suites, junitTestsuite{Name: dir})
 		}
-		if c.Skipped {
-			tc.Skipped = &junitSkipped{}
-		} else if !c.Passed {
-			msg := c.ErrorText
-			if len(c.Failures) > 0 && c.Failures[0].Message != "" {
-				msg = c.Failures[0].Message
-			}
-			tc.Failure = &junitFailure{
-				Message: msg,
-				Type:    "assertion",
-				Body:    msg,
+		ts := &root.Suites[i]
+		ts.elapsed += c.Duration
+		for _, line := range c.Console {
+			ts.console = append(ts.console, "["+c.Name+"] "+line)
+		}
+		for _, tc := range junitCases(c) {
+			ts.Tests++
+			switch {
+			case tc.Failure != nil:
+				ts.Failures++
+			case tc.Error != nil:
+				ts.Errors++
+			case tc.Skipped != nil:
+				ts.Skipped++
 			}
+			ts.Cases = append(ts.Cases, tc)
+		}
+	}
+	for i := range root.Suites {
+		ts := &root.Suites[i]
+		ts.Time = junitTime(ts.elapsed)
+		if len(ts.console) > 0 {
+			ts.SystemOut = strings.Join(ts.console, "\n") + "\n"
 		}
-		ts.Cases = append(ts.Cases, tc)
+		root.Tests += ts.Tests
+		root.Failures += ts.Failures
+		root.Errors += ts.Errors
+		root.Skipped += ts.Skipped
 	}
-	data, err := xml.MarshalIndent(ts, "", "  ")
+	data, err := xml.MarshalIndent(root, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -142,79 +220,101 @@ func WriteReportJUnit(path string, sum RunSummary) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
-// HTML template structured similarly to Bru's report (table-based, status classes)
-var htmlTemplate = template.Must(template.New("report").Parse(`<!doctype html>
-<html lang="en">
-<head>
-  <meta charset="UTF-8" />
-  <title>gru report</title>
-  <style>
-    body { font-family: Arial, sans-serif; margin: 16px; background: #fafafa; }
-    h1 { margin-bottom: 8px; }
-    .summary { margin-bottom: 16px; }
-    table { width: 100%; border-collapse: collapse; background: #fff; }
-    th, td { padding: 8px 10px; border: 1px solid #e0e0e0; font-size: 14px; }
-    th { background: #f5f5f5; text-align: left; }
-    .status-pass { color: #2e7d32; font-weight: 600; }
-    .status-fail { color: #c62828; font-weight: 600; }
-    .status-skip { color: #9e9e9e; font-weight: 600; }
-    .mono { font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, monospace; font-size: 12px; }
-  </style>
-</head>
-<body>
-  <h1>gru report</h1>
-  <div class="summary">
-    <div>Total: {{.Total}} &nbsp; Passed: {{.Passed}} &nbsp; Failed: {{.Failed}} &nbsp; Skipped: {{.Skipped}} &nbsp; Time: {{.TotalElapsed}}</div>
-  </div>
-  <table>
-    <thead>
-      <tr>
-        <th>#</th>
-        <th>Name</th>
-        <th>File</th>
-        <th>Status</th>
-        <th>Duration</th>
-        <th>Error</th>
-      </tr>
-    </thead>
-    <tbody>
-      {{range $idx, $c := .Cases}}
-      <tr>
-        <td>{{$idx}}</td>
-        <td>{{$c.Name}}</td>
-        <td class="mono">{{$c.FilePath}}</td>
-        <td>
-          {{if $c.Skipped}}<span class="status-skip">skipped</span>{{else if $c.Passed}}<span class="status-pass">passed</span>{{else}}<span class="status-fail">failed</span>{{end}}
-        </td>
-        <td>{{$c.Duration}}</td>
-        <td>{{if $c.ErrorText}}<span class="mono">{{$c.ErrorText}}</span>{{end}}</td>
-      </tr>
-      {{end}}
-    </tbody>
-  </table>
-</body>
-</html>`))
-
-// WriteReportHTML renders a simple HTML table summary.
-func WriteReportHTML(path string, sum RunSummary) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+// junitCases turns a case into testcases: one per check, or the case itself
+// when it was skipped, has no checks or failed with an error.
+func junitCases(c CaseResult) []junitTestcase {
+	whole := junitTestcase{Name: c.Name, Classname: c.Name, Time: junitTime(c.Duration), File: c.FilePath}
+	if c.Skipped {
+		whole.Skipped = &junitSkipped{}
+		return []junitTestcase{whole}
+	}
+	var out []junitTestcase
+	for _, t := range c.Tests {
+		tc := junitTestcase{Name: t.Name, Classname: c.Name, Time: junitTime(t.Duration), File: c.FilePath, Line: t.Line}
+		if !t.Passed {
+			tc.Failure = &junitFailure{Message: t.Message, Type: t.Kind, Body: t.Message}
+		}
+		out = append(out, tc)
 	}
-	defer f.Close()
-	return htmlTemplate.Execute(f, sum)
+	switch {
+	case c.ErrorText != "":
+		whole.Error = &junitFailure{Message: c.ErrorText, Type: "error", Body: c.ErrorText}
+		out = append(out, whole)
+	case len(out) == 0:
+		if !c.Passed {
+			msg := caseMessage(c)
+			whole.Failure = &junitFailure{Message: msg, Type: "assertion", Body: msg}
+		}
+		out = append(out, whole)
+	}
+	return out
 }
 
-// WriteReport picks the reporter function by format.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Reporter writes a run summary to path in one report format.
+type Reporter interface {
+	WriteReport(path string, sum RunSummary) error
+}
+
+// ReporterFunc adapts a function to a Reporter.
+type ReporterFunc func(path string, sum RunSummary) error
+
+// WriteReport calls f.
+func (f ReporterFunc) WriteReport(path string, sum RunSummary) error { return f(path, sum) }
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = map[string]Reporter{
+		"json":     ReporterFunc(WriteReportJSON),
+		"junit":    ReporterFunc(WriteReportJUnit),
+		"html":     ReporterFunc(WriteReportHTML),
+		"ctrf":     ReporterFunc(WriteReportCTRF),
+		"tap":      ReporterFunc(WriteReportTAP),
+		"allure":   ReporterFunc(WriteReportAllure),
+		"markdown": ReporterFunc(WriteReportMarkdown),
+		"bruno":    ReporterFunc(WriteReportBruno),
+	}
+)
+
+// RegisterReporter makes r available to WriteReport (and gru --reporter)
+// under name, replacing any reporter of that name.
+func RegisterReporter(name string, r Reporter) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || r == nil {
+		panic("gruno: RegisterReporter needs a name and a reporter")
+	}
+	reportersMu.Lock()
+	reporters[name] = r
+	reportersMu.Unlock()
+}
+
+// LookupReporter returns the reporter registered under name.
+func LookupReporter(name string) (Reporter, bool) {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+	r, ok := reporters[strings.ToLower(strings.TrimSpace(name))]
+	return r, ok
+}
+
+// ReporterNames lists the registered reporters, sorted.
+func ReporterNames() []string {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+	return slices.Sorted(maps.Keys(reporters))
+}
+
+// WriteReport writes sum to path with the reporter registered as format
+// (json when empty).
 func WriteReport(format, path string, sum RunSummary) error {
-	switch strings.ToLower(format) {
-	case "json", "":
-		return WriteReportJSON(path, sum)
-	case "junit":
-		return WriteReportJUnit(path, sum)
-	case "html":
-		return WriteReportHTML(path, sum)
-	default:
+	if format == "" {
+		format = "json"
+	}
+	r, ok := LookupReporter(format)
+	if !ok {
 		return fmt.Errorf("unknown format %s", format)
 	}
+	return r.WriteReport(path, sum)
 }
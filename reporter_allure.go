@@ -0,0 +1,166 @@
+package gruno
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Allure 2 result layout (https://allurereport.org), one file per case.
+type allureResult struct {
+	UUID          string             `json:"uuid"`
+	HistoryID     string             `json:"historyId"`
+	Name          string             `json:"name"`
+	FullName      string             `json:"fullName"`
+	Status        string             `json:"status"`
+	StatusDetails *allureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Labels        []allureLabel      `json:"labels"`
+	Steps         []allureStep       `json:"steps,omitempty"`
+	Attachments   []allureAttachment `json:"attachments,omitempty"`
+}
+
+type allureDetails struct {
+	Message string `json:"message,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureStep struct {
+	Name          string         `json:"name"`
+	Status        string         `json:"status"`
+	StatusDetails *allureDetails `json:"statusDetails,omitempty"`
+	Stage         string         `json:"stage"`
+	Start         int64          `json:"start"`
+	Stop          int64          `json:"stop"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// WriteReportAllure writes a RunSummary into dir as Allure results: a
+// <uuid>-result.json per case, with assert rules and test() calls as steps
+// and the console output and request/response headers as attachments. dir is
+// created when missing; existing results are kept so runs can accumulate.
+func WriteReportAllure(dir string, sum RunSummary) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// Cases without a start time follow the case before them.
+	clock := runStart(sum)
+	for _, c := range sum.Cases {
+		start := c.Started
+		if start.IsZero() {
+			start = clock
+		}
+		clock = start.Add(c.Duration)
+		res := allureResult{
+			UUID:      allureUUID(),
+			HistoryID: allureHistoryID(c),
+			Name:      c.Name,
+			FullName:  c.FilePath + "#" + c.Name,
+			Status:    allureStatus(c),
+			Stage:     "finished",
+			Start:     start.UnixMilli(),
+			Stop:      start.Add(c.Duration).UnixMilli(),
+			Labels: []allureLabel{
+				{Name: "framework", Value: "gru"},
+				{Name: "suite", Value: filepath.Dir(c.FilePath)},
+			},
+		}
+		stepStart := start
+		if msg := caseMessage(c); !c.Passed && !c.Skipped && msg != "" {
+			res.StatusDetails = &allureDetails{Message: msg}
+		}
+		for _, tag := range c.Tags {
+			res.Labels = append(res.Labels, allureLabel{Name: "tag", Value: tag})
+		}
+		for _, t := range c.Tests {
+			step := allureStep{Name: t.Name, Status: "passed", Stage: "finished", Start: stepStart.UnixMilli(), Stop: stepStart.Add(t.Duration).UnixMilli()}
+			stepStart = stepStart.Add(t.Duration)
+			if !t.Passed {
+				step.Status = "failed"
+				step.StatusDetails = &allureDetails{Message: t.Message}
+			}
+			res.Steps = append(res.Steps, step)
+		}
+		if len(c.Console) > 0 {
+			att, err := writeAllureAttachment(dir, "console", "text/plain", "txt", []byte(strings.Join(c.Console, "\n")+"\n"))
+			if err != nil {
+				return err
+			}
+			res.Attachments = append(res.Attachments, att)
+		}
+		for _, h := range []struct {
+			name string
+			hdrs map[string]string
+		}{{"request headers", c.RequestHeaders}, {"response headers", c.ResponseHeaders}} {
+			if len(h.hdrs) == 0 {
+				continue
+			}
+			data, err := json.MarshalIndent(h.hdrs, "", "  ")
+			if err != nil {
+				return err
+			}
+			att, err := writeAllureAttachment(dir, h.name, "application/json", "json", data)
+			if err != nil {
+				return err
+			}
+			res.Attachments = append(res.Attachments, att)
+		}
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, res.UUID+"-result.json"), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allureStatus maps a case to Allure's statuses: failed for failed checks,
+// broken when the case could not run them.
+func allureStatus(c CaseResult) string {
+	if !c.Passed && !c.Skipped && len(c.Failures) == 0 && c.ErrorText != "" {
+		return "broken"
+	}
+	return caseStatus(c)
+}
+
+func writeAllureAttachment(dir, name, typ, ext string, data []byte) (allureAttachment, error) {
+	source := allureUUID() + "-attachment." + ext
+	if err := os.WriteFile(filepath.Join(dir, source), data, 0o644); err != nil {
+		return allureAttachment{}, err
+	}
+	return allureAttachment{Name: name, Source: source, Type: typ}, nil
+}
+
+// allureHistoryID identifies a case across runs so Allure can track its
+// history.
+func allureHistoryID(c CaseResult) string {
+	sum := sha256.Sum256([]byte(c.FilePath + "\x00" + c.Name))
+	return hex.EncodeToString(sum[:16])
+}
+
+// allureUUID returns a random (version 4) UUID.
+func allureUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
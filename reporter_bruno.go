@@ -0,0 +1,205 @@
+package gruno
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Bruno CLI JSON report layout (bru run --reporter-json): one entry per
+// iteration.
+type brunoIteration struct {
+	IterationIndex int           `json:"iterationIndex"`
+	Summary        brunoSummary  `json:"summary"`
+	Results        []brunoResult `json:"results"`
+}
+
+type brunoSummary struct {
+	TotalRequests           int `json:"totalRequests"`
+	PassedRequests          int `json:"passedRequests"`
+	FailedRequests          int `json:"failedRequests"`
+	SkippedRequests         int `json:"skippedRequests"`
+	ErrorRequests           int `json:"errorRequests"`
+	TotalAssertions         int `json:"totalAssertions"`
+	PassedAssertions        int `json:"passedAssertions"`
+	FailedAssertions        int `json:"failedAssertions"`
+	TotalTests              int `json:"totalTests"`
+	PassedTests             int `json:"passedTests"`
+	FailedTests             int `json:"failedTests"`
+	TotalPreRequestTests    int `json:"totalPreRequestTests"`
+	PassedPreRequestTests   int `json:"passedPreRequestTests"`
+	FailedPreRequestTests   int `json:"failedPreRequestTests"`
+	TotalPostResponseTests  int `json:"totalPostResponseTests"`
+	PassedPostResponseTests int `json:"passedPostResponseTests"`
+	FailedPostResponseTests int `json:"failedPostResponseTests"`
+}
+
+type brunoResult struct {
+	Test             brunoTestFile     `json:"test"`
+	Request          brunoRequest      `json:"request"`
+	Response         brunoResponse     `json:"response"`
+	Error            *string           `json:"error"`
+	Status           string            `json:"status"`
+	AssertionResults []brunoAssertion  `json:"assertionResults"`
+	TestResults      []brunoTestResult `json:"testResults"`
+	// Pre-request and post-response scripts cannot hold tests in gru.
+	PreRequestTestResults     []brunoTestResult `json:"preRequestTestResults"`
+	PostResponseTestResults   []brunoTestResult `json:"postResponseTestResults"`
+	ShouldStopRunnerExecution bool              `json:"shouldStopRunnerExecution"`
+	RunDuration               float64           `json:"runDuration"`
+	Name                      string            `json:"name"`
+	Path                      string            `json:"path"`
+	IterationIndex            int               `json:"iterationIndex"`
+}
+
+type brunoTestFile struct {
+	Filename string `json:"filename"`
+}
+
+type brunoRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Data    any               `json:"data,omitempty"`
+}
+
+// brunoResponse fields are null when the request failed.
+type brunoResponse struct {
+	Status       *int              `json:"status"`
+	StatusText   *string           `json:"statusText"`
+	Headers      map[string]string `json:"headers"`
+	Data         any               `json:"data"`
+	ResponseTime int64             `json:"responseTime"`
+}
+
+type brunoAssertion struct {
+	LHSExpr    string `json:"lhsExpr"`
+	RHSExpr    string `json:"rhsExpr"`
+	RHSOperand string `json:"rhsOperand"`
+	Operator   string `json:"operator"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+type brunoTestResult struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// WriteReportBruno writes a RunSummary in the JSON layout of the Bruno CLI
+// (bru run --reporter-json), for tooling built around it: an entry per
+// iteration with its summary and a result per request. Like bru, skipped
+// requests are counted but not listed.
+func WriteReportBruno(path string, sum RunSummary) error {
+	iterations := []brunoIteration{}
+	index := map[int]int{}
+	for _, c := range sum.Cases {
+		i, ok := index[c.Iteration]
+		if !ok {
+			i = len(iterations)
+			index[c.Iteration] = i
+			iterations = append(iterations, brunoIteration{IterationIndex: c.Iteration, Results: []brunoResult{}})
+		}
+		it := &iterations[i]
+		if c.Skipped {
+			it.Summary.SkippedRequests++
+			continue
+		}
+		res := brunoCaseResult(c)
+		it.Summary.TotalRequests++
+		switch res.Status {
+		case "pass":
+			it.Summary.PassedRequests++
+		case "error":
+			it.Summary.ErrorRequests++
+		default:
+			it.Summary.FailedRequests++
+		}
+		for _, a := range res.AssertionResults {
+			it.Summary.TotalAssertions++
+			if a.Status == "pass" {
+				it.Summary.PassedAssertions++
+			} else {
+				it.Summary.FailedAssertions++
+			}
+		}
+		for _, t := range res.TestResults {
+			it.Summary.TotalTests++
+			if t.Status == "pass" {
+				it.Summary.PassedTests++
+			} else {
+				it.Summary.FailedTests++
+			}
+		}
+		it.Results = append(it.Results, res)
+	}
+	data, err := json.MarshalIndent(iterations, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func brunoCaseResult(c CaseResult) brunoResult {
+	res := brunoResult{
+		Test:                    brunoTestFile{Filename: c.FilePath},
+		Request:                 brunoRequest{Method: c.Method, URL: c.RequestURL, Headers: c.RequestHeaders, Data: brunoData(c.RequestBody)},
+		Status:                  "pass",
+		AssertionResults:        []brunoAssertion{},
+		TestResults:             []brunoTestResult{},
+		PreRequestTestResults:   []brunoTestResult{},
+		PostResponseTestResults: []brunoTestResult{},
+		RunDuration:             c.Duration.Seconds(),
+		Name:                    c.Name,
+		Path:                    c.FilePath,
+		IterationIndex:          c.Iteration,
+	}
+	if c.Status != 0 {
+		status, text := c.Status, http.StatusText(c.Status)
+		res.Response = brunoResponse{
+			Status:       &status,
+			StatusText:   &text,
+			Headers:      c.ResponseHeaders,
+			Data:         brunoData(c.ResponseBody),
+			ResponseTime: c.Duration.Milliseconds(),
+		}
+	}
+	for _, t := range c.Tests {
+		status := "pass"
+		if !t.Passed {
+			status = "fail"
+			res.Status = "fail"
+		}
+		if t.Kind == TestKindAssert {
+			lhs, rhs, _ := strings.Cut(t.Name, ": ")
+			op, operand, _ := strings.Cut(rhs, " ")
+			res.AssertionResults = append(res.AssertionResults, brunoAssertion{
+				LHSExpr: lhs, RHSExpr: rhs, RHSOperand: operand, Operator: op, Status: status, Error: t.Message,
+			})
+			continue
+		}
+		res.TestResults = append(res.TestResults, brunoTestResult{Description: t.Name, Status: status, Error: t.Message})
+	}
+	if c.ErrorText != "" {
+		msg := c.ErrorText
+		res.Error = &msg
+		res.Status = "error"
+	} else if !c.Passed {
+		res.Status = "fail"
+	}
+	return res
+}
+
+// brunoData is a body as Bruno reports it: parsed when it is JSON, else the
+// text; nil when empty.
+func brunoData(body string) any {
+	if body == "" {
+		return nil
+	}
+	if json.Valid([]byte(body)) {
+		return json.RawMessage(body)
+	}
+	return body
+}
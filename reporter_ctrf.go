@@ -0,0 +1,88 @@
+package gruno
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// CTRF (Common Test Report Format, https://ctrf.io) report layout.
+type ctrfReport struct {
+	ReportFormat string      `json:"reportFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	Results      ctrfResults `json:"results"`
+}
+
+type ctrfResults struct {
+	Tool    ctrfTool    `json:"tool"`
+	Summary ctrfSummary `json:"summary"`
+	Tests   []ctrfTest  `json:"tests"`
+}
+
+type ctrfTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type ctrfSummary struct {
+	Tests   int   `json:"tests"`
+	Passed  int   `json:"passed"`
+	Failed  int   `json:"failed"`
+	Pending int   `json:"pending"`
+	Skipped int   `json:"skipped"`
+	Other   int   `json:"other"`
+	Start   int64 `json:"start"`
+	Stop    int64 `json:"stop"`
+}
+
+type ctrfTest struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Duration int64    `json:"duration"`
+	Message  string   `json:"message,omitempty"`
+	FilePath string   `json:"filePath,omitempty"`
+	Suite    string   `json:"suite,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Stdout   []string `json:"stdout,omitempty"`
+}
+
+// WriteReportCTRF writes a RunSummary as a CTRF JSON report.
+func WriteReportCTRF(path string, sum RunSummary) error {
+	start := runStart(sum)
+	rep := ctrfReport{
+		ReportFormat: "CTRF",
+		SpecVersion:  "0.0.0",
+		Results: ctrfResults{
+			Tool: ctrfTool{Name: "gru", Version: Version()},
+			Summary: ctrfSummary{
+				Tests:   len(sum.Cases),
+				Passed:  sum.Passed,
+				Failed:  sum.Failed,
+				Skipped: sum.Skipped,
+				Start:   start.UnixMilli(),
+				Stop:    start.Add(sum.TotalElapsed).UnixMilli(),
+			},
+			Tests: []ctrfTest{},
+		},
+	}
+	for _, c := range sum.Cases {
+		t := ctrfTest{
+			Name:     c.Name,
+			Status:   caseStatus(c),
+			Duration: c.Duration.Milliseconds(),
+			FilePath: c.FilePath,
+			Suite:    filepath.Dir(c.FilePath),
+			Tags:     c.Tags,
+			Stdout:   c.Console,
+		}
+		if !c.Passed && !c.Skipped {
+			t.Message = caseMessage(c)
+		}
+		rep.Results.Tests = append(rep.Results.Tests, t)
+	}
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
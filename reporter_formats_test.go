@@ -0,0 +1,280 @@
+package gruno
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReportSummary() RunSummary {
+	return RunSummary{
+		Cases: []CaseResult{
+			{Name: "Login", FilePath: "auth/login.bru", Passed: true, Status: 200, Duration: 120 * time.Millisecond,
+				Tests:   []TestResult{{Name: "status ok", Passed: true, Line: 12}},
+				Console: []string{"token issued"}, ResponseHeaders: map[string]string{"content-type": "application/json"}},
+			{Name: "Me #1", FilePath: "users/me.bru", Status: 401, Duration: 30 * time.Millisecond,
+				Failures: []AssertionFailure{{Name: "res.status", Message: "expected 401 to equal 200"}},
+				Tests:    []TestResult{{Name: "res.status: eq 200", Message: "expected 401 to equal 200", Line: 9}}},
+			{Name: "Down", FilePath: "users/down.bru", ErrorText: "http request failed: connection refused"},
+			{Name: "Later", FilePath: "users/later.bru", Passed: true, Skipped: true},
+		},
+		Total: 4, Passed: 1, Failed: 2, Skipped: 1, TotalElapsed: 150 * time.Millisecond,
+	}
+}
+
+func TestWriteReportCTRF(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "ctrf.json")
+	if err := WriteReport("ctrf", out, sampleReportSummary()); err != nil {
+		t.Fatalf("write ctrf: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rep ctrfReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	s := rep.Results.Summary
+	if rep.ReportFormat != "CTRF" || s.Tests != 4 || s.Passed != 1 || s.Failed != 2 || s.Skipped != 1 || s.Stop-s.Start != 150 {
+		t.Fatalf("summary: %+v", rep)
+	}
+	me := rep.Results.Tests[1]
+	if me.Status != "failed" || me.Message != "expected 401 to equal 200" || me.Duration != 30 || me.Suite != "users" {
+		t.Fatalf("test: %+v", me)
+	}
+	if rep.Results.Tests[3].Status != "skipped" {
+		t.Fatalf("skipped: %+v", rep.Results.Tests[3])
+	}
+}
+
+func TestWriteReportTAP(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.tap")
+	if err := WriteReport("tap", out, sampleReportSummary()); err != nil {
+		t.Fatalf("write tap: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 14
+1..4
+# Subtest: Login
+    1..1
+    ok 1 - status ok
+ok 1 - Login
+# Subtest: Me \#1
+    1..1
+    not ok 1 - res.status: eq 200
+      ---
+      message: "expected 401 to equal 200"
+      at: "users/me.bru:9"
+      ...
+not ok 2 - Me \#1
+  ---
+  message: "expected 401 to equal 200"
+  file: "users/me.bru"
+  status: 401
+  duration_ms: 30
+  ...
+not ok 3 - Down
+  ---
+  message: "http request failed: connection refused"
+  file: "users/down.bru"
+  ...
+ok 4 - Later # SKIP
+`
+	if string(data) != want {
+		t.Fatalf("tap:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestWriteReportAllure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "allure-results")
+	if err := WriteReport("allure", dir, sampleReportSummary()); err != nil {
+		t.Fatalf("write allure: %v", err)
+	}
+	results, _ := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	if len(results) != 4 {
+		t.Fatalf("expected 4 result files, got %d", len(results))
+	}
+	byName := map[string]allureResult{}
+	for _, path := range results {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res allureResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		byName[res.Name] = res
+	}
+	if got := byName["Down"].Status; got != "broken" {
+		t.Fatalf("Down status: %s", got)
+	}
+	if me := byName["Me #1"]; me.Status != "failed" || len(me.Steps) != 1 || me.Steps[0].Status != "failed" {
+		t.Fatalf("Me: %+v", me)
+	}
+	login := byName["Login"]
+	if len(login.Attachments) != 2 {
+		t.Fatalf("attachments: %+v", login.Attachments)
+	}
+	console, err := os.ReadFile(filepath.Join(dir, login.Attachments[0].Source))
+	if err != nil || string(console) != "token issued\n" || login.Attachments[0].Type != "text/plain" {
+		t.Fatalf("console attachment: %q %v", console, err)
+	}
+}
+
+func TestWriteReportAllureAndCTRFUseCaseStartTimes(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sum := RunSummary{Cases: []CaseResult{
+		{Name: "a", FilePath: "a.bru", Passed: true, Started: start.Add(10 * time.Millisecond), Duration: 100 * time.Millisecond,
+			Tests: []TestResult{{Name: "ok", Passed: true, Duration: time.Millisecond}}},
+		{Name: "b", FilePath: "b.bru", Passed: true, Started: start, Duration: 50 * time.Millisecond},
+		{Name: "c", FilePath: "c.bru", Passed: true, Skipped: true},
+	}, Total: 3, Passed: 2, Skipped: 1, TotalElapsed: 120 * time.Millisecond}
+
+	dir := filepath.Join(t.TempDir(), "allure-results")
+	if err := WriteReportAllure(dir, sum); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	got := map[string][2]int64{}
+	for _, path := range results {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res allureResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatal(err)
+		}
+		got[res.Name] = [2]int64{res.Start, res.Stop}
+		if res.Name == "a" && (len(res.Steps) != 1 || res.Steps[0].Start != res.Start) {
+			t.Fatalf("a steps: %+v", res.Steps)
+		}
+	}
+	ms := start.UnixMilli()
+	// Overlapping cases keep their own times; the skipped case follows b.
+	want := map[string][2]int64{"a": {ms + 10, ms + 110}, "b": {ms, ms + 50}, "c": {ms + 50, ms + 50}}
+	if !maps.Equal(got, want) {
+		t.Fatalf("allure times: got %v want %v", got, want)
+	}
+
+	out := filepath.Join(t.TempDir(), "ctrf.json")
+	if err := WriteReportCTRF(out, sum); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rep ctrfReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		t.Fatal(err)
+	}
+	if s := rep.Results.Summary; s.Start != ms || s.Stop != ms+120 {
+		t.Fatalf("ctrf window: %d-%d", s.Start, s.Stop)
+	}
+}
+
+func TestWriteReportMarkdownAppendsToStepSummary(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(out, []byte("# Build\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", out)
+	if err := WriteReport("markdown", out, sampleReportSummary()); err != nil {
+		t.Fatalf("write markdown: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"# Build\n### ❌ gru: 1 passed, 2 failed, 1 skipped (4 total) in 150ms",
+		"| ❌ | Me #1 | `users/me.bru` | 401 | 30ms |",
+		"- `res.status: eq 200`: expected 401 to equal 200",
+		"- http request failed: connection refused",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRegisterReporter(t *testing.T) {
+	var got RunSummary
+	RegisterReporter("Custom", ReporterFunc(func(path string, sum RunSummary) error {
+		got = sum
+		return nil
+	}))
+	t.Cleanup(func() {
+		reportersMu.Lock()
+		delete(reporters, "custom")
+		reportersMu.Unlock()
+	})
+	if err := WriteReport("custom", "ignored", sampleReportSummary()); err != nil || got.Total != 4 {
+		t.Fatalf("custom reporter: %v %+v", err, got)
+	}
+	if err := WriteReport("nope", "x", RunSummary{}); err == nil {
+		t.Fatal("expected unknown format error")
+	}
+}
+
+func TestWriteReportBruno(t *testing.T) {
+	sum := RunSummary{Cases: []CaseResult{
+		{Name: "Create", FilePath: "users/create.bru", Method: "POST", RequestURL: "http://api/users", Status: 201, Passed: true,
+			RequestBody: `{"name":"ada"}`, ResponseBody: `{"id":1}`, Duration: 42 * time.Millisecond,
+			Tests: []TestResult{
+				{Name: "res.status: eq 201", Kind: TestKindAssert, Passed: true},
+				{Name: "has id", Kind: TestKindTest, Passed: true},
+			}},
+		{Name: "Down", FilePath: "users/down.bru", Method: "GET", ErrorText: "http request failed: refused"},
+		{Name: "Later", FilePath: "users/later.bru", Passed: true, Skipped: true},
+		{Name: "Create", FilePath: "users/create.bru", Method: "POST", Status: 409, Iteration: 1, ResponseBody: "conflict",
+			Tests: []TestResult{{Name: "res.status: eq 201", Kind: TestKindAssert, Message: "expected 409 to equal 201"}}},
+	}}
+	out := filepath.Join(t.TempDir(), "bruno.json")
+	if err := WriteReport("bruno", out, sum); err != nil {
+		t.Fatalf("write bruno: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iterations []brunoIteration
+	if err := json.Unmarshal(data, &iterations); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(iterations) != 2 || iterations[1].IterationIndex != 1 {
+		t.Fatalf("iterations: %+v", iterations)
+	}
+	first := iterations[0]
+	if s := first.Summary; s.TotalRequests != 2 || s.PassedRequests != 1 || s.ErrorRequests != 1 || s.SkippedRequests != 1 || s.TotalAssertions != 1 || s.TotalTests != 1 {
+		t.Fatalf("summary: %+v", s)
+	}
+	create := first.Results[0]
+	if create.Status != "pass" || create.Request.Method != "POST" || *create.Response.Status != 201 || create.Response.ResponseTime != 42 {
+		t.Fatalf("create: %+v", create)
+	}
+	if body, _ := json.Marshal(create.Response.Data); string(body) != `{"id":1}` {
+		t.Fatalf("response data: %s", body)
+	}
+	if a := create.AssertionResults[0]; a.LHSExpr != "res.status" || a.Operator != "eq" || a.RHSOperand != "201" || a.RHSExpr != "eq 201" {
+		t.Fatalf("assertion: %+v", a)
+	}
+	if down := first.Results[1]; down.Status != "error" || down.Response.Status != nil || *down.Error != "http request failed: refused" {
+		t.Fatalf("down: %+v", down)
+	}
+	again := iterations[1].Results[0]
+	if again.Status != "fail" || again.Response.Data != "conflict" || again.AssertionResults[0].Error != "expected 409 to equal 201" || iterations[1].Summary.FailedAssertions != 1 {
+		t.Fatalf("iteration 1: %+v", again)
+	}
+}
@@ -0,0 +1,457 @@
+package gruno
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// htmlBodyLimit caps each request/response body shown in the HTML report.
+const htmlBodyLimit = 64 << 10
+
+// htmlReport is the view of a RunSummary rendered by htmlTemplate.
+type htmlReport struct {
+	Total, Passed, Failed, Skipped int
+	Elapsed                        string
+	Iterations                     []*htmlIteration
+	Grouped                        bool // more than one iteration
+}
+
+type htmlIteration struct {
+	Number                  int // 1-based
+	Passed, Failed, Skipped int
+	Cases                   []htmlCase
+}
+
+type htmlCase struct {
+	Index           int
+	Name            string
+	File            string
+	Method          string
+	URL             string
+	Status          string // passed, failed or skipped
+	Code            int
+	Duration        string
+	Offset, Width   float64 // waterfall bar, percent of the iteration
+	Error           string
+	Tests           []htmlTest
+	RequestHeaders  []htmlHeader
+	RequestBody     string
+	ResponseHeaders []htmlHeader
+	ResponseBody    string
+	Console         []string
+	Search          string
+
+	started  time.Time
+	at       time.Duration // offset of the bar from the iteration start
+	duration time.Duration
+}
+
+type htmlTest struct {
+	Name     string
+	Kind     string
+	Passed   bool
+	Message  string
+	Duration string
+	Line     int
+}
+
+type htmlHeader struct {
+	Name, Value string
+}
+
+// WriteReportHTML writes a self-contained HTML report (no external assets):
+// cases grouped by iteration with a timing waterfall, status filters and
+// search, and per case its checks, console output and expandable request and
+// response with pretty-printed JSON/XML bodies. Authorization headers are
+// masked.
+func WriteReportHTML(path string, sum RunSummary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := htmlTemplate.Execute(f, newHTMLReport(sum)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func newHTMLReport(sum RunSummary) htmlReport {
+	rep := htmlReport{
+		Total:   sum.Total,
+		Passed:  sum.Passed,
+		Failed:  sum.Failed,
+		Skipped: sum.Skipped,
+		Elapsed: htmlDuration(sum.TotalElapsed),
+	}
+	index := map[int]*htmlIteration{}
+	for i, c := range sum.Cases {
+		it := index[c.Iteration]
+		if it == nil {
+			it = &htmlIteration{Number: c.Iteration + 1}
+			index[c.Iteration] = it
+			rep.Iterations = append(rep.Iterations, it)
+		}
+		hc := newHTMLCase(i, c)
+		switch hc.Status {
+		case "passed":
+			it.Passed++
+		case "skipped":
+			it.Skipped++
+		default:
+			it.Failed++
+		}
+		it.Cases = append(it.Cases, hc)
+	}
+	// Place each case's bar at its start time relative to the first case of
+	// the iteration, so parallel cases overlap; cases without a start time
+	// follow the one before them.
+	for _, it := range rep.Iterations {
+		var first time.Time
+		for _, hc := range it.Cases {
+			if !hc.started.IsZero() && (first.IsZero() || hc.started.Before(first)) {
+				first = hc.started
+			}
+		}
+		var at, span time.Duration
+		for i := range it.Cases {
+			hc := &it.Cases[i]
+			if !hc.started.IsZero() {
+				at = hc.started.Sub(first)
+			}
+			hc.at = at
+			at += hc.duration
+			span = max(span, at)
+		}
+		if span == 0 {
+			continue
+		}
+		for i := range it.Cases {
+			hc := &it.Cases[i]
+			hc.Offset = 100 * float64(hc.at) / float64(span)
+			hc.Width = 100 * float64(hc.duration) / float64(span)
+		}
+	}
+	rep.Grouped = len(rep.Iterations) > 1
+	return rep
+}
+
+func newHTMLCase(i int, c CaseResult) htmlCase {
+	hc := htmlCase{
+		Index:           i + 1,
+		Name:            c.Name,
+		File:            c.FilePath,
+		Method:          c.Method,
+		URL:             c.RequestURL,
+		Status:          caseStatus(c),
+		Code:            c.Status,
+		Duration:        htmlDuration(c.Duration),
+		Error:           c.ErrorText,
+		RequestHeaders:  htmlHeaders(c.RequestHeaders),
+		RequestBody:     prettyBody(c.RequestBody),
+		ResponseHeaders: htmlHeaders(c.ResponseHeaders),
+		ResponseBody:    prettyBody(c.ResponseBody),
+		Console:         c.Console,
+		Search:          strings.ToLower(strings.Join(strings.Fields(c.Name+" "+c.FilePath+" "+c.Method+" "+c.RequestURL), " ")),
+		started:         c.Started,
+		duration:        c.Duration,
+	}
+	if hc.Name == "" {
+		hc.Name = c.FilePath
+	}
+	for _, t := range c.Tests {
+		hc.Tests = append(hc.Tests, htmlTest{
+			Name:     t.Name,
+			Kind:     t.Kind,
+			Passed:   t.Passed,
+			Message:  t.Message,
+			Duration: htmlDuration(t.Duration),
+			Line:     t.Line,
+		})
+	}
+	if len(c.Tests) == 0 {
+		for _, f := range c.Failures {
+			hc.Tests = append(hc.Tests, htmlTest{Name: f.Name, Message: f.Message})
+		}
+	}
+	return hc
+}
+
+// htmlHeaders sorts headers by name, masking the sensitive ones.
+func htmlHeaders(hdrs map[string]string) []htmlHeader {
+	var out []htmlHeader
+	for k, v := range hdrs {
+		if slices.Contains(sensitiveHeaders, strings.ToLower(k)) {
+			v = maskedHeaderValue
+		}
+		out = append(out, htmlHeader{Name: k, Value: v})
+	}
+	slices.SortFunc(out, func(a, b htmlHeader) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+func htmlDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// prettyBody indents JSON and XML bodies and truncates long ones.
+func prettyBody(body string) string {
+	var suffix string
+	if len(body) > htmlBodyLimit {
+		suffix = fmt.Sprintf("\n… truncated, %d bytes in total", len(body))
+		body = body[:htmlBodyLimit]
+	}
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case suffix != "":
+	case json.Valid([]byte(trimmed)):
+		var b bytes.Buffer
+		if json.Indent(&b, []byte(trimmed), "", "  ") == nil {
+			return b.String()
+		}
+	case strings.HasPrefix(trimmed, "<"):
+		if s, ok := indentXML(trimmed); ok {
+			return s
+		}
+	}
+	return body + suffix
+}
+
+// indentXML re-indents an XML document, keeping namespace prefixes as
+// written.
+func indentXML(s string) (string, bool) {
+	dec := xml.NewDecoder(strings.NewReader(s))
+	var b bytes.Buffer
+	depth, open := 0, false
+	indent := func() { b.WriteString(strings.Repeat("  ", depth)) }
+	qname := func(n xml.Name) string {
+		if n.Space != "" {
+			return n.Space + ":" + n.Local
+		}
+		return n.Local
+	}
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if open {
+				b.WriteString("\n")
+			}
+			indent()
+			b.WriteString("<" + qname(t.Name))
+			for _, a := range t.Attr {
+				b.WriteString(" " + qname(a.Name) + `="`)
+				_ = xml.EscapeText(&b, []byte(a.Value))
+				b.WriteString(`"`)
+			}
+			b.WriteString(">")
+			depth++
+			open = true
+		case xml.EndElement:
+			depth--
+			if !open {
+				indent()
+			}
+			b.WriteString("</" + qname(t.Name) + ">\n")
+			open = false
+		case xml.CharData:
+			text := bytes.TrimSpace(t)
+			if len(text) == 0 {
+				continue
+			}
+			if !open {
+				indent()
+			}
+			_ = xml.EscapeText(&b, text)
+			if !open {
+				b.WriteString("\n")
+			}
+		case xml.ProcInst:
+			indent()
+			fmt.Fprintf(&b, "<?%s %s?>\n", t.Target, t.Inst)
+		case xml.Comment:
+			if open {
+				b.WriteString("\n")
+				open = false
+			}
+			indent()
+			fmt.Fprintf(&b, "<!--%s-->\n", t)
+		case xml.Directive:
+			indent()
+			fmt.Fprintf(&b, "<!%s>\n", t)
+		}
+	}
+	if depth != 0 {
+		return "", false
+	}
+	return strings.TrimRight(b.String(), "\n"), true
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>gru report</title>
+  <style>
+    :root { --pass: #2e7d32; --fail: #c62828; --skip: #9e9e9e; --line: #e0e0e0; }
+    body { font-family: system-ui, Arial, sans-serif; margin: 16px; background: #fafafa; color: #212121; }
+    h1 { margin: 0 0 12px; }
+    h2 { font-size: 16px; margin: 20px 0 8px; }
+    h3 { font-size: 13px; margin: 12px 0 4px; text-transform: uppercase; color: #616161; }
+    .cards { display: flex; gap: 8px; flex-wrap: wrap; margin-bottom: 12px; }
+    .card { background: #fff; border: 1px solid var(--line); border-radius: 6px; padding: 8px 14px; }
+    .card b { display: block; font-size: 20px; }
+    .toolbar { display: flex; gap: 6px; flex-wrap: wrap; align-items: center; margin-bottom: 8px; }
+    .toolbar input { flex: 1; min-width: 200px; padding: 6px 8px; border: 1px solid var(--line); border-radius: 4px; }
+    .toolbar button { padding: 6px 10px; border: 1px solid var(--line); border-radius: 4px; background: #fff; cursor: pointer; }
+    .toolbar button.active { background: #212121; color: #fff; }
+    details.case { background: #fff; border: 1px solid var(--line); border-left: 4px solid var(--skip); border-radius: 4px; margin: 4px 0; }
+    details.case.passed { border-left-color: var(--pass); }
+    details.case.failed { border-left-color: var(--fail); }
+    details.case > summary { display: grid; grid-template-columns: 3em 5.5em 4.5em minmax(8em, 1fr) minmax(8em, 1fr) 3.5em 30% 6em; gap: 8px; align-items: center; padding: 6px 10px; cursor: pointer; font-size: 14px; }
+    .badge { font-size: 11px; font-weight: 600; text-transform: uppercase; }
+    .passed .badge, .ok { color: var(--pass); }
+    .failed .badge, .ko { color: var(--fail); }
+    .skipped .badge { color: var(--skip); }
+    .bar { position: relative; height: 10px; background: #f5f5f5; border-radius: 2px; }
+    .bar span { position: absolute; top: 0; bottom: 0; min-width: 2px; background: #90a4ae; border-radius: 2px; }
+    .failed .bar span { background: #ef9a9a; }
+    .passed .bar span { background: #a5d6a7; }
+    .dur { text-align: right; }
+    .detail { padding: 0 12px 12px; border-top: 1px solid var(--line); }
+    .error { color: var(--fail); margin-top: 8px; }
+    ul.tests { list-style: none; margin: 0; padding: 0; }
+    ul.tests li { padding: 2px 0; }
+    .panes { display: grid; grid-template-columns: 1fr 1fr; gap: 12px; }
+    details.pane > summary { cursor: pointer; font-weight: 600; margin-top: 12px; }
+    table.headers { border-collapse: collapse; width: 100%; margin: 6px 0; }
+    table.headers td { border: 1px solid var(--line); padding: 2px 6px; vertical-align: top; word-break: break-all; }
+    pre { background: #f5f5f5; padding: 8px; overflow: auto; max-height: 480px; margin: 6px 0; white-space: pre-wrap; word-break: break-all; }
+    .mono, pre { font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, monospace; font-size: 12px; }
+    .muted { color: #757575; }
+    .ellipsis { overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+  </style>
+</head>
+<body>
+  <h1>gru report</h1>
+  <div class="cards">
+    <div class="card">Total<b>{{.Total}}</b></div>
+    <div class="card ok">Passed<b>{{.Passed}}</b></div>
+    <div class="card ko">Failed<b>{{.Failed}}</b></div>
+    <div class="card muted">Skipped<b>{{.Skipped}}</b></div>
+    <div class="card">Time<b>{{.Elapsed}}</b></div>
+  </div>
+  <div class="toolbar">
+    <input id="search" type="search" placeholder="Search name, file or URL" aria-label="Search" />
+    <button type="button" data-filter="all" class="active">All</button>
+    <button type="button" data-filter="passed">Passed</button>
+    <button type="button" data-filter="failed">Failed</button>
+    <button type="button" data-filter="skipped">Skipped</button>
+    <button type="button" id="expand">Expand all</button>
+    <button type="button" id="collapse">Collapse all</button>
+  </div>
+  {{- range .Iterations}}
+  <section class="iteration">
+    {{- if $.Grouped}}<h2>Iteration {{.Number}} <span class="muted">{{.Passed}} passed, {{.Failed}} failed, {{.Skipped}} skipped</span></h2>{{end}}
+    {{- range .Cases}}
+    <details class="case {{.Status}}" data-status="{{.Status}}" data-search="{{.Search}}"{{if eq .Status "failed"}} open{{end}}>
+      <summary>
+        <span class="muted">#{{.Index}}</span>
+        <span class="badge">{{.Status}}</span>
+        <span class="mono">{{.Method}}</span>
+        <span class="ellipsis" title="{{.Name}}">{{.Name}}</span>
+        <span class="mono ellipsis muted" title="{{.File}}">{{.File}}</span>
+        <span class="mono">{{if .Code}}{{.Code}}{{end}}</span>
+        <span class="bar" title="{{.Duration}}"><span style="left: {{printf "%.2f" .Offset}}%; width: {{printf "%.2f" .Width}}%"></span></span>
+        <span class="mono dur">{{.Duration}}</span>
+      </summary>
+      <div class="detail">
+        {{- if .Error}}<div class="error mono">{{.Error}}</div>{{end}}
+        {{- if .Tests}}
+        <h3>Tests</h3>
+        <ul class="tests">
+          {{- range .Tests}}<li><span class="{{if .Passed}}ok{{else}}ko{{end}}">{{if .Passed}}&#10003;{{else}}&#10007;{{end}}</span> {{.Name}} <span class="mono muted">{{.Kind}}{{if .Duration}} {{.Duration}}{{end}}{{if .Line}} line {{.Line}}{{end}}</span>{{if .Message}}<div class="mono ko">{{.Message}}</div>{{end}}</li>
+          {{- end}}
+        </ul>
+        {{- end}}
+        {{- if .URL}}
+        <div class="panes">
+          <details class="pane">
+            <summary>Request</summary>
+            <div class="mono">{{.Method}} {{.URL}}</div>
+            {{- if .RequestHeaders}}<table class="headers mono">{{range .RequestHeaders}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+            {{- if .RequestBody}}<pre>{{.RequestBody}}</pre>{{end}}
+          </details>
+          <details class="pane">
+            <summary>Response{{if .Code}} {{.Code}}{{end}}</summary>
+            {{- if .ResponseHeaders}}<table class="headers mono">{{range .ResponseHeaders}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+            {{- if .ResponseBody}}<pre>{{.ResponseBody}}</pre>{{end}}
+          </details>
+        </div>
+        {{- end}}
+        {{- if .Console}}
+        <h3>Console</h3>
+        <pre>{{range .Console}}{{.}}{{"\n"}}{{end}}</pre>
+        {{- end}}
+      </div>
+    </details>
+    {{- end}}
+  </section>
+  {{- end}}
+  <p id="empty" class="muted" hidden>No cases match.</p>
+  <script>
+    (function () {
+      var search = document.getElementById("search");
+      var cases = Array.prototype.slice.call(document.querySelectorAll("details.case"));
+      var buttons = Array.prototype.slice.call(document.querySelectorAll("[data-filter]"));
+      var filter = "all";
+      function apply() {
+        var term = search.value.trim().toLowerCase();
+        var shown = 0;
+        cases.forEach(function (c) {
+          var ok = (filter === "all" || c.dataset.status === filter) && (term === "" || c.dataset.search.indexOf(term) >= 0);
+          c.hidden = !ok;
+          if (ok) shown++;
+        });
+        document.querySelectorAll("section.iteration").forEach(function (s) {
+          s.hidden = !s.querySelector("details.case:not([hidden])");
+        });
+        document.getElementById("empty").hidden = shown > 0;
+      }
+      search.addEventListener("input", apply);
+      buttons.forEach(function (b) {
+        b.addEventListener("click", function () {
+          filter = b.dataset.filter;
+          buttons.forEach(function (x) { x.classList.toggle("active", x === b); });
+          apply();
+        });
+      });
+      document.getElementById("expand").addEventListener("click", function () {
+        cases.forEach(function (c) { if (!c.hidden) c.open = true; });
+      });
+      document.getElementById("collapse").addEventListener("click", function () {
+        cases.forEach(function (c) { c.open = false; });
+      });
+    })();
+  </script>
+</body>
+</html>
+`))
@@ -0,0 +1,94 @@
+package gruno
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// WriteReportMarkdown writes a RunSummary as a Markdown summary: a results
+// table followed by the failures. When path is $GITHUB_STEP_SUMMARY the
+// summary is appended, as GitHub Actions expects; other files are replaced.
+func WriteReportMarkdown(path string, sum RunSummary) error {
+	var b bytes.Buffer
+	icon := "✅"
+	if sum.Failed > 0 {
+		icon = "❌"
+	}
+	fmt.Fprintf(&b, "### %s gru: %d passed, %d failed, %d skipped (%d total) in %s\n\n",
+		icon, sum.Passed, sum.Failed, sum.Skipped, sum.Total, sum.TotalElapsed.Round(time.Millisecond))
+	if len(sum.Cases) > 0 {
+		b.WriteString("| | Name | File | Status | Duration |\n|---|---|---|---|---|\n")
+		for _, c := range sum.Cases {
+			status := ""
+			if c.Status != 0 {
+				status = fmt.Sprint(c.Status)
+			}
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %s | %s |\n",
+				markdownIcon(c), markdownCell(c.Name), markdownCell(c.FilePath), status, c.Duration.Round(time.Millisecond))
+		}
+	}
+	var failed []CaseResult
+	for _, c := range sum.Cases {
+		if !c.Passed && !c.Skipped {
+			failed = append(failed, c)
+		}
+	}
+	if len(failed) > 0 {
+		b.WriteString("\n#### Failures\n")
+		for _, c := range failed {
+			fmt.Fprintf(&b, "\n**%s** (`%s`)\n", markdownCell(c.Name), c.FilePath)
+			if c.ErrorText != "" {
+				fmt.Fprintf(&b, "- %s\n", markdownLine(c.ErrorText))
+			}
+			for _, t := range c.Tests {
+				if !t.Passed {
+					fmt.Fprintf(&b, "- `%s`: %s\n", t.Name, markdownLine(t.Message))
+				}
+			}
+			if len(c.Tests) == 0 {
+				for _, f := range c.Failures {
+					fmt.Fprintf(&b, "- `%s`: %s\n", f.Name, markdownLine(f.Message))
+				}
+			}
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if stepSummary := os.Getenv("GITHUB_STEP_SUMMARY"); stepSummary != "" && path == stepSummary {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		b.WriteString("\n")
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func markdownIcon(c CaseResult) string {
+	switch caseStatus(c) {
+	case "skipped":
+		return "⏭️"
+	case "passed":
+		return "✅"
+	default:
+		return "❌"
+	}
+}
+
+// markdownCell keeps s inside one table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownLine(s), "|", `\|`)
+}
+
+// markdownLine folds s onto one line.
+func markdownLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,83 @@
+package gruno
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WriteReportTAP writes a RunSummary as TAP version 14. Each case is a test
+// point; its assert rules and test() calls are reported as a subtest.
+func WriteReportTAP(path string, sum RunSummary) error {
+	var b bytes.Buffer
+	b.WriteString("TAP version 14\n")
+	fmt.Fprintf(&b, "1..%d\n", len(sum.Cases))
+	for i, c := range sum.Cases {
+		if len(c.Tests) > 0 && !c.Skipped {
+			fmt.Fprintf(&b, "# Subtest: %s\n", tapDescription(c.Name))
+			fmt.Fprintf(&b, "    1..%d\n", len(c.Tests))
+			for j, t := range c.Tests {
+				writeTAPPoint(&b, "    ", j+1, t.Passed, t.Name, "")
+				if !t.Passed {
+					writeTAPYAML(&b, "    ", []tapField{{"message", t.Message}, {"at", tapPosition(c.FilePath, t.Line)}})
+				}
+			}
+		}
+		directive := ""
+		if c.Skipped {
+			directive = " # SKIP"
+		}
+		writeTAPPoint(&b, "", i+1, c.Passed, c.Name, directive)
+		if !c.Passed && !c.Skipped {
+			writeTAPYAML(&b, "", []tapField{
+				{"message", caseMessage(c)},
+				{"file", c.FilePath},
+				{"status", c.Status},
+				{"duration_ms", int(c.Duration.Milliseconds())},
+			})
+		}
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+func writeTAPPoint(b *bytes.Buffer, indent string, n int, ok bool, desc, directive string) {
+	status := "ok"
+	if !ok {
+		status = "not ok"
+	}
+	fmt.Fprintf(b, "%s%s %d - %s%s\n", indent, status, n, tapDescription(desc), directive)
+}
+
+type tapField struct {
+	key   string
+	value any
+}
+
+// writeTAPYAML writes a YAML diagnostic block, leaving out empty values.
+// Values are JSON encoded, which YAML reads as plain scalars.
+func writeTAPYAML(b *bytes.Buffer, indent string, fields []tapField) {
+	fmt.Fprintf(b, "%s  ---\n", indent)
+	for _, f := range fields {
+		if f.value == "" || f.value == 0 {
+			continue
+		}
+		v, _ := json.Marshal(f.value)
+		fmt.Fprintf(b, "%s  %s: %s\n", indent, f.key, v)
+	}
+	fmt.Fprintf(b, "%s  ...\n", indent)
+}
+
+// tapDescription escapes what TAP would read as a directive and keeps the
+// description on one line.
+func tapDescription(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\r", " ", "\n", " ").Replace(s)
+}
+
+func tapPosition(file string, line int) string {
+	if line <= 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
@@ -3,8 +3,11 @@ package gruno
 import (
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,32 +73,77 @@ func TestWriteReportJUnit(t *testing.T) {
 		t.Fatalf("read junit: %v", err)
 	}
 
-	var suite junitTestsuite
-	if err := xml.Unmarshal(data, &suite); err != nil {
+	var suites junitTestsuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
 		t.Fatalf("unmarshal: %v", err)
 	}
-	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
-		t.Fatalf("unexpected suite %+v", suite)
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("unexpected suites %+v", suites)
 	}
+	suite := suites.Suites[0]
 	if len(suite.Cases) != 3 || suite.Cases[2].Failure == nil {
 		t.Fatalf("expected failure case recorded")
 	}
 }
 
+func TestWriteReportJUnitPerCheckAndFolder(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.xml")
+	sum := RunSummary{
+		Cases: []CaseResult{
+			{Name: "Login", FilePath: "auth/login.bru", Passed: true, Duration: 100 * time.Millisecond, Console: []string{"token issued"},
+				Tests: []TestResult{
+					{Name: "res.status: eq 200", Kind: TestKindAssert, Passed: true, Duration: time.Millisecond, Line: 9},
+					{Name: "has token", Kind: TestKindTest, Passed: true, Duration: 2 * time.Millisecond, Line: 14},
+				}},
+			{Name: "Me", FilePath: "users/me.bru", Duration: 50 * time.Millisecond,
+				Tests: []TestResult{{Name: "is ada", Kind: TestKindTest, Message: "expected grace to equal ada", Line: 12}}},
+			{Name: "Down", FilePath: "users/down.bru", ErrorText: "http request failed: refused"},
+		},
+		Total: 3, Passed: 1, Failed: 2, TotalElapsed: 200 * time.Millisecond,
+	}
+	if err := WriteReportJUnit(out, sum); err != nil {
+		t.Fatalf("write junit: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestsuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if suites.Tests != 4 || suites.Failures != 1 || suites.Errors != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("unexpected suites %+v", suites)
+	}
+	auth, users := suites.Suites[0], suites.Suites[1]
+	if auth.Name != "auth" || auth.Time != "0.100" || auth.SystemOut != "[Login] token issued\n" {
+		t.Fatalf("auth suite: %+v", auth)
+	}
+	if tc := auth.Cases[1]; tc.Name != "has token" || tc.Classname != "Login" || tc.Time != "0.002" || tc.Line != 14 || tc.File != "auth/login.bru" {
+		t.Fatalf("testcase: %+v", tc)
+	}
+	if users.Name != "users" || users.Cases[0].Failure == nil || users.Cases[0].Failure.Type != "test" || users.Cases[1].Error == nil {
+		t.Fatalf("users suite: %+v", users)
+	}
+}
+
 func TestFilterReportHeaders(t *testing.T) {
 	sum := RunSummary{
 		Cases: []CaseResult{
 			{
 				Name:            "case",
-				RequestHeaders:  map[string]string{"authorization": "Bearer secret", "x-foo": "bar"},
-				ResponseHeaders: map[string]string{"content-type": "application/json", "x-foo": "bar"},
+				RequestHeaders:  map[string]string{"authorization": "Bearer secret", "cookie": "sid=secret", "x-foo": "bar"},
+				ResponseHeaders: map[string]string{"content-type": "application/json", "set-cookie": "sid=secret", "x-foo": "bar"},
 			},
 		},
 	}
 
 	withMask := FilterReportHeaders(sum, RunOptions{})
-	if withMask.Cases[0].RequestHeaders["authorization"] != "********" {
-		t.Fatalf("authorization not masked: %+v", withMask.Cases[0].RequestHeaders)
+	if withMask.Cases[0].RequestHeaders["authorization"] != "********" || withMask.Cases[0].RequestHeaders["cookie"] != "********" {
+		t.Fatalf("authorization/cookie not masked: %+v", withMask.Cases[0].RequestHeaders)
+	}
+	if withMask.Cases[0].ResponseHeaders["set-cookie"] != "********" {
+		t.Fatalf("set-cookie not masked: %+v", withMask.Cases[0].ResponseHeaders)
 	}
 	if withMask.Cases[0].RequestHeaders["x-foo"] != "bar" {
 		t.Fatalf("unexpected header retained")
@@ -148,3 +196,62 @@ func TestWriteReportHTMLGolden(t *testing.T) {
 		t.Fatalf("html report mismatch\nwant:\n%s\n\ngot:\n%s", want, got)
 	}
 }
+
+func TestWriteReportHTMLWaterfallUsesStartTimes(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sum := RunSummary{Cases: []CaseResult{
+		{Name: "a", Passed: true, Started: start, Duration: 100 * time.Millisecond},
+		{Name: "b", Passed: true, Started: start.Add(50 * time.Millisecond), Duration: 150 * time.Millisecond},
+		{Name: "c", Passed: true, Skipped: true},
+		{Name: "d", Passed: true, Started: start.Add(150 * time.Millisecond), Duration: 50 * time.Millisecond},
+	}}
+	rep := newHTMLReport(sum)
+	var got []string
+	for _, hc := range rep.Iterations[0].Cases {
+		got = append(got, fmt.Sprintf("%.0f+%.0f", hc.Offset, hc.Width))
+	}
+	// Parallel cases overlap; the skipped case follows b.
+	if want := []string{"0+50", "25+75", "100+0", "75+25"}; !slices.Equal(got, want) {
+		t.Fatalf("waterfall: got %v want %v", got, want)
+	}
+}
+
+func TestWriteReportHTMLDetails(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.html")
+	sum := RunSummary{Cases: []CaseResult{
+		{Name: "Create", FilePath: "users/create.bru", Method: "POST", RequestURL: "https://api.example/users", Status: 201, Passed: true,
+			RequestHeaders:  map[string]string{"Authorization": "Bearer secret", "Content-Type": "application/json", "Cookie": "sid=secret", "X-Api-Key": "secret"},
+			ResponseHeaders: map[string]string{"Set-Cookie": "sid=secret; Path=/"},
+			RequestBody:     `{"name":"ada"}`, ResponseBody: `<user><id>1</id></user>`, Duration: 40 * time.Millisecond,
+			Tests:   []TestResult{{Name: "res.status: eq 201", Kind: TestKindAssert, Passed: true, Duration: time.Millisecond}},
+			Console: []string{"created <ada>"}},
+		{Name: "Create", FilePath: "users/create.bru", Method: "POST", Status: 409, Iteration: 1, Duration: 10 * time.Millisecond,
+			Tests: []TestResult{{Name: "res.status: eq 201", Kind: TestKindAssert, Message: "expected 409 to equal 201"}}},
+	}, Total: 2, Passed: 1, Failed: 1}
+	if err := WriteReportHTML(out, sum); err != nil {
+		t.Fatalf("write html: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"{\n  &#34;name&#34;: &#34;ada&#34;\n}",
+		"&lt;user&gt;\n  &lt;id&gt;1&lt;/id&gt;\n&lt;/user&gt;",
+		"<td>Authorization</td><td>********</td>",
+		"<td>Cookie</td><td>********</td>",
+		"<td>Set-Cookie</td><td>********</td>",
+		"created &lt;ada&gt;\n",
+		"<h2>Iteration 2 ",
+		`data-search="create users/create.bru post https://api.example/users"`,
+		"expected 409 to equal 201",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("html lacks %q", want)
+		}
+	}
+	if strings.Contains(html, "secret") || strings.Contains(html, "src=") || strings.Contains(html, "<link") {
+		t.Fatal("html leaks a secret or references external assets")
+	}
+}